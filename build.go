@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Emptyless/jsonschema-transform/config"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// buildCmd registered to the rootCmd
+var buildCmd = &cobra.Command{
+	Use:          "build [target...]",
+	Short:        "build the targets declared in the configuration file",
+	Long:         "build the named targets (or all targets if none are given) declared in " + config.FileName + ". Each target runs its command with the options of the target, which take precedence over the defaults of the configuration file",
+	Example:      fmt.Sprintf("%s build api-diagram", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleBuild,
+}
+
+// init the buildCmd command
+func init() {
+	rootCmd.AddCommand(buildCmd)
+}
+
+// handleBuild for the buildCmd command
+func handleBuild(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		names = cfg.TargetNames()
+	}
+
+	for _, name := range names {
+		if err = buildTarget(cmd, cfg, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildTarget by running its command with the options of the target. Targets overwrite their previous output unless
+// the 'overwrite' option is false
+func buildTarget(cmd *cobra.Command, cfg *config.Config, name string) error {
+	target, values, err := cfg.Target(name)
+	if err != nil {
+		return err
+	}
+
+	command, _, err := rootCmd.Find([]string{target.Command})
+	if err != nil {
+		return err
+	}
+
+	// flags are reset before and after such that options do not leak between targets of the same command
+	resetFlags(command)
+	defer resetFlags(command)
+
+	if overwrite := command.Flags().Lookup(allowOverwriteFlag.Name); overwrite != nil {
+		_ = setFlag(overwrite, true)
+	}
+
+	if err = applyFlags(command, values, true); err != nil {
+		return fmt.Errorf("target %s: %w", name, err)
+	}
+
+	if err = command.ValidateArgs(target.Args); err != nil {
+		return fmt.Errorf("target %s: %w", name, err)
+	}
+
+	logrus.Info("building target ", name)
+	command.SetContext(cmd.Context())
+	if err = command.RunE(command, target.Args); err != nil {
+		return fmt.Errorf("target %s: %w", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfig to a temporary directory for the schemas in testdata
+func writeConfig(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	testdata, err := filepath.Abs("testdata")
+	require.NoError(t, err)
+
+	configFile := filepath.Join(dir, ".jsonschema-transform.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(fmt.Sprintf(`globs: [%q]
+base-uri: %q
+options:
+  layout: elk
+targets:
+  api-diagram:
+    command: d2
+    output: api.d2
+    options:
+      direction: right
+  pet-impact:
+    command: impact
+    args: [Pet Store]
+`, filepath.Join(testdata, "*.json"), filepath.Dir(testdata))), 0o644))
+
+	t.Cleanup(func() {
+		_ = rootCmd.PersistentFlags().Set(configFlag.Name, "")
+		resetFlags(d2Cmd)
+	})
+
+	return dir, configFile
+}
+
+func TestBuild_BuildsTargets(t *testing.T) {
+	// Arrange
+	dir, configFile := writeConfig(t)
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	rootCmd.SetArgs([]string{"build", "--config", configFile})
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(dir, "api.d2"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "layout-engine: elk")
+	assert.Contains(t, string(b), "direction: right")
+	assert.Contains(t, outputBuffer.String(), "classes depending on Pet Store:")
+}
+
+func TestBuild_UnknownTarget(t *testing.T) {
+	// Arrange
+	_, configFile := writeConfig(t)
+	rootCmd.SetArgs([]string{"build", "--config", configFile, "docs"})
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, config.ErrUnknownTarget)
+}
+
+func TestConfig_FlagsOverrideConfig(t *testing.T) {
+	// Arrange
+	dir, configFile := writeConfig(t)
+	defaultsFile, overriddenFile := filepath.Join(dir, "defaults.d2"), filepath.Join(dir, "overridden.d2")
+
+	// Act
+	rootCmd.SetArgs([]string{d2Cmd.Use, "--config", configFile, "-o", defaultsFile, "--container-base-path", ""})
+	defaultsErr := rootCmd.Execute()
+	rootCmd.SetArgs([]string{d2Cmd.Use, "--config", configFile, "-o", overriddenFile, "--container-base-path", "", "--layout", "dagre"})
+	overriddenErr := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, defaultsErr)
+	require.NoError(t, overriddenErr)
+	defaults, err := os.ReadFile(defaultsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(defaults), "layout-engine: elk")
+	overridden, err := os.ReadFile(overriddenFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(overridden), "layout-engine: dagre")
+}
+
+func TestBuild_ConfigAllowsCommands(t *testing.T) {
+	for _, command := range rootCmd.Commands() {
+		// help, build and serve do not produce an output and are not targets
+		if !command.Runnable() || command.Name() == "help" || command == buildCmd || command == serveCmd {
+			continue
+		}
+
+		t.Run(command.Name(), func(t *testing.T) {
+			// Arrange
+			configFile := filepath.Join(t.TempDir(), ".jsonschema-transform.yaml")
+			require.NoError(t, os.WriteFile(configFile, []byte("targets:\n  target:\n    command: "+command.Name()+"\n"), 0o644))
+
+			// Act
+			_, err := config.Load(configFile)
+
+			// Assert
+			require.NoError(t, err)
+		})
+	}
+}
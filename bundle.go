@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Emptyless/jsonschema-transform/bundle"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// bundleCmd registered to the rootCmd
+var bundleCmd = &cobra.Command{
+	Use:          "bundle",
+	Short:        "bundle the json schemas into a single self-contained schema",
+	Long:         "bundle the json schemas and every schema they reference into a single self-contained 2020-12 schema where the referenced schemas are embedded under '$defs'",
+	Example:      fmt.Sprintf("%s bundle --globs schemas/api.json --output api.bundle.json", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleBundle,
+}
+
+// init the bundleCmd command
+func init() {
+	rootCmd.AddCommand(bundleCmd)
+	bundleOutputFlag.Apply(bundleCmd.Flags())
+	globsFlag.Apply(bundleCmd.Flags())
+	excludeFlag.Apply(bundleCmd.Flags())
+	jobsFlag.Apply(bundleCmd.Flags())
+	baseURIFlag.Apply(bundleCmd.Flags())
+	allowOverwriteFlag.Apply(bundleCmd.Flags())
+	dereferenceFlag.Apply(bundleCmd.Flags())
+	bundleIDFlag.Apply(bundleCmd.Flags())
+	watchFlag.Apply(bundleCmd.Flags())
+}
+
+// handleBundle for the bundleCmd command
+func handleBundle(cmd *cobra.Command, _ []string) error {
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	dereference, err := cmd.Flags().GetBool(dereferenceFlag.Name)
+	if err != nil {
+		return err
+	}
+
+	return runOrWatch(cmd, parser, func(overwrite bool) error {
+		return writeBundle(cmd, parser, &bundle.Config{
+			ID:          cmd.Flag(bundleIDFlag.Name).Value.String(),
+			Dereference: dereference,
+		}, overwrite)
+	})
+}
+
+// writeBundle of the schemas of the parser to the output file (or stdout if '-'), an existing file is only replaced if
+// overwrite is true
+func writeBundle(cmd *cobra.Command, parser *parse.Parser, cfg *bundle.Config, overwrite bool) error {
+	roots, err := parser.Schemas()
+	if err != nil {
+		return err
+	}
+
+	document, err := bundle.Bundle(parser.Compiler, roots, cfg)
+	if err != nil {
+		return err
+	}
+
+	output, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return err
+	}
+	output = append(output, '\n')
+
+	outputFile := cmd.Flag(bundleOutputFlag.Name).Value.String()
+	if outputFile == "-" {
+		_, err = cmd.OutOrStdout().Write(output)
+		return err
+	}
+
+	if _, statErr := os.Stat(outputFile); statErr == nil && !overwrite {
+		return ErrNoOverwrite
+	}
+
+	if writeFileErr := os.WriteFile(outputFile, output, 0o644); writeFileErr != nil {
+		return writeFileErr
+	}
+
+	logrus.Info("bundled schema written to ", outputFile)
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Draft202012 is the $schema of the compound document
+const Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// ErrNoRoots is returned when there are no schemas to bundle
+var ErrNoRoots = errors.New("no schemas to bundle")
+
+// ErrUnresolvedRef is returned when a $ref (or $dynamicRef) is not resolved by the compiler
+var ErrUnresolvedRef = errors.New("unresolved reference")
+
+// Config used when bundling
+type Config struct {
+	// ID of the compound document, only used when multiple roots are bundled (a single root keeps its own $id)
+	ID string
+
+	// Dereference inlines every $ref where the reference is not recursive
+	Dereference bool
+}
+
+// Bundle the roots and every schema document they (transitively) reference into a single 2020-12 compound document.
+// Referenced documents are embedded under '$defs' keeping their '$id' and all references are rewritten to absolute
+// URIs such that they resolve to the embedded documents. If a single root is provided it becomes the compound
+// document, otherwise the roots are embedded as well.
+func Bundle(compiler *jsonschema.Compiler, roots []*jsonschema.Schema, cfg *Config) (map[string]any, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	if len(roots) == 0 {
+		return nil, ErrNoRoots
+	}
+
+	documents, err := Documents(compiler, roots)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []map[string]any
+	for _, document := range documents {
+		resource, resourceErr := toResource(document)
+		if resourceErr != nil {
+			return nil, resourceErr
+		}
+		resources = append(resources, resource)
+	}
+
+	var bundle map[string]any
+	embedded := resources
+	if len(roots) == 1 {
+		bundle = resources[0]
+		embedded = resources[1:]
+	} else {
+		bundle = map[string]any{}
+		if cfg.ID != "" {
+			bundle["$id"] = cfg.ID
+		}
+	}
+	bundle["$schema"] = Draft202012
+
+	defs, _ := bundle["$defs"].(map[string]any)
+	if defs == nil {
+		defs = map[string]any{}
+	}
+
+	var keys []string
+	for _, resource := range embedded {
+		delete(resource, "$schema") // embedded resources share the dialect of the compound document
+		key := defKey(defs, resource)
+		defs[key] = resource
+		keys = append(keys, key)
+	}
+
+	if len(defs) > 0 {
+		bundle["$defs"] = defs
+	}
+
+	if cfg.Dereference {
+		bundle = Dereference(bundle)
+		prune(bundle, keys)
+	}
+
+	return bundle, nil
+}
+
+// prune embedded resources under keys in '$defs' that are no longer referenced (e.g. after Dereference)
+func prune(bundle map[string]any, keys []string) {
+	defs, _ := bundle["$defs"].(map[string]any)
+	for pruned := true; pruned; {
+		pruned = false
+		referenced := references(bundle)
+		for _, key := range keys {
+			resource, ok := defs[key].(map[string]any)
+			if !ok {
+				continue
+			}
+
+			if id, _ := resource["$id"].(string); !referenced[id] {
+				delete(defs, key)
+				pruned = true
+			}
+		}
+	}
+
+	if len(defs) == 0 {
+		delete(bundle, "$defs")
+	}
+}
+
+// Documents reachable from the roots by following every resolved $ref and $dynamicRef to the document containing
+// its target (the same way parse.ClassParser.PropertyRef does). The roots are returned first followed by the
+// referenced documents in the order they are discovered.
+func Documents(compiler *jsonschema.Compiler, roots []*jsonschema.Schema) ([]*jsonschema.Schema, error) {
+	var res []*jsonschema.Schema
+	contained := map[*jsonschema.Schema]bool{}
+	queue := slices.Clone(roots)
+	for len(queue) > 0 {
+		document := queue[0]
+		queue = queue[1:]
+		if document == nil || contained[document] {
+			continue
+		}
+
+		res = append(res, document)
+
+		var errs []error
+		parse.Walk(document, func(schema *jsonschema.Schema) bool {
+			contained[schema] = true
+
+			if schema.Ref != "" && schema.ResolvedRef == nil {
+				errs = append(errs, fmt.Errorf("%w: $ref '%s' in %s", ErrUnresolvedRef, schema.Ref, document.GetSchemaURI()))
+			}
+
+			if schema.DynamicRef != "" && schema.ResolvedDynamicRef == nil {
+				errs = append(errs, fmt.Errorf("%w: $dynamicRef '%s' in %s", ErrUnresolvedRef, schema.DynamicRef, document.GetSchemaURI()))
+			}
+
+			for _, resolved := range []*jsonschema.Schema{schema.ResolvedRef, schema.ResolvedDynamicRef} {
+				if resolved == nil {
+					continue
+				}
+
+				parent, err := compiler.GetSchema(resolved.GetSchemaURI())
+				if err != nil {
+					errs = append(errs, fmt.Errorf("parent of $ref '%s' failed to load: %w", resolved.GetSchemaURI(), err))
+					continue
+				}
+
+				queue = append(queue, parent)
+			}
+
+			return true
+		})
+
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+	}
+
+	return res, nil
+}
+
+// toResource converts the jsonschema.Schema into its JSON representation with an '$id' and absolute references
+func toResource(schema *jsonschema.Schema) (map[string]any, error) {
+	contents, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	var resource map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	if err = decoder.Decode(&resource); err != nil {
+		return nil, fmt.Errorf("schema %s is not an object: %w", schema.GetSchemaURI(), err)
+	}
+
+	if _, ok := resource["$id"]; !ok && schema.GetSchemaURI() != "" {
+		resource["$id"] = schema.GetSchemaURI()
+	}
+
+	parse.WalkDocument(resource, nil, func(node map[string]any, base *url.URL) {
+		for _, keyword := range []string{"$ref", "$dynamicRef"} {
+			if ref, ok := node[keyword].(string); ok {
+				node[keyword] = parse.ResolveURI(base, ref).String()
+			}
+		}
+	})
+
+	return resource, nil
+}
+
+// invalidKeyCharacters are replaced when deriving a $defs key
+var invalidKeyCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// defKey derives a unique key in defs for the resource based on the file name in its $id (e.g. 'store' for
+// 'file:///testdata/store.json')
+func defKey(defs map[string]any, resource map[string]any) string {
+	key := "schema"
+	if id, ok := resource["$id"].(string); ok {
+		if u, err := url.Parse(id); err == nil {
+			name := strings.TrimSuffix(path.Base(u.Path), path.Ext(u.Path))
+			name = invalidKeyCharacters.ReplaceAllString(name, "_")
+			if name != "" && name != "." && name != "/" {
+				key = name
+			}
+		}
+	}
+
+	unique := key
+	for i := 2; defs[unique] != nil; i++ {
+		unique = fmt.Sprintf("%s_%d", key, i)
+	}
+
+	return unique
+}
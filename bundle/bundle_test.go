@@ -0,0 +1,110 @@
+package bundle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const personSchema = `{
+  "$id": "https://example.com/schemas/person.json",
+  "title": "Person",
+  "type": "object",
+  "properties": {
+    "address": {"$ref": "address.json"},
+    "children": {"type": "array", "items": {"$ref": "#"}}
+  }
+}`
+
+const addressSchema = `{
+  "$id": "https://example.com/schemas/address.json",
+  "title": "Address",
+  "type": "object",
+  "required": ["street"],
+  "properties": {
+    "street": {"type": "string"}
+  }
+}`
+
+func compile(t *testing.T) (*jsonschema.Compiler, *jsonschema.Schema) {
+	t.Helper()
+
+	compiler := jsonschema.NewCompiler()
+	_, err := compiler.Compile([]byte(addressSchema))
+	require.NoError(t, err)
+	person, err := compiler.Compile([]byte(personSchema))
+	require.NoError(t, err)
+
+	return compiler, person
+}
+
+func TestBundle(t *testing.T) {
+	// Arrange
+	compiler, person := compile(t)
+
+	// Act
+	document, err := Bundle(compiler, []*jsonschema.Schema{person}, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/schemas/person.json", document["$id"])
+	assert.Equal(t, Draft202012, document["$schema"])
+
+	defs := document["$defs"].(map[string]any)
+	require.Contains(t, defs, "address")
+	assert.Equal(t, "https://example.com/schemas/address.json", defs["address"].(map[string]any)["$id"])
+
+	address := document["properties"].(map[string]any)["address"].(map[string]any)
+	assert.Equal(t, "https://example.com/schemas/address.json", address["$ref"])
+
+	// the bundle must be usable without the original schemas
+	contents, err := json.Marshal(document)
+	require.NoError(t, err)
+	bundled, err := jsonschema.NewCompiler().Compile(contents)
+	require.NoError(t, err)
+	assert.True(t, bundled.Validate(map[string]any{"address": map[string]any{"street": "Main"}}).IsValid())
+	assert.False(t, bundled.Validate(map[string]any{"address": map[string]any{}}).IsValid())
+}
+
+func TestBundle_Dereference(t *testing.T) {
+	// Arrange
+	compiler, person := compile(t)
+
+	// Act
+	document, err := Bundle(compiler, []*jsonschema.Schema{person}, &Config{Dereference: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, document, "$defs")
+
+	properties := document["properties"].(map[string]any)
+	address := properties["address"].(map[string]any)
+	assert.Equal(t, "Address", address["title"])
+	assert.NotContains(t, address, "$id")
+
+	// recursive references are kept
+	items := properties["children"].(map[string]any)["items"].(map[string]any)
+	assert.Equal(t, "https://example.com/schemas/person.json", items["$ref"])
+}
+
+func TestBundle_MultipleRoots(t *testing.T) {
+	// Arrange
+	compiler, person := compile(t)
+	address, err := compiler.GetSchema("https://example.com/schemas/address.json")
+	require.NoError(t, err)
+
+	// Act
+	document, err := Bundle(compiler, []*jsonschema.Schema{person, address}, &Config{ID: "https://example.com/schemas/bundle.json"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/schemas/bundle.json", document["$id"])
+
+	defs := document["$defs"].(map[string]any)
+	assert.Len(t, defs, 2)
+	assert.Contains(t, defs, "person")
+	assert.Contains(t, defs, "address")
+}
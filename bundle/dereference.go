@@ -0,0 +1,170 @@
+package bundle
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+)
+
+// identifierKeywords are removed from inlined copies as they must be unique within a compound document
+var identifierKeywords = []string{"$id", "$schema", "$anchor", "$dynamicAnchor", "$defs"}
+
+// Dereference a compound document (with absolute references, see Bundle) by replacing every $ref with a copy of its
+// target. A $ref with sibling keywords is combined with its target using 'allOf'. References that are recursive
+// (i.e. the target is already being inlined) and $dynamicRef's are kept as is.
+func Dereference(document map[string]any) map[string]any {
+	idx := index(document)
+
+	// references to the document itself are always recursive
+	var stack []string
+	if id, ok := document["$id"].(string); ok {
+		stack = append(stack, id)
+	}
+
+	res, _ := dereference(document, idx, stack, false).(map[string]any)
+
+	return res
+}
+
+// dereference a node where stack contains the references that are currently being inlined. If inlined is true the
+// identifierKeywords are removed from the copy
+func dereference(node any, idx map[string]any, stack []string, inlined bool) any {
+	switch n := node.(type) {
+	case map[string]any:
+		res := map[string]any{}
+		for key, value := range n {
+			if inlined && slices.Contains(identifierKeywords, key) {
+				continue
+			}
+
+			switch {
+			case parse.IsDataKeyword(key):
+				res[key] = value
+			case parse.IsKeyedKeyword(key):
+				if keyed, ok := value.(map[string]any); ok {
+					copied := map[string]any{}
+					for name, subschema := range keyed {
+						copied[name] = dereference(subschema, idx, stack, inlined)
+					}
+					res[key] = copied
+				} else {
+					res[key] = dereference(value, idx, stack, inlined)
+				}
+			default:
+				res[key] = dereference(value, idx, stack, inlined)
+			}
+		}
+
+		ref, ok := n["$ref"].(string)
+		if !ok || slices.Contains(stack, ref) {
+			return res
+		}
+
+		target, ok := idx[ref]
+		if !ok {
+			target, ok = resolvePointer(ref, idx)
+		}
+		if !ok {
+			return res
+		}
+
+		targetCopy := dereference(target, idx, append(slices.Clone(stack), ref), true)
+		delete(res, "$ref")
+		if len(res) == 0 {
+			return targetCopy
+		}
+
+		allOf, _ := res["allOf"].([]any)
+		res["allOf"] = append(slices.Clone(allOf), targetCopy)
+
+		return res
+	case []any:
+		res := make([]any, len(n))
+		for i, item := range n {
+			res[i] = dereference(item, idx, stack, inlined)
+		}
+
+		return res
+	default:
+		return node
+	}
+}
+
+// index of every resource ($id) and anchor ($anchor, $dynamicAnchor) in the document by their absolute URI
+func index(document map[string]any) map[string]any {
+	idx := map[string]any{}
+	parse.WalkDocument(document, nil, func(schema map[string]any, base *url.URL) {
+		if base == nil {
+			return
+		}
+
+		resource := *base
+		resource.Fragment = ""
+		if _, ok := schema["$id"].(string); ok {
+			idx[resource.String()] = schema
+		}
+
+		for _, keyword := range []string{"$anchor", "$dynamicAnchor"} {
+			if anchor, ok := schema[keyword].(string); ok {
+				idx[resource.String()+"#"+anchor] = schema
+			}
+		}
+	})
+
+	return idx
+}
+
+// resolvePointer resolves a reference with a JSON pointer fragment, e.g. 'https://example.com/pet.json#/$defs/id'
+func resolvePointer(ref string, idx map[string]any) (any, bool) {
+	resource, pointer, ok := strings.Cut(ref, "#")
+	if !ok || !strings.HasPrefix(pointer, "/") {
+		return nil, false
+	}
+
+	node, ok := idx[resource]
+	if !ok {
+		return nil, false
+	}
+
+	for _, segment := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = strings.ReplaceAll(strings.ReplaceAll(segment, "~1", "/"), "~0", "~")
+
+		switch n := node.(type) {
+		case map[string]any:
+			if node, ok = n[segment]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(n) {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// references returns the resource URIs (i.e. without fragment) that are referenced using $ref or $dynamicRef
+func references(document map[string]any) map[string]bool {
+	res := map[string]bool{}
+	parse.WalkDocument(document, nil, func(schema map[string]any, _ *url.URL) {
+		for _, keyword := range []string{"$ref", "$dynamicRef"} {
+			if ref, ok := schema[keyword].(string); ok {
+				resource, _, _ := strings.Cut(ref, "#")
+				res[resource] = true
+			}
+		}
+	})
+
+	return res
+}
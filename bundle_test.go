@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundle_WritesBundle(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{bundleCmd.Use, "--globs", "./testdata/pet.json", "--base-uri", "./", "--output", "-"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, outputBuffer.String(), `"$ref": "file:///testdata/store.json#id"`)
+	assert.Contains(t, outputBuffer.String(), `"$id": "file:///testdata/store.json"`)
+}
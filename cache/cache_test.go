@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDir(t *testing.T) {
+	// Arrange
+	dir, err := Open(filepath.Join(t.TempDir(), "cache"))
+	require.NoError(t, err)
+
+	// Act
+	_, before := dir.Get("key")
+	putErr := dir.Put("key", []byte("value"))
+	value, after := dir.Get("key")
+
+	// Assert
+	assert.False(t, before)
+	require.NoError(t, putErr)
+	assert.True(t, after)
+	assert.Equal(t, "value", string(value))
+}
+
+func TestDir_Prune(t *testing.T) {
+	// Arrange
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, dir.Put("unused", []byte("value")))
+	require.NoError(t, dir.Put("used", []byte("value")))
+	require.NoError(t, dir.Put("recent", []byte("value")))
+	old := time.Now().Add(-48 * time.Hour)
+	for _, key := range []string{"unused", "used"} {
+		require.NoError(t, os.Chtimes(filepath.Join(dir.Path, key), old, old))
+	}
+	_, used := dir.Get("used")
+	require.True(t, used)
+
+	// Act
+	removed, err := dir.Prune(24 * time.Hour)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, unused := dir.Get("unused")
+	assert.False(t, unused)
+	_, used = dir.Get("used")
+	assert.True(t, used)
+	_, recent := dir.Get("recent")
+	assert.True(t, recent)
+}
+
+func TestParser(t *testing.T) {
+	// Arrange
+	schemas := t.TempDir()
+	writeFile(t, filepath.Join(schemas, "pet.json"), `{
+		"$id": "file:///pet.json",
+		"title": "Pet",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "description": "name of the pet"},
+			"owner": {"$ref": "people/owner.json"}
+		}
+	}`)
+	writeFile(t, filepath.Join(schemas, "people", "owner.json"), `{"$id": "file:///people/owner.json", "title": "Owner", "type": "object"}`)
+
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	newParser := func() *Parser {
+		return NewParser(parse.NewParser(filepath.Join(schemas, "*.json")).SetBaseURI("file://"+schemas), dir)
+	}
+
+	// Act
+	miss := newParser()
+	missClasses, missErr := miss.Classes()
+	missRelations, _ := miss.Relations()
+
+	hit := newParser()
+	hitClasses, hitErr := hit.Classes()
+	hitRelations, _ := hit.Relations()
+
+	writeFile(t, filepath.Join(schemas, "people", "owner.json"), `{"$id": "file:///people/owner.json", "title": "Person", "type": "object"}`)
+	changed := newParser()
+	changedClasses, changedErr := changed.Classes()
+
+	// Assert
+	require.NoError(t, missErr)
+	assert.False(t, miss.Hit)
+	require.Len(t, missClasses, 2)
+
+	require.NoError(t, hitErr)
+	assert.True(t, hit.Hit)
+	require.Len(t, hitClasses, 2)
+	assert.Equal(t, "Owner", hitClasses[0].Name)
+	assert.Equal(t, "Pet", hitClasses[1].Name)
+	assert.Equal(t, missClasses[1].Path(), hitClasses[1].Path())
+	assert.Equal(t, "file:///pet.json", hitClasses[1].Schema.GetSchemaURI())
+	assert.Equal(t, "name of the pet", hitClasses[1].Properties[0].Docstring)
+	assert.Same(t, hitClasses[1], hitClasses[1].Properties[0].Parent)
+	require.Len(t, hitRelations, len(missRelations))
+	assert.Same(t, hitClasses[1], hitRelations[0].From)
+	assert.Same(t, hitClasses[0], hitRelations[0].To)
+	assert.Equal(t, "owner", hitRelations[0].FromProperty.Name)
+	assert.ElementsMatch(t, miss.Files(), hit.Files())
+
+	require.NoError(t, changedErr)
+	assert.False(t, changed.Hit, "a referenced file changed")
+	assert.Equal(t, "Person", changedClasses[0].Name)
+}
+
+func TestParser_ReusesUnchangedDocuments(t *testing.T) {
+	// Arrange
+	schemas := t.TempDir()
+	writeFile(t, filepath.Join(schemas, "pet.json"), `{"$id": "file:///pet.json", "title": "Pet", "type": "object", "properties": {"owner": {"$ref": "owner.json"}}}`)
+	writeFile(t, filepath.Join(schemas, "owner.json"), `{"$id": "file:///owner.json", "title": "Owner", "type": "object"}`)
+	writeFile(t, filepath.Join(schemas, "toy.json"), `{"$id": "file:///toy.json", "title": "Toy", "type": "object"}`)
+
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	newParser := func() *Parser {
+		return NewParser(parse.NewParser(filepath.Join(schemas, "*.json")).SetBaseURI("file://"+schemas), dir)
+	}
+	_, err = newParser().Classes()
+	require.NoError(t, err)
+
+	// Act
+	writeFile(t, filepath.Join(schemas, "toy.json"), `{"$id": "file:///toy.json", "title": "Ball", "type": "object"}`)
+	toyChanged := newParser()
+	toyClasses, toyErr := toyChanged.Classes()
+	toyRelations, _ := toyChanged.Relations()
+
+	writeFile(t, filepath.Join(schemas, "owner.json"), `{"$id": "file:///owner.json", "title": "Person", "type": "object"}`)
+	ownerChanged := newParser()
+	ownerClasses, ownerErr := ownerChanged.Classes()
+
+	// Assert
+	require.NoError(t, toyErr)
+	assert.False(t, toyChanged.Hit)
+	assert.Equal(t, []string{filepath.Join(schemas, "toy.json")}, toyChanged.Parsed)
+	require.Len(t, toyClasses, 3)
+	assert.Equal(t, []string{"Owner", "Pet", "Ball"}, []string{toyClasses[0].Name, toyClasses[1].Name, toyClasses[2].Name})
+	require.Len(t, toyRelations, 1)
+	assert.Same(t, toyClasses[1], toyRelations[0].From)
+	assert.Same(t, toyClasses[0], toyRelations[0].To)
+
+	require.NoError(t, ownerErr)
+	assert.ElementsMatch(t, []string{filepath.Join(schemas, "owner.json"), filepath.Join(schemas, "pet.json")}, ownerChanged.Parsed, "the document referencing the changed document is parsed again")
+	assert.Equal(t, "Person", ownerClasses[0].Name)
+}
+
+func TestParser_Depth(t *testing.T) {
+	// Arrange
+	schemas := t.TempDir()
+	writeFile(t, filepath.Join(schemas, "pet.json"), `{"$id": "file:///pet.json", "title": "Pet", "type": "object", "properties": {"owner": {"$ref": "people/owner.json"}}}`)
+	writeFile(t, filepath.Join(schemas, "people", "owner.json"), `{"$id": "file:///people/owner.json", "title": "Owner", "type": "object", "properties": {"address": {"$ref": "address.json"}}}`)
+	writeFile(t, filepath.Join(schemas, "people", "address.json"), `{"$id": "file:///people/address.json", "title": "Address", "type": "object"}`)
+
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	newParser := func() *Parser {
+		return NewParser(parse.NewParser(filepath.Join(schemas, "*.json")).SetBaseURI("file://"+schemas).SetDepth(1), dir)
+	}
+
+	for _, hit := range []bool{false, true} {
+		// Act
+		parser := newParser()
+		classes, classesErr := parser.Classes()
+		relations, relationsErr := parser.Relations()
+
+		// Assert
+		require.NoError(t, classesErr)
+		require.NoError(t, relationsErr)
+		assert.Equal(t, hit, parser.Hit)
+		var names []string
+		for _, c := range classes {
+			names = append(names, c.Name)
+		}
+		assert.Equal(t, []string{"Owner", "Pet"}, names)
+		require.Len(t, relations, 1)
+		assert.Equal(t, "Owner", relations[0].To.Name)
+	}
+}
+
+func TestParser_AnonymousClasses(t *testing.T) {
+	// Arrange
+	schemas := t.TempDir()
+	writeFile(t, filepath.Join(schemas, "pet.json"), `{"$id": "file:///pet.json", "title": "Pet", "type": "object", "properties": {"collar": {"type": "object", "properties": {"color": {"type": "string"}}}}}`)
+	writeFile(t, filepath.Join(schemas, "owner.json"), `{"$id": "file:///owner.json", "title": "Owner", "type": "object", "properties": {"address": {"type": "object", "properties": {"city": {"type": "string"}}}}}`)
+
+	dir, err := Open(t.TempDir())
+	require.NoError(t, err)
+	newParser := func() *Parser {
+		return NewParser(parse.NewParser(filepath.Join(schemas, "*.json")).SetBaseURI("file://"+schemas), dir)
+	}
+	_, err = newParser().Classes()
+	require.NoError(t, err)
+
+	// Act
+	hit := newParser()
+	hitClasses, hitErr := hit.Classes()
+	hitRelations, _ := hit.Relations()
+
+	writeFile(t, filepath.Join(schemas, "owner.json"), `{"$id": "file:///owner.json", "title": "Person", "type": "object", "properties": {"address": {"type": "object", "properties": {"city": {"type": "string"}}}}}`)
+	changed := newParser()
+	changedClasses, changedErr := changed.Classes()
+
+	// Assert
+	require.NoError(t, hitErr)
+	assert.True(t, hit.Hit, "documents with anonymous classes are cached")
+	require.Len(t, hitClasses, 4)
+	require.Len(t, hitRelations, 2)
+	properties := map[string]string{}
+	for _, r := range hitRelations {
+		assert.Contains(t, hitClasses, r.To)
+		assert.Empty(t, strings.TrimSpace(r.To.Name))
+		require.Len(t, r.To.Properties, 1)
+		properties[r.FromProperty.Name] = r.To.Properties[0].Name
+	}
+	assert.Equal(t, map[string]string{"collar": "color", "address": "city"}, properties)
+
+	require.NoError(t, changedErr)
+	assert.Equal(t, []string{filepath.Join(schemas, "owner.json")}, changed.Parsed)
+	require.Len(t, changedClasses, 4)
+	names := map[string]bool{}
+	for _, c := range changedClasses {
+		names[c.Name] = true
+	}
+	assert.Len(t, names, 4, "the restored anonymous class is not named like the parsed one")
+}
+
+func writeFile(t *testing.T, name string, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.NoError(t, os.WriteFile(name, []byte(contents), 0o644))
+}
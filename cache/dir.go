@@ -0,0 +1,101 @@
+// Package cache stores the results of a build on disk such that unchanged inputs are not parsed and rendered again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir of cached entries where every entry is a file named by its key
+type Dir struct {
+	// Path of the directory
+	Path string
+}
+
+// Open the Dir at path, creating it if it does not exist
+func Open(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &Dir{Path: path}, nil
+}
+
+// Get the entry by key, ok is false if it is not stored. The modification time of the entry is updated such that
+// entries in use are not pruned (see Prune)
+func (d *Dir) Get(key string) ([]byte, bool) {
+	path := filepath.Join(d.Path, key)
+	value, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return value, true
+}
+
+// Prune the entries (and the temporary files of interrupted writes) that are not used for longer than maxAge, i.e.
+// neither stored nor retrieved, and return the number of files removed
+func (d *Dir) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		info, infoErr := entry.Info()
+		if infoErr != nil || !info.Mode().IsRegular() || time.Since(info.ModTime()) <= maxAge {
+			continue
+		}
+
+		if err = os.Remove(filepath.Join(d.Path, entry.Name())); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
+// Put the entry by key. The entry is written to a temporary file first such that concurrent builds never read a
+// partially written entry
+func (d *Dir) Put(key string, value []byte) error {
+	f, err := os.CreateTemp(d.Path, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	if _, err = f.Write(value); err != nil {
+		_ = f.Close()
+		return err
+	} else if err = f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), filepath.Join(d.Path, key))
+}
+
+// Key of the parts, prefixed by kind (e.g. 'graph')
+func Key(kind string, parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return kind + "-" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Hash of the contents
+func Hash(contents []byte) string {
+	sum := sha256.Sum256(contents)
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,347 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// version of the encoding of a document, entries of other versions are ignored
+const version = 5
+
+// document of the classes and relations parsed from a single schema document
+type document struct {
+	// Version of the encoding
+	Version int `json:"version"`
+
+	// File the document is read from
+	File string `json:"file"`
+
+	// Files the classes depend on by their content Hash, i.e. the File and the files it (transitively) references
+	Files map[string]string `json:"files"`
+
+	// Classes parsed from the document in the order they were parsed
+	Classes []*class `json:"classes"`
+
+	// Relations from the Classes
+	Relations []*relation `json:"relations"`
+}
+
+// class is the encoded domain.Class
+type class struct {
+	// Source of the class, i.e. the schema document
+	Source string `json:"source"`
+
+	// ID is the URI of the schema of the class
+	ID string `json:"id,omitempty"`
+
+	// Root is true iff the class is parsed from the root schema of the document
+	Root bool `json:"root,omitempty"`
+
+	// Pointer to the schema of the class in the document (e.g. '#/properties/owner'), empty if it has no schema of its
+	// own (e.g. a 'dependentRequired' variant)
+	Pointer string `json:"pointer,omitempty"`
+
+	Name       string      `json:"name"`
+	Docstring  string      `json:"docstring,omitempty"`
+	Properties []*property `json:"properties,omitempty"`
+	Closed     bool        `json:"closed,omitempty"`
+}
+
+// property is the encoded domain.Property
+type property struct {
+	Name      string `json:"name"`
+	Type      string `json:"type,omitempty"`
+	Docstring string `json:"docstring,omitempty"`
+}
+
+// relation is the encoded domain.Relation from a class of the document (by index) to a class of any document (by
+// its file and pointer, or by its name if it has no pointer). Properties are referenced by name (or empty if none)
+type relation struct {
+	Type         string `json:"type"`
+	From         int    `json:"from"`
+	FromProperty string `json:"fromProperty,omitempty"`
+	ToFile       string `json:"toFile"`
+	To           string `json:"to"`
+	ToPointer    string `json:"toPointer,omitempty"`
+	ToProperty   string `json:"toProperty,omitempty"`
+}
+
+// parsed classes and relations of the parse.Parser grouped by the document they are parsed from
+type parsed struct {
+	// classes of the parse.Parser
+	classes []*domain.Class
+
+	// relations of the parse.Parser
+	relations []*domain.Relation
+
+	// files of the classes
+	files map[*domain.Class]string
+
+	// roots are the classes parsed from the root schema of a document
+	roots map[*domain.Class]bool
+
+	// pointers to the schemas of the classes in their document, see class.Pointer
+	pointers map[*domain.Class]string
+
+	// references of a file to the other files its schemas resolve $refs to, an empty file if one is unknown
+	references map[string][]string
+
+	// documents by the file they are read from
+	documents map[string]*jsonschema.Schema
+}
+
+// newParsed groups the classes and relations of the parser by the document they are parsed from
+func newParsed(parser *parse.Parser, classes []*domain.Class, relations []*domain.Relation) *parsed {
+	res := &parsed{
+		classes:    classes,
+		relations:  relations,
+		files:      map[*domain.Class]string{},
+		roots:      map[*domain.Class]bool{},
+		pointers:   map[*domain.Class]string{},
+		references: map[string][]string{},
+		documents:  parser.Documents(),
+	}
+
+	// schemas by the file they are nested in (and their pointer in it), files are walked in order such that the result
+	// is deterministic
+	files := map[*jsonschema.Schema]string{}
+	pointers := map[*jsonschema.Schema]string{}
+	uris := map[string]string{}
+	for _, file := range slices.Sorted(maps.Keys(res.documents)) {
+		uris[res.documents[file].GetSchemaURI()] = file
+		parse.WalkPointers(res.documents[file], func(schema *jsonschema.Schema, pointer string) bool {
+			if _, ok := files[schema]; !ok {
+				files[schema], pointers[schema] = file, "#"+pointer
+			}
+
+			return true
+		})
+	}
+
+	for _, file := range slices.Sorted(maps.Keys(res.documents)) {
+		parse.Walk(res.documents[file], func(schema *jsonschema.Schema) bool {
+			for _, resolved := range []*jsonschema.Schema{schema.ResolvedRef, schema.ResolvedDynamicRef} {
+				if resolved == nil {
+					continue
+				}
+
+				target, ok := files[resolved]
+				if !ok {
+					target = uris[resolved.GetSchemaURI()]
+				}
+				if target != file && !slices.Contains(res.references[file], target) {
+					res.references[file] = append(res.references[file], target)
+				}
+			}
+
+			return true
+		})
+	}
+
+	// a variant of a conditional has no schema of its own but shares the source of its base
+	sources := map[string]string{}
+	for _, c := range classes {
+		if file, ok := files[c.Schema]; ok {
+			res.files[c] = file
+			res.roots[c] = res.documents[file] == c.Schema
+			res.pointers[c] = pointers[c.Schema]
+			sources[c.Path()] = file
+		}
+	}
+	for _, c := range classes {
+		if _, ok := res.files[c]; !ok && sources[c.Path()] != "" {
+			res.files[c] = sources[c.Path()]
+		}
+	}
+
+	return res
+}
+
+// complete returns true iff the document of every class is known
+func (p *parsed) complete() bool {
+	return len(p.files) == len(p.classes)
+}
+
+// closure of the file, i.e. the file and the files it (transitively) references. The closure contains an empty file
+// if one of them could not be resolved to a file
+func (p *parsed) closure(file string) []string {
+	res := []string{file}
+	for i := 0; i < len(res); i++ {
+		for _, reference := range p.references[res[i]] {
+			if !slices.Contains(res, reference) {
+				res = append(res, reference)
+			}
+		}
+	}
+
+	return res
+}
+
+// document encoding the classes and relations parsed from the file, ok is false if they cannot be restored from the
+// encoding, i.e. a class (or a class it relates to) is not identified (see identified) or a referenced file is unknown
+func (p *parsed) document(file string, hash func(file string) (string, error)) (*document, bool) {
+	d := &document{Version: version, File: file, Files: map[string]string{}}
+	for _, f := range p.closure(file) {
+		h, err := hash(f)
+		if f == "" || err != nil {
+			return nil, false
+		}
+		d.Files[f] = h
+	}
+
+	var classes []*domain.Class
+	for _, c := range p.classes {
+		if p.files[c] != file {
+			continue
+		} else if !p.identified(c) {
+			return nil, false
+		}
+
+		encoded := &class{Name: c.Name, Docstring: c.Docstring, Closed: c.Closed, Root: p.roots[c], Pointer: p.pointers[c]}
+		if c.Source != nil {
+			encoded.Source = c.Path()
+		}
+		if c.Schema != nil {
+			encoded.ID = c.Schema.GetSchemaURI()
+		}
+
+		for _, prop := range c.Properties {
+			encoded.Properties = append(encoded.Properties, &property{Name: prop.Name, Type: prop.Type, Docstring: prop.Docstring})
+		}
+
+		classes = append(classes, c)
+		d.Classes = append(d.Classes, encoded)
+	}
+
+	for _, r := range p.relations {
+		from := slices.Index(classes, r.From)
+		if from < 0 {
+			continue
+		} else if !p.identified(r.To) {
+			return nil, false
+		}
+
+		d.Relations = append(d.Relations, &relation{
+			Type:         r.Type,
+			From:         from,
+			FromProperty: propertyName(r.FromProperty),
+			ToFile:       p.files[r.To],
+			To:           r.To.Name,
+			ToPointer:    p.pointers[r.To],
+			ToProperty:   propertyName(r.ToProperty),
+		})
+	}
+
+	return d, true
+}
+
+// identified returns true iff the class has a pointer to its schema or is the only class of its file with its name.
+// Anonymous classes are named by the order in which they are parsed and are hence only identified by their pointer
+func (p *parsed) identified(c *domain.Class) bool {
+	if p.pointers[c] != "" {
+		return true
+	} else if strings.TrimSpace(c.Name) == "" {
+		return false
+	}
+
+	for _, other := range p.classes {
+		if other != c && other.Name == c.Name && p.files[other] == p.files[c] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// valid returns true iff the document is of the current version and every relation is from one of its classes
+func (d *document) valid() bool {
+	if d.Version != version {
+		return false
+	}
+
+	for _, r := range d.Relations {
+		if r.From < 0 || r.From >= len(d.Classes) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// restore the classes of the document, the relations are restored by restoreRelations once the classes of every
+// document are restored. The schemas of the classes only have their URI as the documents are not compiled
+func (d *document) restore(compiler *jsonschema.Compiler) []*domain.Class {
+	classes := make([]*domain.Class, 0, len(d.Classes))
+	for _, encoded := range d.Classes {
+		c := &domain.Class{Source: domain.FileSource{FilePath: encoded.Source}, Schema: stub(compiler, encoded.ID), Name: encoded.Name, Docstring: encoded.Docstring, Closed: encoded.Closed}
+		for _, p := range encoded.Properties {
+			c.Properties = append(c.Properties, &domain.Property{Parent: c, Name: p.Name, Type: p.Type, Docstring: p.Docstring})
+		}
+
+		classes = append(classes, c)
+	}
+
+	return classes
+}
+
+// restoreRelations of the document between its classes and the classes found by their file and pointer (or name),
+// ok is false if a class or property cannot be found
+func (d *document) restoreRelations(classes []*domain.Class, find func(file string, pointer string, name string) *domain.Class) ([]*domain.Relation, bool) {
+	relations := make([]*domain.Relation, 0, len(d.Relations))
+	for _, encoded := range d.Relations {
+		r := &domain.Relation{Type: encoded.Type, From: classes[encoded.From], To: find(encoded.ToFile, encoded.ToPointer, encoded.To)}
+		if r.To == nil {
+			return nil, false
+		}
+
+		r.FromProperty = findProperty(r.From, encoded.FromProperty)
+		r.ToProperty = findProperty(r.To, encoded.ToProperty)
+		if (r.FromProperty == nil) != (encoded.FromProperty == "") || (r.ToProperty == nil) != (encoded.ToProperty == "") {
+			return nil, false
+		}
+
+		relations = append(relations, r)
+	}
+
+	return relations, true
+}
+
+// propertyName of the property or an empty string if nil
+func propertyName(p *domain.Property) string {
+	if p == nil {
+		return ""
+	}
+
+	return p.Name
+}
+
+// findProperty of the class by name or nil if the name is empty or not found
+func findProperty(c *domain.Class, name string) *domain.Property {
+	if name == "" {
+		return nil
+	}
+
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+// stub schema with the URI id such that jsonschema.Schema GetSchemaURI returns it
+func stub(compiler *jsonschema.Compiler, id string) *jsonschema.Schema {
+	if id != "" {
+		if schema, err := compiler.Compile([]byte(`{}`), id); err == nil {
+			return schema
+		}
+	}
+
+	return &jsonschema.Schema{}
+}
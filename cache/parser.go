@@ -0,0 +1,372 @@
+package cache
+
+import (
+	"encoding/json"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/kaptinlin/jsonschema"
+	"github.com/sirupsen/logrus"
+)
+
+// Parser returns the classes and relations of the parse.Parser where the classes and relations of every schema
+// document are stored in the Dir. Documents are restored from the Dir if none of the files they depend on changed,
+// only the files matched by the Globs that (transitively) reference a changed document are parsed again
+type Parser struct {
+	*parse.Parser
+
+	// Dir the parsed documents are stored in
+	Dir *Dir
+
+	// Hit is true iff the classes and relations of every document are restored from the Dir
+	Hit bool
+
+	// Parsed are the files matched by the Globs that are parsed again as (one of) the documents they depend on changed
+	Parsed []string
+
+	classes   []*domain.Class
+	relations []*domain.Relation
+	loaded    bool
+
+	// hashes of the files read by hash
+	hashes map[string]string
+}
+
+// NewParser caching the results of the parse.Parser in the Dir
+func NewParser(parser *parse.Parser, dir *Dir) *Parser {
+	return &Parser{Parser: parser, Dir: dir}
+}
+
+// Classes of the parse.Parser, restored from the Dir if unchanged
+func (p *Parser) Classes() ([]*domain.Class, error) {
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p.classes, nil
+}
+
+// Relations of the parse.Parser, restored from the Dir if unchanged
+func (p *Parser) Relations() ([]*domain.Relation, error) {
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p.relations, nil
+}
+
+// load the classes and relations of the documents from the Dir and parse the files matched by the Globs of which
+// (one of) the documents changed
+func (p *Parser) load() error {
+	if p.loaded {
+		return nil
+	}
+
+	matches, err := p.Parser.Matches()
+	if err != nil {
+		return err
+	}
+	for i, match := range matches {
+		matches[i] = p.name(match)
+	}
+
+	documents := p.restore(matches)
+	for _, match := range matches {
+		if !restored(match, documents) {
+			p.Parsed = append(p.Parsed, match)
+		}
+	}
+	p.Hit = len(p.Parsed) == 0
+
+	if p.Hit {
+		logrus.Info("schemas are unchanged, using the cached classes and relations")
+	} else {
+		logrus.Info("parsing changed schemas: ", p.Parsed)
+	}
+
+	var res *parsed
+	if !p.Hit {
+		if res, err = p.parse(p.Parsed); err != nil {
+			return err
+		}
+	}
+
+	classes, relations, roots, ok := p.merge(matches, documents, res)
+	if !ok {
+		// the documents of the cache and the parsed documents do not agree, hence parse every document again
+		p.Parsed, p.Hit = matches, false
+		if res, err = p.parse(matches); err != nil {
+			return err
+		}
+		classes, relations, roots, _ = p.merge(matches, nil, res)
+	}
+
+	if p.Parser.Depth >= 0 {
+		classes, relations = withinDepth(classes, relations, roots, p.Parser.Depth)
+	}
+	parse.SortClasses(classes)
+	parse.SortRelations(relations)
+	p.classes, p.relations, p.loaded = classes, relations, true
+
+	return nil
+}
+
+// restore the documents of the files and (transitively) the files they depend on from the Dir, documents that are
+// not stored or of which one of the files changed are omitted
+func (p *Parser) restore(files []string) map[string]*document {
+	res := map[string]*document{}
+	queue := slices.Clone(files)
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if _, ok := res[file]; ok {
+			continue
+		}
+		res[file] = nil
+
+		data, ok := p.Dir.Get(p.key(file))
+		if !ok {
+			continue
+		}
+
+		d := &document{}
+		if decodeErr := json.Unmarshal(data, d); decodeErr != nil || !d.valid() || d.File != file || !p.unchanged(d.Files) {
+			continue
+		}
+
+		res[file] = d
+		queue = append(queue, slices.Sorted(maps.Keys(d.Files))...)
+	}
+
+	for file, d := range res {
+		if d == nil {
+			delete(res, file)
+		}
+	}
+
+	return res
+}
+
+// restored returns true iff the document of the file and the documents of every file it depends on are restored
+func restored(file string, documents map[string]*document) bool {
+	d, ok := documents[file]
+	if !ok {
+		return false
+	}
+
+	for dependency := range d.Files {
+		if _, ok = documents[dependency]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parse the files and store the classes and relations of every document that is read in the Dir
+func (p *Parser) parse(files []string) (*parsed, error) {
+	parser := parse.NewParser(files...).SetBaseURI(p.Parser.BaseURI).SetJobs(p.Parser.Jobs).SetDepth(-1)
+	parser.StrictMode = p.Parser.StrictMode
+	if p.Parser.FS != nil {
+		parser.SetFS(p.Parser.FS)
+	}
+
+	classes, err := parser.Classes()
+	if err != nil {
+		return nil, err
+	}
+	relations, err := parser.Relations()
+	if err != nil {
+		return nil, err
+	}
+	p.Parser.Track(parser.Files()...)
+
+	res := newParsed(parser, classes, relations)
+	if !res.complete() {
+		logrus.Warn("could not cache the classes and relations: the document of a class is unknown")
+		return res, nil
+	}
+
+	for _, file := range slices.Sorted(maps.Keys(res.documents)) {
+		d, ok := res.document(file, p.hash)
+		if !ok {
+			logrus.Debug("could not cache the classes and relations of ", file)
+			continue
+		}
+
+		data, err := json.Marshal(d)
+		if err == nil {
+			err = p.Dir.Put(p.key(file), data)
+		}
+		if err != nil {
+			logrus.Warn("could not cache the classes and relations: ", err)
+		}
+	}
+
+	return res, nil
+}
+
+// merge the parsed classes and relations with those of the documents restored from the Dir that are not parsed
+// again. The roots are the classes parsed from the root schema of the matched files. Ok is false if a document that
+// is not parsed again cannot be restored
+func (p *Parser) merge(matches []string, documents map[string]*document, res *parsed) (classes []*domain.Class, relations []*domain.Relation, roots map[*domain.Class]bool, ok bool) {
+	files := map[*domain.Class]string{}
+	pointers := map[*domain.Class]string{}
+	roots = map[*domain.Class]bool{}
+	if res != nil {
+		classes, relations = slices.Clone(res.classes), slices.Clone(res.relations)
+		for _, c := range classes {
+			files[c], pointers[c] = res.files[c], res.pointers[c]
+			roots[c] = res.roots[c] && slices.Contains(matches, res.files[c])
+		}
+	}
+
+	// anonymous classes are named by the order in which they are parsed (see parse.ClassParser NewClass), hence the
+	// restored ones are named after the parsed ones such that every name is unique
+	anonymous := 0
+	for _, c := range classes {
+		if strings.TrimSpace(c.Name) == "" {
+			anonymous = max(anonymous, len(c.Name))
+		}
+	}
+
+	needed := map[string]bool{}
+	for _, match := range matches {
+		if d, ok := documents[match]; ok && !slices.Contains(p.Parsed, match) {
+			for file := range d.Files {
+				needed[file] = true
+			}
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	restored := map[string][]*domain.Class{}
+	for _, file := range slices.Sorted(maps.Keys(needed)) {
+		if res != nil && res.documents[file] != nil {
+			continue // parsed again
+		} else if documents[file] == nil {
+			return nil, nil, nil, false
+		}
+
+		restored[file] = documents[file].restore(compiler)
+		for i, c := range restored[file] {
+			files[c], pointers[c] = file, documents[file].Classes[i].Pointer
+			roots[c] = documents[file].Classes[i].Root && slices.Contains(matches, file)
+			if strings.TrimSpace(c.Name) == "" {
+				anonymous++
+				c.Name = strings.Repeat(" ", anonymous)
+			}
+		}
+		classes = append(classes, restored[file]...)
+		p.Parser.Track(slices.Collect(maps.Keys(documents[file].Files))...)
+	}
+
+	find := func(file string, pointer string, name string) *domain.Class {
+		for _, c := range classes {
+			if files[c] == file && ((pointer != "" && pointers[c] == pointer) || (pointer == "" && c.Name == name)) {
+				return c
+			}
+		}
+
+		return nil
+	}
+	for _, file := range slices.Sorted(maps.Keys(restored)) {
+		r, restoredRelations := documents[file].restoreRelations(restored[file], find)
+		if !restoredRelations {
+			return nil, nil, nil, false
+		}
+		relations = append(relations, r...)
+	}
+
+	return classes, relations, roots, true
+}
+
+// withinDepth returns the classes at most depth relations away from one of the roots and the relations between them
+// (see parse.DepthMap)
+func withinDepth(classes []*domain.Class, relations []*domain.Relation, roots map[*domain.Class]bool, depth int) ([]*domain.Class, []*domain.Relation) {
+	// the roots go first as parse.DepthMap finds the class of a root by its schema, which a restored class shares with
+	// the other classes of its resource (see stub)
+	var schemas []*jsonschema.Schema
+	ordered := make([]*domain.Class, 0, len(classes))
+	for _, c := range classes {
+		if roots[c] {
+			schemas = append(schemas, c.Schema)
+			ordered = append(ordered, c)
+		}
+	}
+	for _, c := range classes {
+		if !roots[c] {
+			ordered = append(ordered, c)
+		}
+	}
+
+	depthMap := parse.DepthMap(schemas, ordered, relations)
+	within := func(c *domain.Class) bool {
+		d, ok := depthMap[c]
+		return ok && d != parse.Unreachable && d <= depth
+	}
+
+	return slices.DeleteFunc(classes, func(c *domain.Class) bool { return !within(c) }),
+		slices.DeleteFunc(relations, func(r *domain.Relation) bool { return !within(r.From) || !within(r.To) })
+}
+
+// key of the document of the file from the options of the parse.Parser
+func (p *Parser) key(file string) string {
+	return Key("document", strconv.Itoa(version), p.Parser.BaseURI, strconv.FormatBool(p.Parser.StrictMode), file)
+}
+
+// name of the file as reported by parse.Parser Files, i.e. its absolute path unless read from the FS
+func (p *Parser) name(file string) string {
+	if p.Parser.FS != nil {
+		return file
+	}
+
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+
+	return file
+}
+
+// unchanged returns true iff every file still has the same hash
+func (p *Parser) unchanged(files map[string]string) bool {
+	for file, hash := range files {
+		if current, err := p.hash(file); err != nil || current != hash {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hash of the contents of the file on disk or in the FS of the parse.Parser, every file is read once
+func (p *Parser) hash(file string) (string, error) {
+	if hash, ok := p.hashes[file]; ok {
+		return hash, nil
+	}
+
+	var contents []byte
+	var err error
+	if p.Parser.FS != nil {
+		contents, err = fs.ReadFile(p.Parser.FS, file)
+	} else {
+		contents, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if p.hashes == nil {
+		p.hashes = map[string]string{}
+	}
+	p.hashes[file] = Hash(contents)
+
+	return p.hashes[file], nil
+}
@@ -13,7 +13,7 @@ var rootCmd = &cobra.Command{
 	Long: `Welcome to the JSON Schema Transformer CLI
 
 The goal of this transformer is to generate diagrams from JSON schema files. This helps to visualize the overall schema`,
-	PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 		verboseFlag, _ := cmd.Flags().GetCount("verbose")
 		quietFlag, _ := cmd.Flags().GetCount("quiet")
 
@@ -22,6 +22,8 @@ The goal of this transformer is to generate diagrams from JSON schema files. Thi
 		logLevel := logrus.WarnLevel + logrus.Level(verboseFlag) - logrus.Level(quietFlag)
 		logrus.SetLevel(logLevel)
 		logrus.SetFormatter(&MsgFormatter{})
+
+		return applyConfig(cmd)
 	},
 }
 
@@ -41,5 +43,6 @@ func Execute() {
 
 func init() {
 	rootCmd.PersistentFlags().CountP("verbose", "v", "Increase the verbosity of the output by one level, -v shows informational logs and -vv will output debug information.")
+	rootCmd.PersistentFlags().String(configFlag.Name, configFlag.Value.(string), configFlag.Usage)
 	rootCmd.PersistentFlags().CountP("quiet", "q", "Decrease the verbosity of the output by one level, -v hides warning logs and -vv will suppress non-fatal errors")
 }
@@ -2,7 +2,14 @@ package main
 
 import (
 	"errors"
+	"time"
 
+	"github.com/Emptyless/jsonschema-transform/cache"
+	"github.com/Emptyless/jsonschema-transform/config"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/transform"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
@@ -45,6 +52,13 @@ var globsFlag = flag{
 	Usage: "glob patterns to match (e.g. '**/*.json', '*.json', 'file.json')",
 }
 
+var excludeFlag = flag{
+	Name:  "exclude",
+	Short: "",
+	Value: []string{},
+	Usage: "glob patterns of files matched by --globs to skip (e.g. 'schemas/internal/*.json')",
+}
+
 var baseURIFlag = flag{
 	Name:  "base-uri",
 	Short: "",
@@ -52,6 +66,80 @@ var baseURIFlag = flag{
 	Usage: "when provided the base-uri will be used to resolve json schemas using the $id property. If the schema does not start with 'http' or 'https' it's assumed to be a 'file://' reference",
 }
 
+var rendererFlag = flag{
+	Name:  "renderer",
+	Short: "",
+	Value: "embedded",
+	Usage: "renderer used for svg and png output, one of 'embedded' (D2 library, png and additional args fall back to external) or 'external' (d2 binary, see --tool)",
+}
+
+var layoutFlag = flag{
+	Name:  "layout",
+	Short: "",
+	Value: "",
+	Usage: "layout engine of the D2 diagram, one of 'dagre' or 'elk' (if empty the D2 default is used)",
+}
+
+var directionFlag = flag{
+	Name:  "direction",
+	Short: "",
+	Value: "",
+	Usage: "direction of the D2 diagram, one of 'up', 'down', 'left' or 'right' (if empty the D2 default is used)",
+}
+
+var themeFlag = flag{
+	Name:  "theme",
+	Short: "",
+	Value: -1,
+	Usage: "ID of the D2 theme (see https://d2lang.com/tour/themes), if negative the D2 default is used",
+}
+
+var darkThemeFlag = flag{
+	Name:  "dark-theme",
+	Short: "",
+	Value: -1,
+	Usage: "ID of the D2 theme used when the viewer prefers a dark color scheme, if negative no dark theme is used",
+}
+
+var sketchFlag = flag{
+	Name:  "sketch",
+	Short: "",
+	Value: false,
+	Usage: "if provided renders the D2 diagram as if it was drawn by hand",
+}
+
+var padFlag = flag{
+	Name:  "pad",
+	Short: "",
+	Value: -1,
+	Usage: "padding around the D2 diagram in pixels, if negative the D2 default is used",
+}
+
+var centerFlag = flag{
+	Name:  "center",
+	Short: "",
+	Value: false,
+	Usage: "if provided centers the D2 diagram in the viewbox",
+}
+
+var templateDirFlag = flag{
+	Name:  "template-dir",
+	Short: "",
+	Value: "",
+	Usage: "directory with templates overriding the defaults ('class.tmpl', 'relation.tmpl', 'container.tmpl', 'config.tmpl' and 'document.tmpl')",
+}
+
+var linkTemplateFlag = flag{
+	Name:  "link-template",
+	Short: "",
+	Value: "",
+	Usage: "template of the link of a class, e.g. 'https://git.acme/repo/blob/main/{{ .Path }}' (if empty classes are not linked)",
+}
+
+// d2ConfigFlags configure the rendering of a D2 diagram, see newD2Config. The linkTemplateFlag is only registered by
+// the commands that do not link the classes themselves
+var d2ConfigFlags = []flag{rendererFlag, toolFlag, layoutFlag, directionFlag, themeFlag, darkThemeFlag, sketchFlag, padFlag, centerFlag, templateDirFlag}
+
 var allowOverwriteFlag = flag{
 	Name:  "overwrite",
 	Short: "",
@@ -59,11 +147,18 @@ var allowOverwriteFlag = flag{
 	Usage: "if provided allows existing files to be overwritten (i.e. regenerate)",
 }
 
+var watchFlag = flag{
+	Name:  "watch",
+	Short: "",
+	Value: false,
+	Usage: "watch the schemas (and every file they reference) and regenerate the output on changes until interrupted",
+}
+
 var toolFlag = flag{
 	Name:  "tool",
 	Short: "",
 	Value: "",
-	Usage: "path to the tool to render the 'd2' file, if empty 'which d2' is used",
+	Usage: "path to the tool to render the 'd2' file when using the external renderer, if empty 'which d2' is used",
 }
 
 var containerBasePathFlag = flag{
@@ -80,8 +175,260 @@ var depthFlag = flag{
 	Usage: "max depth of external $refs that can be followed from a glob reference schema",
 }
 
+var jobsFlag = flag{
+	Name:  "jobs",
+	Short: "j",
+	Value: 0,
+	Usage: "number of schema files read and normalized concurrently, if 0 the number of CPUs is used",
+}
+
+var cacheDirFlag = flag{
+	Name:  "cache-dir",
+	Short: "",
+	Value: "",
+	Usage: "directory caching the parsed schemas and rendered diagrams between runs such that unchanged inputs are not parsed or rendered again, if empty nothing is cached",
+}
+
+var cacheMaxAgeFlag = flag{
+	Name:  "cache-max-age",
+	Short: "",
+	Value: 30,
+	Usage: "number of days after which entries of the --cache-dir that are not used are removed, if 0 nothing is removed",
+}
+
+var dataFlag = flag{
+	Name:  "data",
+	Short: "d",
+	Value: []string{},
+	Usage: "glob patterns to match instance documents to validate (.json, .yaml, .yml, .ndjson or .jsonl)",
+}
+
+var schemaIDFlag = flag{
+	Name:  "schema-id",
+	Short: "",
+	Value: "",
+	Usage: "$id of the schema used to validate every instance, overrides the '$schema' property of instances and --schema-map",
+}
+
+var schemaMapFlag = flag{
+	Name:  "schema-map",
+	Short: "",
+	Value: []string{},
+	Usage: "map instance files to a schema as 'pattern=schema-id' (e.g. 'pets/*.yaml=file:///schemas/pet.json'), used when an instance has no '$schema' property",
+}
+
+var reportFormatFlag = flag{
+	Name:  "format",
+	Short: "f",
+	Value: "text",
+	Usage: "format of the validation report, one of 'text', 'json' or 'junit'",
+}
+
+var bundleOutputFlag = flag{
+	Name:  "output",
+	Short: "o",
+	Value: "bundle.json",
+	Usage: "Optionally set the location of the bundled schema, use '-' to write to stdout",
+}
+
+var dereferenceFlag = flag{
+	Name:  "dereference",
+	Short: "",
+	Value: false,
+	Usage: "if provided inlines every $ref that is not recursive instead of referencing the embedded schemas",
+}
+
+var bundleIDFlag = flag{
+	Name:  "id",
+	Short: "",
+	Value: "",
+	Usage: "$id of the bundled schema when multiple schemas are bundled (a single schema keeps its own $id)",
+}
+
+var statsFormatFlag = flag{
+	Name:  "format",
+	Short: "f",
+	Value: "table",
+	Usage: "format of the statistics, one of 'table', 'json' or 'prometheus'",
+}
+
+var topFlag = flag{
+	Name:  "top",
+	Short: "",
+	Value: 5,
+	Usage: "amount of largest classes (by amount of properties) to report",
+}
+
+var failOnCyclesFlag = flag{
+	Name:  "fail-on-cycles",
+	Short: "",
+	Value: false,
+	Usage: "if provided exits with an error when one or more cycles are found (e.g. to use in CI)",
+}
+
+var impactOutputFlag = flag{
+	Name:  "output",
+	Short: "o",
+	Value: "",
+	Usage: "Optionally render the dependency subgraph as a D2 diagram to the output file (e.g. impact.d2 or impact.svg)",
+}
+
+var templateFileFlag = flag{
+	Name:  "template",
+	Short: "t",
+	Value: "",
+	Usage: "path to the Go text/template executed against the view model",
+}
+
+var templateOutputFlag = flag{
+	Name:  "output",
+	Short: "o",
+	Value: "-",
+	Usage: "Optionally set the location of the output file, use '-' to write to stdout. With --per-class the directory in which the files are written",
+}
+
+var perClassFlag = flag{
+	Name:  "per-class",
+	Short: "",
+	Value: false,
+	Usage: "if provided renders the template once per class to the file named by --filename",
+}
+
+var filenameFlag = flag{
+	Name:  "filename",
+	Short: "",
+	Value: "{{ .Class.Slug }}.txt",
+	Usage: "template of the file name of a class (relative to --output and unique per class) when using --per-class",
+}
+
+var siteOutputFlag = flag{
+	Name:  "output",
+	Short: "o",
+	Value: "site",
+	Usage: "Optionally set the directory the site is written to",
+}
+
+var titleFlag = flag{
+	Name:  "title",
+	Short: "",
+	Value: "Schemas",
+	Usage: "title of the site",
+}
+
+var upgradeOutputFlag = flag{
+	Name:  "output",
+	Short: "o",
+	Value: "",
+	Usage: "Optionally set the directory the upgraded schemas are written to (keeping their path relative to the working directory), if empty the schemas are upgraded in place",
+}
+
+var configFlag = flag{
+	Name:  "config",
+	Short: "",
+	Value: "",
+	Usage: "path of the configuration file, if empty " + config.FileName + " is searched in the working directory and its parents",
+}
+
+var addrFlag = flag{
+	Name:  "addr",
+	Short: "",
+	Value: "localhost:8080",
+	Usage: "address the preview server listens on",
+}
+
 // ErrNoGlobs is returned when no globs are provided (which is a no-op)
-var ErrNoGlobs = errors.New("no globs provided")
+var ErrNoGlobs = transform.ErrNoGlobs
+
+// ErrNoData is returned when no data globs are provided (which is a no-op)
+var ErrNoData = errors.New("no data globs provided")
+
+// ErrInvalidInstances is returned when one or more instances failed validation
+var ErrInvalidInstances = errors.New("one or more instances are invalid")
+
+// ErrCyclesFound is returned when one or more cycles are found and failing on cycles is requested
+var ErrCyclesFound = errors.New("one or more cycles found")
+
+// ErrNoTemplate is returned when no template is provided
+var ErrNoTemplate = errors.New("no template provided")
+
+// ErrUnknownOption is returned when an option of the configuration file does not match a flag of the command
+var ErrUnknownOption = errors.New("unknown option")
+
+// ErrValidationChanged is returned when an instance has a different validation outcome after upgrading the schemas
+var ErrValidationChanged = errors.New("validation outcome changed by upgrading the schemas")
+
+// ErrInvalidFilename is returned when the --filename of a class is empty or not inside the --output directory
+var ErrInvalidFilename = errors.New("file name is empty or outside of the output directory")
+
+// ErrDuplicateFilename is returned when the --filename of two classes is the same
+var ErrDuplicateFilename = errors.New("file name is used by more than one class")
 
 // ErrNoOverwrite is returned when a file would be overwritten which is not allowed
 var ErrNoOverwrite = errors.New("file exists but overwrite of file is not allowed")
+
+// openCache of the cacheDirFlag pruning the entries older than the cacheMaxAgeFlag, nil if no directory is set
+func openCache(cmd *cobra.Command) (*cache.Dir, error) {
+	path := cmd.Flag(cacheDirFlag.Name).Value.String()
+	if path == "" {
+		return nil, nil
+	}
+
+	dir, err := cache.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	maxAge, err := cmd.Flags().GetInt(cacheMaxAgeFlag.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxAge > 0 {
+		removed, pruneErr := dir.Prune(time.Duration(maxAge) * 24 * time.Hour)
+		if pruneErr != nil {
+			return nil, pruneErr
+		}
+		logrus.Debugf("removed %d cache entries not used for %d days", removed, maxAge)
+	}
+
+	return dir, nil
+}
+
+// cachedParser restoring the classes and relations of the parser from the dir (see cache.Parser), or the parser itself
+// if the dir is nil
+func cachedParser(parser *parse.Parser, dir *cache.Dir) transform.Parser {
+	if dir == nil {
+		return parser
+	}
+
+	return cache.NewParser(parser, dir)
+}
+
+// newParser from the globsFlag, excludeFlag, baseURIFlag and (if registered on the command) depthFlag and jobsFlag,
+// see transform.NewParser
+func newParser(cmd *cobra.Command) (*parse.Parser, error) {
+	opts := transform.Options{
+		Globs:   cmd.Flag(globsFlag.Name).Value.(pflag.SliceValue).GetSlice(),
+		BaseURI: cmd.Flag(baseURIFlag.Name).Value.String(),
+	}
+
+	if f := cmd.Flags().Lookup(excludeFlag.Name); f != nil {
+		opts.Exclude = f.Value.(pflag.SliceValue).GetSlice()
+	}
+	if cmd.Flags().Lookup(depthFlag.Name) != nil {
+		depth, err := cmd.Flags().GetInt(depthFlag.Name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Depth = &depth
+	}
+	if cmd.Flags().Lookup(jobsFlag.Name) != nil {
+		jobs, err := cmd.Flags().GetInt(jobsFlag.Name)
+		if err != nil {
+			return nil, err
+		}
+		opts.Jobs = jobs
+	}
+
+	return transform.NewParser(opts)
+}
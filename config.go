@@ -0,0 +1,100 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// loadConfig from --config or the config.FileName closest to the working directory. If required is false and no
+// configuration file is found, nil is returned
+func loadConfig(cmd *cobra.Command, required bool) (*config.Config, error) {
+	path := cmd.Flag(configFlag.Name).Value.String()
+	if path == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		if path, err = config.Find(cwd); errors.Is(err, config.ErrNotFound) && !required {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return config.Load(path)
+}
+
+// applyConfig defaults of the configuration file (if any) to the flags of the command that are not set explicitly
+func applyConfig(cmd *cobra.Command) error {
+	cfg, err := loadConfig(cmd, false)
+	if err != nil || cfg == nil {
+		return err
+	}
+
+	return applyFlags(cmd, cfg.Defaults(), false)
+}
+
+// applyFlags values by flag name to the flags of the command that are not set explicitly (i.e. flags on the command
+// line take precedence). If strict, values for flags the command does not have result in ErrUnknownOption
+func applyFlags(cmd *cobra.Command, values map[string]any, strict bool) error {
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		f := cmd.Flags().Lookup(name)
+		if f == nil && strict {
+			return fmt.Errorf("%w: %s has no option %s", ErrUnknownOption, cmd.Name(), name)
+		} else if f == nil || f.Changed {
+			continue
+		}
+
+		if err := setFlag(f, values[name]); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrUnknownOption, name, err)
+		}
+	}
+
+	return nil
+}
+
+// setFlag to the value without marking it as changed such that it can still be reset (see resetFlags)
+func setFlag(f *pflag.Flag, value any) error {
+	sliceValue, ok := f.Value.(pflag.SliceValue)
+	if !ok {
+		return f.Value.Set(fmt.Sprint(value))
+	}
+
+	switch value := value.(type) {
+	case []string:
+		return sliceValue.Replace(value)
+	case []any:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			values = append(values, fmt.Sprint(v))
+		}
+		return sliceValue.Replace(values)
+	default:
+		return sliceValue.Replace([]string{fmt.Sprint(value)})
+	}
+}
+
+// resetFlags of the command to their default values
+func resetFlags(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if sliceValue, ok := f.Value.(pflag.SliceValue); ok {
+			var values []string
+			if defValue := strings.Trim(f.DefValue, "[]"); defValue != "" {
+				values = strings.Split(defValue, ",")
+			}
+			_ = sliceValue.Replace(values)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
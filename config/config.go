@@ -0,0 +1,237 @@
+// Package config reads the project configuration (.jsonschema-transform.yaml) of jsonschema-transform
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// FileName of the configuration file discovered by Find
+const FileName = ".jsonschema-transform.yaml"
+
+// SchemaID of the Schema the configuration file is validated against
+const SchemaID = "https://github.com/Emptyless/jsonschema-transform/config.schema.json"
+
+// ErrNotFound is returned when no configuration file is found
+var ErrNotFound = errors.New("no " + FileName + " found")
+
+// ErrInvalidConfig is returned when the configuration file is not valid w.r.t. the Schema
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ErrUnknownTarget is returned when a target is not declared in the configuration file
+var ErrUnknownTarget = errors.New("unknown target")
+
+// Schema of the configuration file
+//
+//go:embed config.schema.json
+var Schema []byte
+
+// PathOptions are the options holding (glob patterns of) paths, relative paths are resolved against the directory of
+// the configuration file
+var PathOptions = []string{"globs", "exclude", "base-uri", "output", "template", "template-dir", "data"}
+
+// Config of a project
+type Config struct {
+	Flags
+
+	// Targets by name
+	Targets map[string]*Target `json:"targets,omitempty"`
+
+	// Path of the configuration file
+	Path string `json:"-"`
+}
+
+// Flags shared by the commands, set on the Config they are the defaults of every command and Target
+type Flags struct {
+	// Globs of the schemas
+	Globs []string `json:"globs,omitempty"`
+
+	// Exclude files matched by the Globs
+	Exclude []string `json:"exclude,omitempty"`
+
+	// BaseURI used to resolve the schemas
+	BaseURI string `json:"base-uri,omitempty"`
+
+	// Depth of external $refs to follow
+	Depth *int `json:"depth,omitempty"`
+
+	// ContainerBasePath to put classes in containers representing directories
+	ContainerBasePath string `json:"container-base-path,omitempty"`
+
+	// Options of any other flag by name
+	Options map[string]any `json:"options,omitempty"`
+}
+
+// Target built by a command
+type Target struct {
+	Flags
+
+	// Command building the Target, e.g. 'd2'
+	Command string `json:"command"`
+
+	// Output of the Command
+	Output string `json:"output,omitempty"`
+
+	// Args of the Command
+	Args []string `json:"args,omitempty"`
+}
+
+// Find the configuration file in dir or the closest of its parent directories
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, statErr := os.Stat(path); statErr == nil && !info.IsDir() {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNotFound
+		}
+		dir = parent
+	}
+}
+
+// Load the configuration file and validate it against the Schema
+func Load(path string) (*Config, error) {
+	instances, err := validate.ReadInstances(path)
+	if err != nil {
+		return nil, err
+	} else if len(instances) != 1 {
+		return nil, fmt.Errorf("%w: %s: expected a single document", ErrInvalidConfig, path)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if _, err = compiler.Compile(Schema); err != nil {
+		return nil, err
+	}
+
+	validator := &validate.Validator{Compiler: compiler, SchemaID: SchemaID}
+	result, err := validator.Validate(instances[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Valid {
+		var messages []string
+		for _, e := range result.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", e.InstanceLocation, e.Message))
+		}
+
+		return nil, fmt.Errorf("%w: %s:\n    %s", ErrInvalidConfig, path, strings.Join(messages, "\n    "))
+	}
+
+	b, err := json.Marshal(instances[0].Data)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{}
+	if err = json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, path, err)
+	}
+
+	if cfg.Path, err = filepath.Abs(path); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Defaults of every command by flag name
+func (c *Config) Defaults() map[string]any {
+	return c.resolve(c.Flags.values())
+}
+
+// TargetNames in sorted order
+func (c *Config) TargetNames() []string {
+	return slices.Sorted(maps.Keys(c.Targets))
+}
+
+// Target by name and its flag values by flag name, the values of the Target take precedence over the Defaults
+func (c *Config) Target(name string) (*Target, map[string]any, error) {
+	target, ok := c.Targets[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s (declared: %s)", ErrUnknownTarget, name, strings.Join(c.TargetNames(), ", "))
+	}
+
+	values := c.Flags.values()
+	maps.Copy(values, target.Flags.values())
+	if target.Output != "" {
+		values["output"] = target.Output
+	}
+
+	return target, c.resolve(values), nil
+}
+
+// values of the Flags by flag name
+func (f *Flags) values() map[string]any {
+	res := map[string]any{}
+	maps.Copy(res, f.Options)
+
+	if len(f.Globs) > 0 {
+		res["globs"] = f.Globs
+	}
+	if len(f.Exclude) > 0 {
+		res["exclude"] = f.Exclude
+	}
+	if f.BaseURI != "" {
+		res["base-uri"] = f.BaseURI
+	}
+	if f.Depth != nil {
+		res["depth"] = *f.Depth
+	}
+	if f.ContainerBasePath != "" {
+		res["container-base-path"] = f.ContainerBasePath
+	}
+
+	return res
+}
+
+// resolve the relative paths of the PathOptions against the directory of the configuration file
+func (c *Config) resolve(values map[string]any) map[string]any {
+	dir := filepath.Dir(c.Path)
+	resolvePath := func(path string) string {
+		if path == "" || path == "-" || filepath.IsAbs(path) || strings.Contains(path, "://") {
+			return path
+		}
+
+		return filepath.Join(dir, path)
+	}
+
+	for _, name := range PathOptions {
+		switch value := values[name].(type) {
+		case string:
+			values[name] = resolvePath(value)
+		case []string:
+			resolved := make([]string, 0, len(value))
+			for _, path := range value {
+				resolved = append(resolved, resolvePath(path))
+			}
+			values[name] = resolved
+		case []any:
+			resolved := make([]string, 0, len(value))
+			for _, path := range value {
+				resolved = append(resolved, resolvePath(fmt.Sprint(path)))
+			}
+			values[name] = resolved
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind_SearchesParentDirectories(t *testing.T) {
+	// Act
+	path, err := Find("testdata/project/schemas")
+	_, notFoundErr := Find(t.TempDir())
+
+	// Assert
+	require.NoError(t, err)
+	abs, err := filepath.Abs("testdata/project/" + FileName)
+	require.NoError(t, err)
+	assert.Equal(t, abs, path)
+	require.ErrorIs(t, notFoundErr, ErrNotFound)
+}
+
+func TestLoad_ResolvesTargets(t *testing.T) {
+	// Arrange
+	dir, err := filepath.Abs("testdata/project")
+	require.NoError(t, err)
+
+	// Act
+	cfg, err := Load(filepath.Join(dir, FileName))
+	require.NoError(t, err)
+	target, values, err := cfg.Target("api-diagram")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "d2", target.Command)
+	assert.Equal(t, map[string]any{
+		"globs":    []string{filepath.Join(dir, "schemas/*.json")},
+		"exclude":  []string{filepath.Join(dir, "schemas/internal.json")},
+		"base-uri": filepath.Join(dir, "schemas"),
+		"depth":    1,
+		"output":   filepath.Join(dir, "docs/api.svg"),
+		"layout":   "dagre",
+		"theme":    200.0,
+		"sketch":   true,
+	}, values)
+	assert.Equal(t, "elk", cfg.Defaults()["layout"])
+}
+
+func TestLoad_InvalidConfig(t *testing.T) {
+	// Act
+	_, err := Load("testdata/invalid.yaml")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "/globs: Value is string but should be array")
+	assert.ErrorContains(t, err, "/targets/api-diagram: Required property 'command' is missing")
+}
+
+func TestConfig_UnknownTarget(t *testing.T) {
+	// Arrange
+	cfg, err := Load("testdata/project/" + FileName)
+	require.NoError(t, err)
+
+	// Act
+	_, _, err = cfg.Target("docs")
+
+	// Assert
+	require.ErrorIs(t, err, ErrUnknownTarget)
+}
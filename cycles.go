@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/spf13/cobra"
+)
+
+// cyclesCmd registered to the rootCmd
+var cyclesCmd = &cobra.Command{
+	Use:          "cycles",
+	Short:        "list the cycles in the $ref graph of the json schemas",
+	Long:         "list every cycle of classes that (transitively) reference each other together with the properties involved",
+	Example:      fmt.Sprintf("%s cycles --globs 'schemas/*.json' --fail-on-cycles", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleCycles,
+}
+
+// init the cyclesCmd command
+func init() {
+	rootCmd.AddCommand(cyclesCmd)
+	globsFlag.Apply(cyclesCmd.Flags())
+	excludeFlag.Apply(cyclesCmd.Flags())
+	jobsFlag.Apply(cyclesCmd.Flags())
+	baseURIFlag.Apply(cyclesCmd.Flags())
+	failOnCyclesFlag.Apply(cyclesCmd.Flags())
+	cacheDirFlag.Apply(cyclesCmd.Flags())
+	cacheMaxAgeFlag.Apply(cyclesCmd.Flags())
+}
+
+// handleCycles for the cyclesCmd command
+func handleCycles(cmd *cobra.Command, _ []string) error {
+	failOnCycles, err := cmd.Flags().GetBool(failOnCyclesFlag.Name)
+	if err != nil {
+		return err
+	}
+
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	dir, err := openCache(cmd)
+	if err != nil {
+		return err
+	}
+
+	source := cachedParser(parser, dir)
+	classes, err := source.Classes()
+	if err != nil {
+		return err
+	}
+
+	relations, err := source.Relations()
+	if err != nil {
+		return err
+	}
+
+	cycles := parse.Cycles(classes, relations)
+	writeCycles(cmd.OutOrStdout(), cycles)
+
+	if failOnCycles && len(cycles) > 0 {
+		return fmt.Errorf("%w: %d", ErrCyclesFound, len(cycles))
+	}
+
+	return nil
+}
+
+// writeCycles as text, listing the classes of every parse.Cycle followed by the (unique) edges between them
+func writeCycles(w io.Writer, cycles []*parse.Cycle) {
+	if len(cycles) == 0 {
+		_, _ = fmt.Fprintln(w, "no cycles found")
+		return
+	}
+
+	for i, cycle := range cycles {
+		names := make([]string, 0, len(cycle.Classes))
+		for _, class := range cycle.Classes {
+			names = append(names, class.Name)
+		}
+		_, _ = fmt.Fprintf(w, "cycle %d: %s\n", i+1, strings.Join(names, ", "))
+
+		seen := map[string]bool{}
+		for _, relation := range cycle.Relations {
+			edge := cycleEdge(relation)
+			if seen[edge] {
+				continue
+			}
+			seen[edge] = true
+			_, _ = fmt.Fprintf(w, "    %s\n", edge)
+		}
+	}
+}
+
+// cycleEdge describes a domain.Relation as 'From.property -> To'
+func cycleEdge(relation *domain.Relation) string {
+	from := relation.From.Name
+	if relation.FromProperty != nil {
+		from += "." + relation.FromProperty.Name
+	}
+
+	return fmt.Sprintf("%s -> %s", from, relation.To.Name)
+}
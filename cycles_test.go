@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycles_ListsCycles(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{cyclesCmd.Use, "--globs", "./testdata/cycles/*.json", "--base-uri", "./", "--fail-on-cycles=false"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, outputBuffer.String(), "cycle 1: Leaf, Node\n")
+	assert.Contains(t, outputBuffer.String(), "    Leaf.node -> Node\n")
+	assert.Contains(t, outputBuffer.String(), "    Node.children -> Node\n")
+	assert.Contains(t, outputBuffer.String(), "    Node.leaf -> Leaf\n")
+}
+
+func TestCycles_FailOnCycles(t *testing.T) {
+	// Arrange
+	rootCmd.SetOut(new(bytes.Buffer))
+	args := []string{cyclesCmd.Use, "--globs", "./testdata/cycles/*.json", "--base-uri", "./", "--fail-on-cycles"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, ErrCyclesFound)
+}
+
+func TestCycles_NoCycles(t *testing.T) {
+	// Arrange
+	globs := cyclesCmd.Flags().Lookup(globsFlag.Name).Value.(pflag.SliceValue)
+	require.NoError(t, globs.Replace(nil)) // slice flags append to the values of previous executions
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{cyclesCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--fail-on-cycles"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "no cycles found\n", outputBuffer.String())
+}
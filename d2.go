@@ -3,15 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
-	"path"
-	"path/filepath"
 	"strings"
 
 	"github.com/Emptyless/jsonschema-transform/d2"
-	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/transform"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/pflag"
 )
 
 // d2Cmd registered to the rootCmd
@@ -29,87 +26,146 @@ func init() {
 	rootCmd.AddCommand(d2Cmd)
 	outputFlag.Apply(d2Cmd.Flags())
 	globsFlag.Apply(d2Cmd.Flags())
+	excludeFlag.Apply(d2Cmd.Flags())
+	jobsFlag.Apply(d2Cmd.Flags())
 	baseURIFlag.Apply(d2Cmd.Flags())
 	allowOverwriteFlag.Apply(d2Cmd.Flags())
-	toolFlag.Apply(d2Cmd.Flags())
+	watchFlag.Apply(d2Cmd.Flags())
 	containerBasePathFlag.Apply(d2Cmd.Flags())
 	depthFlag.Apply(d2Cmd.Flags())
+	cacheDirFlag.Apply(d2Cmd.Flags())
+	cacheMaxAgeFlag.Apply(d2Cmd.Flags())
+	for _, f := range d2ConfigFlags {
+		f.Apply(d2Cmd.Flags())
+	}
+	linkTemplateFlag.Apply(d2Cmd.Flags())
 	d2Cmd.Flags().StringP("", "", "", "additional args passed to the D2 (e.g. jsonschema-transform d2 --globs schema.json -- --layout elk")
 }
 
 // handleD2 for the d2Cmd command
 func handleD2(cmd *cobra.Command, _ []string) error {
-	globs := cmd.Flag(globsFlag.Name).Value.(pflag.SliceValue).GetSlice()
-	if len(globs) == 0 {
-		return ErrNoGlobs
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	outputFile := cmd.Flag(outputFlag.Name).Value.String()
+	if strings.Contains(outputFile, "%s") {
+		outputFile = strings.ReplaceAll(outputFile, "%s", "d2") // replace variable type with d2 extension
+	}
+
+	cfg, err := newD2Config(cmd, outputFile)
+	if err != nil {
+		return err
 	}
+	cfg.Args = cmd.Flags().Args()
 
-	containerBasePath := cmd.Flag(containerBasePathFlag.Name).Value.String()
-	baseURI := cmd.Flag(baseURIFlag.Name).Value.String()
-	if containerBasePath != "" && HasHTTPPrefix(baseURI) {
-		return fmt.Errorf("cannot use --%s when --%s is not a file:// based URI", containerBasePathFlag.Name, baseURIFlag.Name)
-	} else if containerBasePath != "" && baseURI == "" {
-		return fmt.Errorf("cannot use --%s when --%s is empty", containerBasePathFlag.Name, baseURIFlag.Name)
+	// resolve containerBasePath relative to the absolute baseURI
+	if cfg.ContainerBasePath, err = transform.ContainerBasePath(parser.BaseURI, cmd.Flag(containerBasePathFlag.Name).Value.String()); err != nil {
+		return fmt.Errorf("cannot use --%s when --%s is empty or not a file:// based URI: %w", containerBasePathFlag.Name, baseURIFlag.Name, err)
 	}
 
-	depth, err := cmd.Flags().GetInt(depthFlag.Name)
+	dir, err := openCache(cmd)
 	if err != nil {
 		return err
 	}
+	if dir != nil {
+		cfg.Cache = dir
+	}
+
+	transformer := transform.NewD2(transform.D2Options{Config: cfg})
 
-	parser := parse.NewParser(globs...).SetDepth(depth)
-	if baseURI != "" && !HasHTTPPrefix(baseURI) {
-		baseURIAbs, err := filepath.Abs(baseURI)
-		if err != nil {
-			logrus.Error("unable to determine absolute filepath", err)
-			return err
+	return runOrWatch(cmd, parser, func(overwrite bool) error {
+		output, d2Err := transformer.Transform(cachedParser(parser, dir))
+		if d2Err != nil {
+			return d2Err
 		}
 
-		// set parser with absolute baseURI
-		parser.SetBaseURI("file://" + baseURIAbs)
+		if _, statErr := os.Stat(outputFile); statErr == nil && !overwrite {
+			return ErrNoOverwrite
+		}
 
-		// resolve containerBasePath relative to baseURIAbs
-		if containerBasePath != "" {
-			containerBasePath = path.Join(baseURIAbs, containerBasePath)
+		if writeFileErr := os.WriteFile(outputFile, output, 0o644); writeFileErr != nil {
+			return writeFileErr
 		}
-	} else if baseURI != "" && HasHTTPPrefix(baseURI) {
-		parser.SetBaseURI(baseURI)
-	}
 
-	outputFile := cmd.Flag(outputFlag.Name).Value.String()
-	if strings.Contains(outputFile, "%s") {
-		outputFile = strings.ReplaceAll(outputFile, "%s", "d2") // replace variable type with d2 extension
-	}
+		logrus.Info("d2 diagram written to ", outputFile)
+
+		return nil
+	})
+}
 
+// newD2Config for the outputFile from the d2ConfigFlags
+func newD2Config(cmd *cobra.Command, outputFile string) (*d2.Config, error) {
 	format, err := d2.FormatFromFile(outputFile)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	output, err := d2.D2(parser, &d2.Config{
-		Format:            format,
-		Tool:              cmd.Flag(toolFlag.Name).Value.String(),
-		Args:              cmd.Flags().Args(),
-		ContainerBasePath: containerBasePath,
-	})
-	if err != nil {
-		return err
+	cfg := &d2.Config{
+		Format: format,
+		Tool:   cmd.Flag(toolFlag.Name).Value.String(),
+		Args:   []string{},
+	}
+
+	if cfg.Renderer, err = d2.ParseRenderer(cmd.Flag(rendererFlag.Name).Value.String()); err != nil {
+		return nil, err
+	}
+
+	if layout := cmd.Flag(layoutFlag.Name).Value.String(); layout != "" {
+		if cfg.Layout, err = d2.ParseLayout(layout); err != nil {
+			return nil, err
+		}
+	}
+
+	if direction := cmd.Flag(directionFlag.Name).Value.String(); direction != "" {
+		if cfg.Direction, err = d2.ParseDirection(direction); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.ThemeID, err = optionalInt(cmd, themeFlag); err != nil {
+		return nil, err
+	}
+
+	if cfg.DarkThemeID, err = optionalInt(cmd, darkThemeFlag); err != nil {
+		return nil, err
+	}
+
+	if cfg.Pad, err = optionalInt(cmd, padFlag); err != nil {
+		return nil, err
+	}
+
+	if cfg.Sketch, err = cmd.Flags().GetBool(sketchFlag.Name); err != nil {
+		return nil, err
 	}
 
-	if _, statErr := os.Stat(outputFile); statErr == nil && cmd.Flag(allowOverwriteFlag.Name).Value.String() == "false" {
-		return ErrNoOverwrite
+	if cfg.Center, err = cmd.Flags().GetBool(centerFlag.Name); err != nil {
+		return nil, err
 	}
 
-	if writeFileErr := os.WriteFile(outputFile, output, 0o644); writeFileErr != nil {
-		return writeFileErr
+	if templateDir := cmd.Flag(templateDirFlag.Name).Value.String(); templateDir != "" {
+		if cfg.Templates, err = d2.LoadTemplates(templateDir); err != nil {
+			return nil, err
+		}
 	}
 
-	logrus.Info("d2 diagram written to ", outputFile)
+	if linkTemplate := cmd.Flags().Lookup(linkTemplateFlag.Name); linkTemplate != nil && linkTemplate.Value.String() != "" {
+		if cfg.Link, err = d2.NewLinkTemplate(linkTemplate.Value.String()); err != nil {
+			return nil, err
+		}
+	}
 
-	return nil
+	return cfg, nil
 }
 
-// HasHTTPPrefix checks if the baseURI starts with either http or https
-func HasHTTPPrefix(baseURI string) bool {
-	return baseURI != "" && (strings.HasPrefix(baseURI, "http") || strings.HasPrefix(baseURI, "https"))
+// optionalInt value of the flag or nil if negative (unset)
+func optionalInt(cmd *cobra.Command, f flag) (*int64, error) {
+	i, err := cmd.Flags().GetInt(f.Name)
+	if err != nil || i < 0 {
+		return nil, err
+	}
+
+	value := int64(i)
+	return &value, nil
 }
@@ -3,6 +3,7 @@ package d2
 import (
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Emptyless/jsonschema-transform/domain"
@@ -84,8 +85,17 @@ func (c *Container) Add(class *domain.Class, parser ContainerParser) {
 	container.Classes = append(container.Classes, class)
 }
 
-// Render a Container using RenderContainer. This avoids the circular dependency problem my adding
-// the RenderContainer as a function to the ContainerTemplate
+// Sort the nested Containers (recursively) by name such that they are rendered in a canonical order
+func (c *Container) Sort() {
+	slices.SortStableFunc(c.Containers, func(a, b *Container) int { return strings.Compare(a.Name, b.Name) })
+	for _, container := range c.Containers {
+		container.Sort()
+	}
+}
+
+// Render a Container using RenderContainer.
+//
+// Deprecated: use Templates.RenderContainer.
 func (c *Container) Render() string {
 	return RenderContainer(c)
 }
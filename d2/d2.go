@@ -4,15 +4,16 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"os/exec"
 	"regexp"
 	"strings"
+	"text/template"
 
 	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
 )
 
-// ErrParserFailure is returned when there is some failure by the parser
-var ErrParserFailure = errors.New("failed to parse classes or relations")
+// ErrParserFailure is returned when there is some failure by the parser, the same error as parse.ErrParserFailure
+var ErrParserFailure = parse.ErrParserFailure
 
 // ErrNoClasses is returned when no Classes are parsed
 var ErrNoClasses = errors.New("no classes parsed")
@@ -28,15 +29,63 @@ type Config struct {
 	// Format used when outputting diagram
 	Format Format
 
-	// Tool used to render svg (if SVG Format)
+	// Renderer used to render the SVG and PNG Format, defaults to Embedded
+	Renderer Renderer
+
+	// Tool used to render svg or png (if External Renderer), if empty 'which d2' is used
 	Tool string
 
-	// Args used by the tool
+	// Args used by the tool, the Embedded Renderer falls back to the External Renderer if set
 	Args []string
 
 	// ContainerBasePath if set will wrap all domain.Class in containers based on the
 	// DirContainerParser
 	ContainerBasePath string
+
+	// Layout engine, if empty the D2 default is used
+	Layout Layout
+
+	// Direction of the diagram, if empty the D2 default is used
+	Direction Direction
+
+	// ThemeID of the D2 theme, if nil the D2 default is used
+	ThemeID *int64
+
+	// DarkThemeID of the D2 theme used when the viewer prefers a dark color scheme, if nil no dark theme is used
+	DarkThemeID *int64
+
+	// Sketch renders the diagram as if it was drawn by hand
+	Sketch bool
+
+	// Pad around the diagram in pixels, if nil the D2 default is used
+	Pad *int64
+
+	// Center the diagram in the viewbox
+	Center bool
+
+	// Templates used to render the diagram, if nil the DefaultTemplates are used
+	Templates *Templates
+
+	// Link of a domain.Class (e.g. to its schema or documentation) rendered as D2 'link', if nil classes are not linked
+	// such that the diagram does not depend on the location of the schemas (see NewLinkTemplate)
+	Link func(class *domain.Class) string
+
+	// Cache of the rendered SVG and PNG Format, if nil every diagram is rendered
+	Cache RenderCache
+}
+
+// RenderCache stores rendered diagrams by a key derived from the D2 script and the options used to render it
+type RenderCache interface {
+	// Get the rendered diagram by key, ok is false if it is not stored
+	Get(key string) (value []byte, ok bool)
+
+	// Put the rendered diagram by key
+	Put(key string, value []byte) error
+}
+
+// HasD2Config returns true iff any of the options set in the D2 'vars.d2-config' is set
+func (c *Config) HasD2Config() bool {
+	return c.Layout != "" || c.ThemeID != nil || c.DarkThemeID != nil || c.Sketch || c.Pad != nil || c.Center
 }
 
 // D2 transform Parser with Config into .d2 or .svg output
@@ -53,13 +102,8 @@ func D2(parser Parser, cfg *Config) ([]byte, error) {
 		cfg.Args = []string{}
 	}
 
-	if (cfg.Format == SVG || cfg.Format == PNG) && cfg.Tool == "" {
-		output, outputErr := exec.Command("which", "d2").Output()
-		if outputErr != nil {
-			return nil, outputErr
-		}
-
-		cfg.Tool = strings.TrimSpace(string(output))
+	if cfg.Renderer == "" {
+		cfg.Renderer = Embedded
 	}
 
 	classes, err := parser.Classes()
@@ -74,56 +118,63 @@ func D2(parser Parser, cfg *Config) ([]byte, error) {
 		return nil, errors.Join(ErrParserFailure, err)
 	}
 
-	buffer := new(bytes.Buffer)
+	templates := cfg.Templates
+	if templates == nil {
+		templates = DefaultTemplates
+	}
+
+	if cfg.Link != nil {
+		if templates, err = templates.With(template.FuncMap{"link": cfg.Link}); err != nil {
+			return nil, err
+		}
+	}
+
+	document := &Document{Config: cfg, Classes: classes}
+	cycles := parse.CycleRelations(classes, relations)
+	for _, r := range relations {
+		document.Relations = append(document.Relations, &Relation{Relation: r, From: r.From, To: r.To, Cycle: cycles[r]})
+	}
 
 	// if the ContainerBasePath is set, render containerized
 	if cfg.ContainerBasePath != "" {
 		containerParser := DirContainerParser{RootPath: cfg.ContainerBasePath}
-		container := Container{Name: ""}
+		document.Container = &Container{Name: ""}
 
 		// add classes to the container
 		for _, c := range classes {
-			container.Add(c, containerParser)
+			document.Container.Add(c, containerParser)
 		}
+		document.Container.Sort()
 
-		buffer.WriteString(container.Render())
-
-		// check if the name is only spaces
-		onlySpacesRegex := regexp.MustCompile(`^\s+$`)
-
-		// update the name such that the relations can be created
-		for _, r := range relations {
-			from := containerParser.Containers(r.From)
-			prefix := strings.Join(from, ".")
-			if !strings.HasPrefix(r.From.Name, prefix) {
-				if match := onlySpacesRegex.FindString(r.From.Name); match != "" {
-					r.From.Name = fmt.Sprintf(`"%s"`, match)
-				}
-				r.From.Name = prefix + "." + r.From.Name
-			}
-
-			to := containerParser.Containers(r.To)
-			prefix = strings.Join(to, ".")
-			if !strings.HasPrefix(r.To.Name, prefix) {
-				if match := onlySpacesRegex.FindString(r.To.Name); match != "" {
-					r.To.Name = fmt.Sprintf(`"%s"`, match)
-				}
-				r.To.Name = prefix + "." + r.To.Name
-			}
-		}
-	} else {
-		for _, c := range classes {
-			buffer.WriteString(RenderClass(c))
-			buffer.WriteString("\n\n")
+		// qualify the names by their containers such that the relations can be created
+		for _, r := range document.Relations {
+			r.From = qualify(r.From, containerParser)
+			r.To = qualify(r.To, containerParser)
 		}
 	}
 
-	for i, r := range relations {
-		buffer.WriteString(RenderRelation(r))
-		if i != len(relations)-1 {
-			buffer.WriteString("\n\n")
-		}
+	output, err := templates.RenderDocument(document)
+	if err != nil {
+		return nil, err
 	}
 
+	buffer := bytes.NewBufferString(output)
 	return cfg.Format.Render(buffer, cfg)
 }
+
+// qualify a copy of the domain.Class by prefixing its name with its containers
+func qualify(class *domain.Class, containerParser ContainerParser) *domain.Class {
+	prefix := strings.Join(containerParser.Containers(class), ".")
+	if strings.HasPrefix(class.Name, prefix) {
+		return class
+	}
+
+	qualified := *class
+	// check if the name is only spaces
+	if match := regexp.MustCompile(`^\s+$`).FindString(qualified.Name); match != "" {
+		qualified.Name = fmt.Sprintf(`"%s"`, match)
+	}
+	qualified.Name = prefix + "." + qualified.Name
+
+	return &qualified
+}
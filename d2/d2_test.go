@@ -2,16 +2,18 @@ package d2
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse/parsetest"
 	"github.com/stretchr/testify/require"
 	"github.com/test-go/testify/assert"
 )
 
 func TestD2(t *testing.T) {
 	// Arrange
-	parser := TestParser{
+	parser := parsetest.Parser{
 		ClassData: []*domain.Class{
 			{
 				Source:    domain.FileSource{FilePath: "pet.json"},
@@ -27,33 +29,245 @@ func TestD2(t *testing.T) {
 
 	// Act
 	b, err := D2(&parser, &Config{Format: SVG})
-	_ = os.WriteFile("out.svg", b, 0o644)
 
 	// Assert
 	require.NoError(t, err)
 	assert.NotEmpty(t, b)
 }
 
-type TestParser struct {
-	ClassData    []*domain.Class
-	ClassesError error
+func TestD2_UsesRenderCache(t *testing.T) {
+	// Arrange
+	parser := parsetest.Parser{ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet"}}}
+	cache := &TestRenderCache{}
+
+	// Act
+	rendered, renderErr := D2(&parser, &Config{Format: SVG, Cache: cache})
+	for key := range cache.values {
+		cache.values[key] = []byte("<svg>cached</svg>")
+	}
+	cached, cachedErr := D2(&parser, &Config{Format: SVG, Cache: cache})
+
+	// Assert
+	require.NoError(t, renderErr)
+	require.NoError(t, cachedErr)
+	assert.Contains(t, string(rendered), "Pet")
+	assert.Len(t, cache.values, 1)
+	assert.Equal(t, "<svg>cached</svg>", string(cached))
+}
+
+type TestRenderCache struct {
+	values map[string][]byte
+}
+
+func (c *TestRenderCache) Get(key string) ([]byte, bool) {
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *TestRenderCache) Put(key string, value []byte) error {
+	if c.values == nil {
+		c.values = map[string][]byte{}
+	}
+	c.values[key] = value
+	return nil
+}
+
+func TestD2_HighlightsCycles(t *testing.T) {
+	// Arrange
+	node := &domain.Class{Name: "Node", Source: domain.FileSource{FilePath: "node.json"}}
+	leaf := &domain.Class{Name: "Leaf", Source: domain.FileSource{FilePath: "leaf.json"}}
+	root := &domain.Class{Name: "Root", Source: domain.FileSource{FilePath: "root.json"}}
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{node, leaf, root},
+		RelationsData: []*domain.Relation{
+			{Type: "leaf", From: node, To: leaf},
+			{Type: "node", From: leaf, To: node},
+			{Type: "node", From: root, To: node},
+		},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: Native})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "Node -- Leaf: \"leaf\" {\n  style.stroke: red\n")
+	assert.Contains(t, string(b), "Leaf -- Node: \"node\" {\n  style.stroke: red\n")
+	assert.Contains(t, string(b), "Root -- Node: \"node\"")
+	assert.NotContains(t, string(b), "Root -- Node: \"node\" {")
+}
+
+func TestD2_ExternalRendererRemovesTempFiles(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	tool := filepath.Join(t.TempDir(), "d2")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\ncp \"$1\" \"$2\"\n"), 0o755))
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet"}},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: SVG, Renderer: External, Tool: tool})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"Pet": {`)
+	entries, err := os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestD2_EmbeddedRendererFallsBackForArgs(t *testing.T) {
+	// Arrange
+	tool := filepath.Join(t.TempDir(), "d2")
+	require.NoError(t, os.WriteFile(tool, []byte("#!/bin/sh\necho \"$3 $4\" > \"$2\"\n"), 0o755))
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet"}},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: SVG, Renderer: Embedded, Tool: tool, Args: []string{"--layout", "elk"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "--layout elk\n", string(b))
+}
+
+func TestParseRenderer(t *testing.T) {
+	// Act
+	renderer, err := ParseRenderer("external")
+	_, unknownErr := ParseRenderer("browser")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, External, renderer)
+	require.ErrorIs(t, unknownErr, ErrUnknownRenderer)
+}
+
+func TestD2_RendersConfig(t *testing.T) {
+	// Arrange
+	themeID, pad := int64(200), int64(10)
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet"}},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: Native, Layout: ELK, Direction: Right, ThemeID: &themeID, Sketch: true, Pad: &pad})
 
-	RelationsData  []*domain.Relation
-	RelationsError error
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "vars: {\n  d2-config: {\n    layout-engine: elk\n    theme-id: 200\n    sketch: true\n    pad: 10\n  }\n}\n\ndirection: right\n")
 }
 
-func (p *TestParser) Classes() ([]*domain.Class, error) {
-	if err := p.ClassesError; err != nil {
-		return nil, err
+func TestD2_EmbeddedRendererUsesConfig(t *testing.T) {
+	// Arrange
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet"}},
 	}
 
-	return p.ClassData, nil
+	// Act
+	b, err := D2(&parser, &Config{Format: SVG, Layout: ELK, Sketch: true})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "<svg")
 }
 
-func (p *TestParser) Relations() ([]*domain.Relation, error) {
-	if err := p.RelationsError; err != nil {
-		return nil, err
+func TestD2_RendersTooltipWithoutLink(t *testing.T) {
+	// Arrange
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{Source: domain.FileSource{FilePath: "pet.json"}, Name: "Pet", Docstring: "a friendly animal"}},
 	}
 
-	return p.RelationsData, nil
+	// Act
+	b, err := D2(&parser, &Config{Format: Native})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\"Pet\": {\n  shape: class\n  tooltip: \"a friendly animal\"\n")
+	assert.NotContains(t, string(b), "link:", "links are opt-in as the source depends on the machine")
+}
+
+func TestNewLinkTemplate(t *testing.T) {
+	// Arrange
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	class := &domain.Class{Source: domain.FileSource{FilePath: filepath.Join(cwd, "schemas", "pet.json")}, Name: "Pet Store"}
+	link, err := NewLinkTemplate("https://git.acme/repo/blob/main/{{ .Path }}#{{ .Slug }}")
+	require.NoError(t, err)
+	parser := parsetest.Parser{ClassData: []*domain.Class{class}}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: Native, Link: link})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `link: "https://git.acme/repo/blob/main/schemas/pet.json#pet-store"`)
+}
+
+func TestNewLinkTemplate_InvalidTemplate(t *testing.T) {
+	// Act
+	_, err := NewLinkTemplate("{{ .Path ")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidTemplate)
+}
+
+func TestD2_RendersClosedClass(t *testing.T) {
+	// Arrange
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{
+			Source:     domain.FileSource{FilePath: "inventory.json"},
+			Name:       "Inventory",
+			Properties: []*domain.Property{{Name: "items", Type: "map[^[a-z]+\\d$]Item"}},
+			Closed:     true,
+		}},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: Native})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\"Inventory\": {\n  shape: class\n  tooltip: \"(no additional properties)\"\n  \"items\": \"map[^[a-z]+\\\\d$]Item\"\n}")
+	assert.NotContains(t, string(b), "additionalProperties")
+}
+
+func TestD2_RendersClosedClassWithDocstring(t *testing.T) {
+	// Arrange
+	parser := parsetest.Parser{
+		ClassData: []*domain.Class{{
+			Source:     domain.FileSource{FilePath: "inventory.json"},
+			Name:       "Inventory",
+			Docstring:  "items in stock",
+			Properties: []*domain.Property{{Name: "additionalProperties", Type: "boolean"}},
+			Closed:     true,
+		}},
+	}
+
+	// Act
+	b, err := D2(&parser, &Config{Format: Native})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "  tooltip: \"items in stock (no additional properties)\"\n  \"additionalProperties\": \"boolean\"\n}")
+}
+
+func TestRender_DefaultTemplates(t *testing.T) {
+	// Arrange
+	pet := &domain.Class{Name: "Pet", Properties: []*domain.Property{{Name: "name", Type: "string"}}}
+	store := &domain.Class{Name: "Pet Store"}
+	container := &Container{Name: "pets", Classes: []*domain.Class{pet}}
+
+	// Act
+	class := RenderClass(pet)
+	relation := RenderRelation(&domain.Relation{Type: "store", From: pet, To: store})
+	rendered := container.Render()
+
+	// Assert
+	assert.Equal(t, "\n\"Pet\": {\n  shape: class\n  \"name\": \"string\"\n}", class)
+	assert.Equal(t, `Pet -- Pet Store: "store"`, relation)
+	assert.Contains(t, rendered, `"pets": {`)
+	assert.Contains(t, rendered, `"Pet": {`)
 }
@@ -2,13 +2,11 @@ package d2
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
-	"os"
-	"os/exec"
 	"path/filepath"
-	"strings"
 
 	"github.com/sirupsen/logrus"
 )
@@ -53,54 +51,57 @@ func (f Format) Render(buffer *bytes.Buffer, cfg *Config) ([]byte, error) {
 	case Native:
 		return buffer.Bytes(), nil
 	case SVG, PNG:
-		// create temporary diagram file
-		diagramFile, createDiagramFile := os.CreateTemp("", "diagram-*.d2")
-		if createDiagramFile != nil {
-			return nil, createDiagramFile
+		if cfg.Cache == nil {
+			return f.render(buffer, cfg)
 		}
 
-		// write the diagram code to the file
-		if _, err := diagramFile.Write(buffer.Bytes()); err != nil {
-			return nil, err
-		}
-
-		// create temporary svg file
-		svgFile, createSvgFileErr := os.CreateTemp("", "diagram-*."+string(f))
-		if createSvgFileErr != nil {
-			return nil, createSvgFileErr
-		} else if closeErr := svgFile.Close(); closeErr != nil {
-			return nil, closeErr
+		key := f.cacheKey(buffer, cfg)
+		if output, ok := cfg.Cache.Get(key); ok {
+			logrus.Debug("diagram is unchanged, using the cached render")
+			return output, nil
 		}
 
-		// create args
-		args := []string{diagramFile.Name(), svgFile.Name()}
-		if cfg.Args != nil {
-			args = append(args, cfg.Args...)
+		output, err := f.render(buffer, cfg)
+		if err != nil {
+			return nil, err
 		}
 
-		output, outputErr := exec.Command(cfg.Tool, args...).Output()
-		if err := new(exec.ExitError); errors.As(outputErr, &err) {
-			var builder strings.Builder
-			for i, line := range strings.Split(buffer.String(), "\n") {
-				builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
-			}
-			logrus.Debug("diagram.d2:\n")
-			logrus.Debug(builder.String())
-
-			return nil, errors.New(string(err.Stderr))
-		} else if outputErr != nil {
-			return nil, outputErr
+		if putErr := cfg.Cache.Put(key, output); putErr != nil {
+			logrus.Warn("could not cache the rendered diagram: ", putErr)
 		}
 
-		logrus.Debug(string(output))
+		return output, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
 
-		svgFile, openSvgFileErr := os.Open(svgFile.Name())
-		if openSvgFileErr != nil {
-			return nil, openSvgFileErr
+// render the D2 script in the buffer to the SVG or PNG Format with the Renderer of the Config
+func (f Format) render(buffer *bytes.Buffer, cfg *Config) ([]byte, error) {
+	switch f {
+	case SVG, PNG:
+		if cfg.Renderer == External {
+			return renderExternal(f, buffer, cfg)
+		} else if f == PNG {
+			logrus.Debug("embedded renderer does not support png, falling back to the external renderer")
+			return renderExternal(f, buffer, cfg)
+		} else if len(cfg.Args) > 0 {
+			logrus.Debug("embedded renderer does not support args, falling back to the external renderer")
+			return renderExternal(f, buffer, cfg)
 		}
 
-		return io.ReadAll(svgFile)
+		return renderEmbedded(buffer)
 	default:
 		return nil, ErrUnknownFormat
 	}
 }
+
+// cacheKey of the D2 script in the buffer rendered to the Format with the Renderer, Tool and Args of the Config
+func (f Format) cacheKey(buffer *bytes.Buffer, cfg *Config) string {
+	h := sha256.New()
+	for _, part := range append([]string{string(f), string(cfg.Renderer), cfg.Tool, buffer.String()}, cfg.Args...) {
+		_, _ = fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+
+	return "render-" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package d2
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownLayout is returned when the supplied layout engine is not recognized
+var ErrUnknownLayout = errors.New("unknown layout engine")
+
+// ErrUnknownDirection is returned when the supplied direction is not recognized
+var ErrUnknownDirection = errors.New("unknown direction")
+
+// Layout engine used by D2 to position the shapes
+type Layout string
+
+// Dagre layout engine (the D2 default)
+const Dagre Layout = "dagre"
+
+// ELK layout engine
+const ELK Layout = "elk"
+
+// ParseLayout from its name
+func ParseLayout(name string) (Layout, error) {
+	switch Layout(name) {
+	case Dagre, ELK:
+		return Layout(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownLayout, name)
+	}
+}
+
+// Direction in which the diagram flows
+type Direction string
+
+// Up direction
+const Up Direction = "up"
+
+// Down direction (the D2 default)
+const Down Direction = "down"
+
+// Left direction
+const Left Direction = "left"
+
+// Right direction
+const Right Direction = "right"
+
+// ParseDirection from its name
+func ParseDirection(name string) (Direction, error) {
+	switch Direction(name) {
+	case Up, Down, Left, Right:
+		return Direction(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownDirection, name)
+	}
+}
@@ -0,0 +1,91 @@
+package d2
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
+	"github.com/sirupsen/logrus"
+)
+
+// LinkData is available to a link template (see NewLinkTemplate)
+type LinkData struct {
+	// Name of the domain.Class
+	Name string
+
+	// Slug of the Name (see tmpl.Slug)
+	Slug string
+
+	// ID of the schema of the domain.Class
+	ID string
+
+	// Source of the domain.Class as parsed (e.g. a 'file://' or 'https://' URI)
+	Source string
+
+	// Path of the Source, relative to the working directory for files (e.g. 'schemas/pet.json') or the path of the
+	// URL without leading slash otherwise
+	Path string
+}
+
+// NoLink of a domain.Class, the default link (see Config.Link)
+func NoLink(*domain.Class) string {
+	return ""
+}
+
+// SourceLink of a domain.Class is the path of its domain.Source
+func SourceLink(class *domain.Class) string {
+	if class == nil || class.Source == nil {
+		return ""
+	}
+
+	return class.Path()
+}
+
+// NewLinkTemplate returns a link function (see Config.Link) executing the source template with the LinkData of a
+// domain.Class, e.g. 'https://git.acme/repo/blob/main/{{ .Path }}'
+func NewLinkTemplate(source string) (func(class *domain.Class) string, error) {
+	tpl, err := template.New("link").Funcs(tmpl.Funcs()).Parse(source)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidTemplate, err)
+	}
+
+	return func(class *domain.Class) string {
+		var builder strings.Builder
+		if executeErr := tpl.Execute(&builder, NewLinkData(class)); executeErr != nil {
+			logrus.Warnf("could not render link of class %s: %s", class.Name, executeErr)
+			return ""
+		}
+
+		return builder.String()
+	}, nil
+}
+
+// NewLinkData of a domain.Class
+func NewLinkData(class *domain.Class) *LinkData {
+	data := &LinkData{Name: class.Name, Slug: tmpl.Slug(class.Name), Source: SourceLink(class)}
+	if class.Schema != nil {
+		data.ID = class.Schema.GetSchemaURI()
+	}
+
+	u, err := url.Parse(data.Source)
+	switch {
+	case err != nil:
+		data.Path = data.Source
+	case u.Scheme == "file" || u.Scheme == "":
+		data.Path = filepath.Clean(u.Path)
+		if cwd, cwdErr := os.Getwd(); cwdErr == nil {
+			if rel, relErr := filepath.Rel(cwd, data.Path); relErr == nil && !strings.HasPrefix(rel, "..") {
+				data.Path = filepath.ToSlash(rel)
+			}
+		}
+	default:
+		data.Path = strings.TrimPrefix(u.Path, "/")
+	}
+
+	return data
+}
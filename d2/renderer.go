@@ -0,0 +1,144 @@
+package d2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"oss.terrastruct.com/d2/d2graph"
+	"oss.terrastruct.com/d2/d2layouts/d2dagrelayout"
+	"oss.terrastruct.com/d2/d2layouts/d2elklayout"
+	"oss.terrastruct.com/d2/d2lib"
+	"oss.terrastruct.com/d2/d2renderers/d2svg"
+	"oss.terrastruct.com/d2/lib/log"
+	"oss.terrastruct.com/d2/lib/textmeasure"
+)
+
+// ErrUnknownRenderer is returned when the supplied renderer is not recognized
+var ErrUnknownRenderer = errors.New("unknown renderer")
+
+// Renderer used to render the SVG and PNG Format
+type Renderer string
+
+// Embedded renderer uses the D2 library in-process (SVG only, PNG falls back to the External renderer)
+const Embedded Renderer = "embedded"
+
+// External renderer executes the d2 binary (see Config.Tool)
+const External Renderer = "external"
+
+// ParseRenderer from its name
+func ParseRenderer(name string) (Renderer, error) {
+	switch Renderer(name) {
+	case Embedded, External:
+		return Renderer(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownRenderer, name)
+	}
+}
+
+// renderEmbedded the D2 script in the buffer to an SVG using the D2 library
+func renderEmbedded(buffer *bytes.Buffer) ([]byte, error) {
+	ruler, err := textmeasure.NewRuler()
+	if err != nil {
+		return nil, err
+	}
+
+	// route the logs of the D2 library through logrus
+	writer := logrus.StandardLogger().WriterLevel(logrus.DebugLevel)
+	defer func() { _ = writer.Close() }()
+	ctx := log.With(context.Background(), slog.New(slog.NewTextHandler(writer, nil)))
+
+	renderOpts := &d2svg.RenderOpts{}
+	diagram, _, err := d2lib.Compile(ctx, buffer.String(), &d2lib.CompileOptions{
+		Ruler: ruler,
+		LayoutResolver: func(engine string) (d2graph.LayoutGraph, error) {
+			switch Layout(engine) {
+			case ELK:
+				return d2elklayout.DefaultLayout, nil
+			case Dagre:
+				return d2dagrelayout.DefaultLayout, nil
+			default:
+				return nil, fmt.Errorf("%w: %s", ErrUnknownLayout, engine)
+			}
+		},
+	}, renderOpts)
+	if err != nil {
+		logDiagram(buffer)
+		return nil, err
+	}
+
+	return d2svg.Render(diagram, renderOpts)
+}
+
+// renderExternal the D2 script in the buffer to the Format by executing the Config.Tool
+func renderExternal(f Format, buffer *bytes.Buffer, cfg *Config) ([]byte, error) {
+	tool := cfg.Tool
+	if tool == "" {
+		output, outputErr := exec.Command("which", "d2").Output()
+		if outputErr != nil {
+			return nil, outputErr
+		}
+
+		tool = strings.TrimSpace(string(output))
+	}
+
+	// create temporary diagram file
+	diagramFile, createDiagramFileErr := os.CreateTemp("", "diagram-*.d2")
+	if createDiagramFileErr != nil {
+		return nil, createDiagramFileErr
+	}
+	defer func() { _ = os.Remove(diagramFile.Name()) }()
+
+	// write the diagram code to the file
+	if _, err := diagramFile.Write(buffer.Bytes()); err != nil {
+		_ = diagramFile.Close()
+		return nil, err
+	} else if closeErr := diagramFile.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	// create temporary output file
+	outputFile, createOutputFileErr := os.CreateTemp("", "diagram-*."+string(f))
+	if createOutputFileErr != nil {
+		return nil, createOutputFileErr
+	}
+	defer func() { _ = os.Remove(outputFile.Name()) }()
+
+	if closeErr := outputFile.Close(); closeErr != nil {
+		return nil, closeErr
+	}
+
+	// create args
+	args := []string{diagramFile.Name(), outputFile.Name()}
+	if cfg.Args != nil {
+		args = append(args, cfg.Args...)
+	}
+
+	output, outputErr := exec.Command(tool, args...).Output()
+	if err := new(exec.ExitError); errors.As(outputErr, &err) {
+		logDiagram(buffer)
+		return nil, errors.New(string(err.Stderr))
+	} else if outputErr != nil {
+		return nil, outputErr
+	}
+
+	logrus.Debug(string(output))
+
+	return os.ReadFile(outputFile.Name())
+}
+
+// logDiagram with line numbers on debug level to find the line referenced by a D2 error
+func logDiagram(buffer *bytes.Buffer) {
+	var builder strings.Builder
+	for i, line := range strings.Split(buffer.String(), "\n") {
+		builder.WriteString(fmt.Sprintf("%d. %s\n", i+1, line))
+	}
+	logrus.Debug("diagram.d2:\n")
+	logrus.Debug(builder.String())
+}
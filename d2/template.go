@@ -1,29 +1,55 @@
 package d2
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"text/template"
 
 	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
 )
 
-var ClassTemplate = NewTemplate("Class", `
+// ErrInvalidTemplate is returned when a user supplied template cannot be parsed or executed
+var ErrInvalidTemplate = errors.New("invalid template")
+
+// ClassTemplateSource used to render a domain.Class
+const ClassTemplateSource = `
 "{{- $.Name }}": {
   shape: class
+{{- with link $ }}
+  link: {{ quote . }}
+{{- end }}
+{{- $tooltip := $.Docstring }}
+{{- if $.Closed }}
+{{- $tooltip = print $tooltip (and $tooltip " ") "(no additional properties)" }}
+{{- end }}
+{{- with $tooltip }}
+  tooltip: {{ quote . }}
+{{- end }}
 {{- range $property := $.Properties }}
-  "{{ $property.Name }}": "{{ $property.Type }}"
+  {{ quote $property.Name }}: {{ quote $property.Type }}
 {{- end }}
-}`)
+}`
 
-var RelationTemplate = NewTemplate("Relation", `
-{{- $.From.Name }} -- {{ $.To.Name }}: "{{ $.Type | safe }}"`)
+// RelationTemplateSource used to render a Relation
+const RelationTemplateSource = `
+{{- $.From.Name }} -- {{ $.To.Name }}: {{ $.Type | quote | safe }}
+{{- if $.Cycle }} {
+  style.stroke: red
+  style.stroke-width: 3
+}
+{{- end }}`
 
-var ContainerTemplate = NewTemplate("Container", `
+// ContainerTemplateSource used to render a Container
+const ContainerTemplateSource = `
 {{- if $.Name -}}
 "{{ $.Name }}": {
 {{- range $container := $.Containers -}}
-{{ $container.Render | indent 4 }}
+{{ container $container | indent 4 }}
 
 {{ end -}}
 {{- range $class := $.Classes }}
@@ -32,74 +58,324 @@ var ContainerTemplate = NewTemplate("Container", `
 }
 {{- else -}}
 {{- range $container := $.Containers -}}
-{{ $container.Render }}
+{{ container $container }}
 
 {{ end -}}
 {{- range $class := $.Classes }}
 {{ class $class }}
 {{ end -}}
-{{- end }}`, template.FuncMap{
-	"class": RenderClass,
-	"indent": func(amount int, input string) string {
-		lines := strings.Split(input, "\n")
-		var builder strings.Builder
-		for i, line := range lines {
-			builder.WriteString(strings.Repeat(" ", amount) + line)
-			if i != len(lines)-1 {
-				builder.WriteString("\n")
-			}
+{{- end }}`
+
+// ConfigTemplateSource used to render the Config (D2 'vars.d2-config' and direction)
+const ConfigTemplateSource = `
+{{- if $.HasD2Config -}}
+vars: {
+  d2-config: {
+{{- with $.Layout }}
+    layout-engine: {{ . }}
+{{- end }}
+{{- with $.ThemeID }}
+    theme-id: {{ . }}
+{{- end }}
+{{- with $.DarkThemeID }}
+    dark-theme-id: {{ . }}
+{{- end }}
+{{- if $.Sketch }}
+    sketch: true
+{{- end }}
+{{- with $.Pad }}
+    pad: {{ . }}
+{{- end }}
+{{- if $.Center }}
+    center: true
+{{- end }}
+  }
+}
+{{ end -}}
+{{- with $.Direction }}
+direction: {{ . }}
+{{ end -}}`
+
+// DocumentTemplateSource used to render the Document
+const DocumentTemplateSource = `
+{{- with config $.Config }}{{ . }}
+{{ end }}
+{{- if $.Container }}{{ container $.Container }}{{ else }}{{ range $class := $.Classes }}{{ class $class }}
+
+{{ end }}{{ end }}
+{{- range $i, $relation := $.Relations }}{{ if $i }}
+
+{{ end }}{{ relation $relation }}{{ end }}`
+
+// TemplateFiles that can be overridden in a template directory (see LoadTemplates) by their template source
+var TemplateFiles = map[string]string{
+	"class.tmpl":     ClassTemplateSource,
+	"relation.tmpl":  RelationTemplateSource,
+	"container.tmpl": ContainerTemplateSource,
+	"config.tmpl":    ConfigTemplateSource,
+	"document.tmpl":  DocumentTemplateSource,
+}
+
+// Templates used to render a D2 diagram. Every template can call the other templates with the 'class', 'relation',
+// 'container' and 'config' functions in addition to the helper library (see tmpl.Funcs), 'safe' and 'link' (see
+// Config.Link)
+type Templates struct {
+	Class     *template.Template
+	Relation  *template.Template
+	Container *template.Template
+	Config    *template.Template
+	Document  *template.Template
+}
+
+// DefaultTemplates used when no Templates are configured
+var DefaultTemplates = MustNewTemplates(nil)
+
+// ClassTemplate of the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.Class. Assigning another template has no effect on the rendering, set
+// Config.Templates instead.
+var ClassTemplate = DefaultTemplates.Class
+
+// RelationTemplate of the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.Relation. Assigning another template has no effect on the rendering, set
+// Config.Templates instead.
+var RelationTemplate = DefaultTemplates.Relation
+
+// ContainerTemplate of the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.Container. Assigning another template has no effect on the rendering, set
+// Config.Templates instead.
+var ContainerTemplate = DefaultTemplates.Container
+
+// ConfigTemplate of the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.Config. Assigning another template has no effect on the rendering, set
+// Config.Templates instead.
+var ConfigTemplate = DefaultTemplates.Config
+
+// DocumentTemplate of the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.Document. Assigning another template has no effect on the rendering, set
+// Config.Templates instead.
+var DocumentTemplate = DefaultTemplates.Document
+
+// NewTemplates from sources keyed by their file name (see TemplateFiles), missing sources use the default. The funcs
+// are made available to every template in addition to the default functions
+func NewTemplates(sources map[string]string, funcs ...template.FuncMap) (*Templates, error) {
+	t := &Templates{}
+	templateFuncs := t.funcs()
+	templateFuncs["link"] = NoLink
+
+	for name, target := range map[string]**template.Template{
+		"class.tmpl":     &t.Class,
+		"relation.tmpl":  &t.Relation,
+		"container.tmpl": &t.Container,
+		"config.tmpl":    &t.Config,
+		"document.tmpl":  &t.Document,
+	} {
+		source, ok := sources[name]
+		if !ok {
+			source = TemplateFiles[name]
 		}
 
-		return builder.String()
-	},
-})
+		tpl := template.New(name).Funcs(baseFuncs()).Funcs(templateFuncs)
+		for _, fn := range funcs {
+			tpl.Funcs(fn)
+		}
 
-// RenderContainer to string output
-func RenderContainer(container *Container) string {
-	var builder strings.Builder
-	if err := ContainerTemplate.Execute(&builder, container); err != nil {
-		panic(err)
+		tpl, err := tpl.Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, name, err)
+		}
+		*target = tpl
 	}
 
-	return builder.String()
+	return t, nil
 }
 
-// RenderClass to string output
-func RenderClass(class *domain.Class) string {
-	var builder strings.Builder
-	if err := ClassTemplate.Execute(&builder, class); err != nil {
-		panic(err)
+// With returns a copy of the Templates where the funcs replace the functions with the same name
+func (t *Templates) With(funcs template.FuncMap) (*Templates, error) {
+	res := &Templates{}
+	for source, target := range map[*template.Template]**template.Template{
+		t.Class:     &res.Class,
+		t.Relation:  &res.Relation,
+		t.Container: &res.Container,
+		t.Config:    &res.Config,
+		t.Document:  &res.Document,
+	} {
+		tpl, err := source.Clone()
+		if err != nil {
+			return nil, err
+		}
+		*target = tpl.Funcs(res.funcs()).Funcs(funcs)
 	}
 
-	return builder.String()
+	return res, nil
 }
 
-// RenderRelation to string output
-func RenderRelation(relation *domain.Relation) string {
-	var builder strings.Builder
-	if err := RelationTemplate.Execute(&builder, relation); err != nil {
+// funcs rendering the Templates from within a template
+func (t *Templates) funcs() template.FuncMap {
+	return template.FuncMap{
+		"class":     t.RenderClass,
+		"relation":  t.RenderRelation,
+		"container": t.RenderContainer,
+		"config":    t.RenderConfig,
+	}
+}
+
+// MustNewTemplates is like NewTemplates but panics if a template cannot be parsed
+func MustNewTemplates(sources map[string]string, funcs ...template.FuncMap) *Templates {
+	t, err := NewTemplates(sources, funcs...)
+	if err != nil {
 		panic(err)
 	}
 
-	return builder.String()
+	return t
 }
 
-// NewTemplate from name, input
+// LoadTemplates from a directory containing overrides for (some of) the TemplateFiles, see NewTemplates for funcs
+func LoadTemplates(dir string, funcs ...template.FuncMap) (*Templates, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := map[string]string{}
+	for _, entry := range entries {
+		if _, ok := TemplateFiles[entry.Name()]; !ok || entry.IsDir() {
+			continue
+		}
+
+		b, readFileErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if readFileErr != nil {
+			return nil, readFileErr
+		}
+		sources[entry.Name()] = string(b)
+	}
+
+	return NewTemplates(sources, funcs...)
+}
+
+// RenderClass to string output
+func (t *Templates) RenderClass(class *domain.Class) (string, error) {
+	return execute(t.Class, class)
+}
+
+// RenderRelation to string output
+func (t *Templates) RenderRelation(relation *Relation) (string, error) {
+	return execute(t.Relation, relation)
+}
+
+// RenderContainer to string output
+func (t *Templates) RenderContainer(container *Container) (string, error) {
+	return execute(t.Container, container)
+}
+
+// RenderConfig to string output
+func (t *Templates) RenderConfig(cfg *Config) (string, error) {
+	return execute(t.Config, cfg)
+}
+
+// RenderDocument to string output
+func (t *Templates) RenderDocument(document *Document) (string, error) {
+	return execute(t.Document, document)
+}
+
+// Document rendered by the DocumentTemplate
+type Document struct {
+	// Config of the diagram
+	Config *Config
+
+	// Classes in the diagram
+	Classes []*domain.Class
+
+	// Relations between the Classes
+	Relations []*Relation
+
+	// Container with all Classes if rendered containerized (see Config.ContainerBasePath), otherwise nil
+	Container *Container
+}
+
+// RenderContainer to string output using the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.RenderContainer which returns an error instead of panicking.
+func RenderContainer(container *Container) string {
+	return must(DefaultTemplates.RenderContainer(container))
+}
+
+// RenderClass to string output using the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.RenderClass which returns an error instead of panicking.
+func RenderClass(class *domain.Class) string {
+	return must(DefaultTemplates.RenderClass(class))
+}
+
+// Relation rendered by the RelationTemplate
+type Relation struct {
+	*domain.Relation
+
+	// From part of the Relation with its name qualified by its containers (if rendered containerized)
+	From *domain.Class
+
+	// To part of the Relation with its name qualified by its containers (if rendered containerized)
+	To *domain.Class
+
+	// Cycle is true iff the domain.Relation is part of a cycle (see parse.Cycles), which is highlighted
+	Cycle bool
+}
+
+// RenderRelation to string output using the DefaultTemplates.
+//
+// Deprecated: use DefaultTemplates.RenderRelation which also highlights cycles and returns an error instead of
+// panicking.
+func RenderRelation(relation *domain.Relation) string {
+	return must(DefaultTemplates.RenderRelation(&Relation{Relation: relation, From: relation.From, To: relation.To}))
+}
+
+// NewTemplate from name, input with the helper library (see tmpl.Funcs) and 'safe' available.
+//
+// Deprecated: use NewTemplates which also makes the other templates available.
 func NewTemplate(name, input string, funcs ...template.FuncMap) *template.Template {
 	tpl := template.New(name)
-	tpl.Funcs(template.FuncMap{
-		"safe": func(inp string) string {
-			re := regexp.MustCompile(`\$`)
-			for _, match := range re.FindAllString(inp, -1) {
-				inp = strings.Replace(inp, match, `\$`, 1)
-			}
-
-			return inp
-		},
-	})
+	tpl.Funcs(baseFuncs())
 	for _, fn := range funcs {
 		tpl.Funcs(fn)
 	}
 
 	return template.Must(tpl.Parse(input))
 }
+
+// baseFuncs available to every template: the helper library and 'safe' to escape D2 variables
+func baseFuncs() template.FuncMap {
+	funcs := tmpl.Funcs()
+	funcs["safe"] = func(inp string) string {
+		re := regexp.MustCompile(`\$`)
+		for _, match := range re.FindAllString(inp, -1) {
+			inp = strings.Replace(inp, match, `\$`, 1)
+		}
+
+		return inp
+	}
+
+	return funcs
+}
+
+// execute the template with data to string output
+func execute(tpl *template.Template, data any) (string, error) {
+	var builder strings.Builder
+	if err := tpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	return builder.String(), nil
+}
+
+// must returns s or panics on err, used to render the DefaultTemplates which are known to be valid
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
@@ -2,16 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/Emptyless/jsonschema-transform/d2"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestD2_CreatesD2File(t *testing.T) {
 	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram.d2")
 	outputBuffer := new(bytes.Buffer)
 	rootCmd.SetOut(outputBuffer)
-	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--overwrite", "--output", "diagram.d2"}
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--output", outputFile}
 	rootCmd.SetArgs(args)
 
 	// Act
@@ -19,13 +27,15 @@ func TestD2_CreatesD2File(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
+	assert.FileExists(t, outputFile)
 }
 
 func TestD2_CreatesSvg(t *testing.T) {
 	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram.svg")
 	outputBuffer := new(bytes.Buffer)
 	rootCmd.SetOut(outputBuffer)
-	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--overwrite", "--output", "diagram.svg"}
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--output", outputFile}
 	rootCmd.SetArgs(args)
 
 	// Act
@@ -33,13 +43,15 @@ func TestD2_CreatesSvg(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
+	assert.FileExists(t, outputFile)
 }
 
 func TestD2_CreatesContainerizedD2(t *testing.T) {
 	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram_with_containers.d2")
 	outputBuffer := new(bytes.Buffer)
 	rootCmd.SetOut(outputBuffer)
-	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--container-base-path", ".", "--overwrite", "--output", "diagram_with_containers.d2"}
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--container-base-path", ".", "--output", outputFile}
 	rootCmd.SetArgs(args)
 
 	// Act
@@ -47,13 +59,15 @@ func TestD2_CreatesContainerizedD2(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
+	assert.FileExists(t, outputFile)
 }
 
 func TestD2_CreatesContainerizedSvg(t *testing.T) {
 	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram_with_containers.svg")
 	outputBuffer := new(bytes.Buffer)
 	rootCmd.SetOut(outputBuffer)
-	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--container-base-path", ".", "--overwrite", "--output", "diagram_with_containers.svg"}
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--container-base-path", ".", "--output", outputFile}
 	rootCmd.SetArgs(args)
 
 	// Act
@@ -61,4 +75,139 @@ func TestD2_CreatesContainerizedSvg(t *testing.T) {
 
 	// Assert
 	require.NoError(t, err)
+	assert.FileExists(t, outputFile)
+}
+
+func TestD2_UnknownLayout(t *testing.T) {
+	// Arrange
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "-o", filepath.Join(t.TempDir(), "diagram.d2"), "--layout", "circular"}
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() { _ = d2Cmd.Flags().Set(layoutFlag.Name, "") })
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, d2.ErrUnknownLayout)
+}
+
+func TestD2_TemplateDir(t *testing.T) {
+	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram.d2")
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "-o", outputFile, "--container-base-path", "", "--template-dir", "./testdata/templates"}
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() { _ = d2Cmd.Flags().Set(templateDirFlag.Name, "") })
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\"Pet\": {\n  shape: class\n  tooltip: \"a friendly animal\"\n")
+	assert.Contains(t, string(b), `Pet -- Pet Store: "\$ref"`)
+}
+
+func TestD2_LinkTemplate(t *testing.T) {
+	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram.d2")
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "-o", outputFile, "--container-base-path", "", "--link-template", "https://git.acme/repo/blob/main/{{ .Path }}"}
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() { _ = d2Cmd.Flags().Set(linkTemplateFlag.Name, "") })
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "\"Pet\": {\n  shape: class\n  link: \"https://git.acme/repo/blob/main/testdata/pet.json\"\n  tooltip: \"a friendly animal\"\n")
+}
+
+func TestD2_WatchRegeneratesOnChange(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "pet.json")
+	outputFile := filepath.Join(dir, "diagram.d2")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"$id": "pet.json", "title": "Pet", "type": "object", "properties": {"name": {"type": "string"}}}`), 0o644))
+	args := []string{d2Cmd.Use, "--globs", filepath.Join(dir, "*.json"), "--base-uri", dir, "-o", outputFile, "--container-base-path", "", "--watch"}
+	resetFlags(d2Cmd)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		resetFlags(d2Cmd)
+		d2Cmd.SetContext(context.Background())
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	d2Cmd.SetContext(ctx) // cobra only passes the context of the rootCmd to commands without one
+	done := make(chan error)
+	go func() { done <- rootCmd.Execute() }()
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(outputFile)
+		return strings.Contains(string(b), `"name": "string"`)
+	}, 5*time.Second, 20*time.Millisecond)
+
+	// Act
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"$id": "pet.json", "title": "Pet", "type": "object", "properties": {"nickname": {"type": "string"}}}`), 0o644))
+
+	// Assert
+	require.Eventually(t, func() bool {
+		b, _ := os.ReadFile(outputFile)
+		return strings.Contains(string(b), `"nickname": "string"`)
+	}, 5*time.Second, 20*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, "false", d2Cmd.Flag(allowOverwriteFlag.Name).Value.String(), "watching does not change the flag")
+}
+
+func TestD2_CacheDir(t *testing.T) {
+	// Arrange
+	cacheDir := t.TempDir()
+	outputFile := filepath.Join(t.TempDir(), "diagram.svg")
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "-o", outputFile, "--container-base-path", "", "--overwrite", "--cache-dir", cacheDir}
+	t.Cleanup(func() { resetFlags(d2Cmd) })
+
+	// Act
+	resetFlags(d2Cmd)
+	rootCmd.SetArgs(args)
+	firstErr := rootCmd.Execute()
+	first, _ := os.ReadFile(outputFile)
+	resetFlags(d2Cmd)
+	rootCmd.SetArgs(args)
+	secondErr := rootCmd.Execute()
+	second, _ := os.ReadFile(outputFile)
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, first, second)
+	documents, _ := filepath.Glob(filepath.Join(cacheDir, "document-*"))
+	renders, _ := filepath.Glob(filepath.Join(cacheDir, "render-*"))
+	assert.Len(t, documents, 2, "pet.json and store.json")
+	assert.Len(t, renders, 1)
+}
+
+func TestD2_DeterministicOutput(t *testing.T) {
+	// Arrange
+	outputFile := filepath.Join(t.TempDir(), "diagram.d2")
+	args := []string{d2Cmd.Use, "--globs", "./testdata/*.json", "--globs", "./testdata/cycles/*.json", "--base-uri", "./", "-o", outputFile, "--container-base-path", ".", "--depth", "1", "--overwrite"}
+	t.Cleanup(func() { resetFlags(d2Cmd) })
+
+	// Act
+	var outputs [][]byte
+	for range 10 {
+		resetFlags(d2Cmd)
+		rootCmd.SetArgs(args)
+		require.NoError(t, rootCmd.Execute())
+
+		output, err := os.ReadFile(outputFile)
+		require.NoError(t, err)
+		outputs = append(outputs, output)
+	}
+
+	// Assert
+	for _, output := range outputs[1:] {
+		assert.Equal(t, string(outputs[0]), string(output))
+	}
 }
@@ -14,6 +14,10 @@ type Class struct {
 	// Docstring of the Class
 	Docstring string
 
-	// Properties of the Class
+	// Properties of the Class, including the properties named '[string]' for 'additionalProperties' and '[pattern]'
+	// for 'patternProperties'
 	Properties []*Property
+
+	// Closed is true iff the Class does not allow properties other than its Properties ('additionalProperties: false')
+	Closed bool
 }
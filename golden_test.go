@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	goflag "flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/Emptyless/jsonschema-transform/bundle"
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/site"
+	"github.com/Emptyless/jsonschema-transform/stats"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
+	"github.com/Emptyless/jsonschema-transform/transform"
+	"github.com/Emptyless/jsonschema-transform/view"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update the golden files instead of comparing them, e.g. 'go test . -run TestGolden -update'
+var update = goflag.Bool("update", false, "update the golden files of the corpus")
+
+// corpus of schemas in testdata where every directory is a case with the golden files of its outputs
+const corpus = "testdata/corpus"
+
+// goldenTemplate rendered against the view.Model of every case
+const goldenTemplate = "testdata/templates/view/summary.tmpl"
+
+// golden outputs of a case by the name of their golden file, every registered transform.Transformer, the bundle and
+// the outputs of the stats, impact, template and html commands. An error is part of the output such that unsupported
+// schemas are covered as well
+func golden(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+
+	res := map[string][]byte{}
+	for _, name := range transform.DefaultRegistry.Names() {
+		transformer, err := transform.Lookup(name)
+		require.NoError(t, err)
+
+		res[name+".golden"] = output(transformer.Transform(newCorpusParser(t, dir)))
+	}
+
+	res["bundle.golden"] = output(bundleCorpus(newCorpusParser(t, dir)))
+	res["stats.golden"] = output(statsCorpus(newCorpusParser(t, dir)))
+	res["impact.golden"] = output(impactCorpus(newCorpusParser(t, dir)))
+	res["template.golden"] = output(templateCorpus(newCorpusParser(t, dir)))
+	res["html.golden"] = output(htmlCorpus(newCorpusParser(t, dir)))
+
+	return res
+}
+
+// bundleCorpus into an indented compound document
+func bundleCorpus(parser *parse.Parser) ([]byte, error) {
+	roots, err := parser.Schemas()
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := bundle.Bundle(parser.Compiler, roots, &bundle.Config{ID: "file:///bundle.json"})
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(document); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// statsCorpus as the table of the stats command
+func statsCorpus(parser *parse.Parser) ([]byte, error) {
+	report, err := stats.Compute(parser, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err = stats.Table.Write(&buffer, report); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// impactCorpus lists the dependents of every class like the impact command
+func impactCorpus(parser *parse.Parser) ([]byte, error) {
+	classes, err := parser.Classes()
+	if err != nil {
+		return nil, err
+	}
+
+	relations, err := parser.Relations()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	for _, class := range classes {
+		writeDependents(&buffer, []*domain.Class{class}, parse.Dependents(classes, relations, class))
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// templateCorpus renders the goldenTemplate against the view.Model like the template command
+func templateCorpus(parser *parse.Parser) ([]byte, error) {
+	tpl, err := template.New(filepath.Base(goldenTemplate)).Funcs(tmpl.Funcs()).ParseFiles(goldenTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	model, err := view.New(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err = tpl.Execute(&buffer, model); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// htmlCorpus concatenates the pages and the search index of the site of the html command, without the diagram (which
+// is covered by the d2 output) and the static assets
+func htmlCorpus(parser *parse.Parser) ([]byte, error) {
+	model, err := view.New(parser)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := site.New(model, &site.Config{Title: "Corpus"})
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	for _, name := range slices.Sorted(func(yield func(string) bool) {
+		for name := range files {
+			if (!strings.HasPrefix(name, "assets/") || name == "assets/search-index.js") && !yield(name) {
+				return
+			}
+		}
+	}) {
+		buffer.WriteString("==> " + name + " <==\n")
+		buffer.Write(files[name])
+		if !bytes.HasSuffix(files[name], []byte("\n")) {
+			buffer.WriteByte('\n')
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// output or the error if not nil
+func output(b []byte, err error) []byte {
+	if err != nil {
+		return []byte("error: " + err.Error() + "\n")
+	}
+
+	return b
+}
+
+// newCorpusParser reading the schemas of the case from its directory
+func newCorpusParser(t *testing.T, dir string) *parse.Parser {
+	t.Helper()
+
+	parser, err := transform.NewParser(transform.Options{Globs: []string{"*.json", "*/*.json"}, FS: os.DirFS(dir), StrictMode: true})
+	require.NoError(t, err)
+
+	return parser
+}
+
+func TestGolden(t *testing.T) {
+	cases, err := os.ReadDir(corpus)
+	require.NoError(t, err)
+
+	for _, c := range cases {
+		if !c.IsDir() {
+			continue
+		}
+
+		t.Run(c.Name(), func(t *testing.T) {
+			dir := filepath.Join(corpus, c.Name())
+			for name, actual := range golden(t, dir) {
+				file := filepath.Join(dir, name)
+				if *update {
+					require.NoError(t, os.WriteFile(file, actual, 0o644))
+					continue
+				}
+
+				expected, readErr := os.ReadFile(file)
+				require.NoError(t, readErr, "golden file is missing, run the test with -update to create it")
+				assert.Equal(t, string(expected), string(actual), "output differs from %s, run the test with -update if the change is intended", file)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Emptyless/jsonschema-transform/site"
+	"github.com/Emptyless/jsonschema-transform/view"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// htmlCmd registered to the rootCmd
+var htmlCmd = &cobra.Command{
+	Use:          "html",
+	Short:        "generate a static html documentation site from the json schemas",
+	Long:         "generate a static html documentation site with a page per class, a search index and the diagram with classes linked to their pages which works offline",
+	Example:      fmt.Sprintf("%s html --globs 'schemas/*.json' -o site", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleHTML,
+}
+
+// init the htmlCmd command
+func init() {
+	rootCmd.AddCommand(htmlCmd)
+	globsFlag.Apply(htmlCmd.Flags())
+	excludeFlag.Apply(htmlCmd.Flags())
+	jobsFlag.Apply(htmlCmd.Flags())
+	baseURIFlag.Apply(htmlCmd.Flags())
+	siteOutputFlag.Apply(htmlCmd.Flags())
+	titleFlag.Apply(htmlCmd.Flags())
+	allowOverwriteFlag.Apply(htmlCmd.Flags())
+	watchFlag.Apply(htmlCmd.Flags())
+	cacheDirFlag.Apply(htmlCmd.Flags())
+	cacheMaxAgeFlag.Apply(htmlCmd.Flags())
+	for _, f := range d2ConfigFlags {
+		f.Apply(htmlCmd.Flags())
+	}
+}
+
+// handleHTML for the htmlCmd command
+func handleHTML(cmd *cobra.Command, _ []string) error {
+	outputDir := cmd.Flag(siteOutputFlag.Name).Value.String()
+	if entries, readDirErr := os.ReadDir(outputDir); readDirErr == nil && len(entries) > 0 && cmd.Flag(allowOverwriteFlag.Name).Value.String() == "false" {
+		return ErrNoOverwrite
+	}
+
+	diagram, err := newD2Config(cmd, "diagram.svg")
+	if err != nil {
+		return err
+	}
+
+	// the site is built from the compiled schemas (see view.New), hence only its diagram is cached
+	dir, err := openCache(cmd)
+	if err != nil {
+		return err
+	}
+	if dir != nil {
+		diagram.Cache = dir
+	}
+
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	return runOrWatch(cmd, parser, func(bool) error {
+		model, modelErr := view.New(parser)
+		if modelErr != nil {
+			return modelErr
+		}
+
+		s, siteErr := site.New(model, &site.Config{
+			Title:   cmd.Flag(titleFlag.Name).Value.String(),
+			Diagram: diagram,
+		})
+		if siteErr != nil {
+			return siteErr
+		}
+
+		if writeErr := s.Write(outputDir); writeErr != nil {
+			return writeErr
+		}
+
+		logrus.Info("site written to ", outputDir)
+
+		return nil
+	})
+}
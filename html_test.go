@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTML_WritesSite(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	args := []string{htmlCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "-o", outputDir, "--overwrite"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	for _, file := range []string{"index.html", "classes/pet.html", "classes/pet-store.html", "assets/search-index.js"} {
+		assert.FileExists(t, filepath.Join(outputDir, file))
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `href="./classes/pet.html"`, "diagram links to the class pages")
+	assert.Empty(t, regexp.MustCompile(`(src|href)="(https?:)?//`).FindAllString(string(index), -1), "no remote assets")
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/d2"
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// impactCmd registered to the rootCmd
+var impactCmd = &cobra.Command{
+	Use:          "impact <$id|file|title>",
+	Short:        "list the classes that (transitively) reference a json schema",
+	Long:         "list every class that (transitively) references the json schema identified by its $id, file path or title together with the path of relations towards it",
+	Example:      fmt.Sprintf("%s impact --globs 'schemas/*.json' schemas/pet.json -o impact.svg", rootCmd.Use),
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE:         handleImpact,
+}
+
+// init the impactCmd command
+func init() {
+	rootCmd.AddCommand(impactCmd)
+	globsFlag.Apply(impactCmd.Flags())
+	excludeFlag.Apply(impactCmd.Flags())
+	jobsFlag.Apply(impactCmd.Flags())
+	baseURIFlag.Apply(impactCmd.Flags())
+	impactOutputFlag.Apply(impactCmd.Flags())
+	allowOverwriteFlag.Apply(impactCmd.Flags())
+	for _, f := range d2ConfigFlags {
+		f.Apply(impactCmd.Flags())
+	}
+	linkTemplateFlag.Apply(impactCmd.Flags())
+	cacheDirFlag.Apply(impactCmd.Flags())
+	cacheMaxAgeFlag.Apply(impactCmd.Flags())
+}
+
+// handleImpact for the impactCmd command
+func handleImpact(cmd *cobra.Command, args []string) error {
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	dir, err := openCache(cmd)
+	if err != nil {
+		return err
+	}
+
+	source := cachedParser(parser, dir)
+	classes, err := source.Classes()
+	if err != nil {
+		return err
+	}
+
+	relations, err := source.Relations()
+	if err != nil {
+		return err
+	}
+
+	targets, err := parse.FindClasses(classes, args[0])
+	if err != nil {
+		return err
+	}
+
+	writeDependents(cmd.OutOrStdout(), targets, parse.Dependents(classes, relations, targets...))
+
+	outputFile := cmd.Flag(impactOutputFlag.Name).Value.String()
+	if outputFile == "" {
+		return nil
+	}
+
+	cfg, err := newD2Config(cmd, outputFile)
+	if err != nil {
+		return err
+	}
+	if dir != nil {
+		cfg.Cache = dir
+	}
+
+	subClasses, subRelations := parse.Subgraph(classes, relations, targets...)
+	output, err := d2.D2(&graph{classes: subClasses, relations: subRelations}, cfg)
+	if err != nil {
+		return err
+	}
+
+	if _, statErr := os.Stat(outputFile); statErr == nil && cmd.Flag(allowOverwriteFlag.Name).Value.String() == "false" {
+		return ErrNoOverwrite
+	}
+
+	if writeFileErr := os.WriteFile(outputFile, output, 0o644); writeFileErr != nil {
+		return writeFileErr
+	}
+
+	logrus.Info("d2 diagram written to ", outputFile)
+
+	return nil
+}
+
+// writeDependents as text, listing every parse.Dependent with its path of relations towards the targets
+func writeDependents(w io.Writer, targets []*domain.Class, dependents []*parse.Dependent) {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+
+	if len(dependents) == 0 {
+		_, _ = fmt.Fprintf(w, "no classes depend on %s\n", strings.Join(names, ", "))
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "classes depending on %s:\n", strings.Join(names, ", "))
+	for _, dependent := range dependents {
+		_, _ = fmt.Fprintf(w, "    %s: %s\n", dependent.Class.Name, relationPath(dependent.Path))
+	}
+}
+
+// relationPath describes a path of domain.Relation's as 'A.property -> B.property -> C'
+func relationPath(path []*domain.Relation) string {
+	var parts []string
+	for _, relation := range path {
+		from := relation.From.Name
+		if relation.FromProperty != nil {
+			from += "." + relation.FromProperty.Name
+		}
+		parts = append(parts, from)
+	}
+
+	if len(path) > 0 {
+		parts = append(parts, path[len(path)-1].To.Name)
+	}
+
+	return strings.Join(parts, " -> ")
+}
+
+// graph of domain.Class'es and domain.Relation's that is already parsed
+type graph struct {
+	classes   []*domain.Class
+	relations []*domain.Relation
+}
+
+// Classes of the graph
+func (g *graph) Classes() ([]*domain.Class, error) {
+	return g.classes, nil
+}
+
+// Relations of the graph
+func (g *graph) Relations() ([]*domain.Relation, error) {
+	return g.relations, nil
+}
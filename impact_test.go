@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpact_ListsDependents(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{"impact", "--globs", "./testdata/*.json", "--base-uri", "./", "Pet Store"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "classes depending on Pet Store:\n    Pet: Pet.store -> Pet Store\n", outputBuffer.String())
+}
+
+func TestImpact_RendersSubgraph(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	outputFile := filepath.Join(t.TempDir(), "impact.d2")
+	args := []string{"impact", "--globs", "./testdata/*.json", "--base-uri", "./", "file:///testdata/pet.json", "-o", outputFile}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "no classes depend on Pet\n", outputBuffer.String())
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `"Pet": {`)
+	assert.NotContains(t, string(b), `"Pet Store": {`)
+}
+
+func TestImpact_CacheDir(t *testing.T) {
+	// Arrange
+	cacheDir := t.TempDir()
+	args := []string{"impact", "--globs", "./testdata/*.json", "--base-uri", "./", "Pet Store", "--cache-dir", cacheDir}
+	resetFlags(impactCmd)
+	t.Cleanup(func() { resetFlags(impactCmd) })
+
+	var outputs []string
+	for range 2 {
+		outputBuffer := new(bytes.Buffer)
+		rootCmd.SetOut(outputBuffer)
+		rootCmd.SetArgs(args)
+
+		// Act
+		err := rootCmd.Execute()
+
+		// Assert
+		require.NoError(t, err)
+		outputs = append(outputs, outputBuffer.String())
+	}
+
+	assert.Equal(t, outputs[0], outputs[1], "the cached classes and relations give the same dependents")
+	documents, _ := filepath.Glob(filepath.Join(cacheDir, "document-*"))
+	assert.NotEmpty(t, documents)
+}
+
+func TestImpact_UnknownClass(t *testing.T) {
+	// Arrange
+	rootCmd.SetOut(new(bytes.Buffer))
+	args := []string{"impact", "--globs", "./testdata/*.json", "--base-uri", "./", "Unknown", "-o", ""}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, parse.ErrUnknownClass)
+	assert.EqualError(t, err, "unknown class: Unknown")
+}
@@ -1,7 +1,8 @@
 package parse
 
 import (
-	"maps"
+	"encoding/json"
+	"hash/fnv"
 	"reflect"
 	"slices"
 
@@ -18,28 +19,54 @@ type Cache interface {
 	Schemas() []*jsonschema.Schema
 }
 
-// MapCache tracks processed jsonschema.Schema's
+// MapCache tracks processed jsonschema.Schema's. Inline schemas (without an $id) are indexed by their cacheKey such
+// that only the processed schemas with the same key have to be deep compared
 type MapCache struct {
 	processed map[*jsonschema.Schema]struct{}
+
+	// order in which the schemas are processed
+	order []*jsonschema.Schema
+
+	// inline processed schemas by their cacheKey
+	inline map[cacheKey][]*jsonschema.Schema
+
+	// keys computed once per schema
+	keys map[*jsonschema.Schema]cacheKey
+}
+
+// cacheKey of a jsonschema.Schema consisting of the canonical URI of its resource and a hash of its contents. Schemas
+// that are deep equal always have the same cacheKey
+type cacheKey struct {
+	uri  string
+	hash uint64
 }
 
-// Schemas stored in Cache
+// Schemas stored in Cache in the order they are processed
 func (c *MapCache) Schemas() []*jsonschema.Schema {
 	if c == nil || c.processed == nil {
 		return nil
 	}
 
-	return slices.Collect(maps.Keys(c.processed))
+	return slices.Clone(c.order)
 }
 
 // Process marks a jsonschema.Schema as processed
 func (c *MapCache) Process(schema *jsonschema.Schema) {
 	if c.processed == nil {
 		c.processed = make(map[*jsonschema.Schema]struct{})
+		c.inline = make(map[cacheKey][]*jsonschema.Schema)
 	}
 
+	if _, ok := c.processed[schema]; ok {
+		return
+	}
 	c.processed[schema] = struct{}{}
-	return
+	c.order = append(c.order, schema)
+
+	if schema.ID == "" {
+		key := c.key(schema)
+		c.inline[key] = append(c.inline[key], schema)
+	}
 }
 
 // HasProcessed returns true iff a jsonschema.Schema is already processed
@@ -53,8 +80,14 @@ func (c *MapCache) HasProcessed(schema *jsonschema.Schema) bool {
 		return true
 	}
 
-	// deep equal also return true
-	for s, _ := range c.processed {
+	// resources with an $id are loaded once by the compiler, deep comparing those is redundant and explodes for
+	// documents that (transitively) reference each other
+	if schema.ID != "" {
+		return false
+	}
+
+	// deep equal also return true, only schemas with the same key can be deep equal
+	for _, s := range c.inline[c.key(schema)] {
 		if reflect.DeepEqual(s, schema) {
 			return true
 		}
@@ -62,3 +95,24 @@ func (c *MapCache) HasProcessed(schema *jsonschema.Schema) bool {
 
 	return false
 }
+
+// key of the schema, computed once per schema
+func (c *MapCache) key(schema *jsonschema.Schema) cacheKey {
+	if key, ok := c.keys[schema]; ok {
+		return key
+	}
+
+	key := cacheKey{uri: schema.GetSchemaURI()}
+	if contents, err := json.Marshal(schema); err == nil {
+		h := fnv.New64a()
+		_, _ = h.Write(contents)
+		key.hash = h.Sum64()
+	} // else the hash is zero and the schema is deep compared with every other schema that could not be hashed
+
+	if c.keys == nil {
+		c.keys = make(map[*jsonschema.Schema]cacheKey)
+	}
+	c.keys[schema] = key
+
+	return key
+}
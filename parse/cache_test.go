@@ -0,0 +1,147 @@
+package parse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kaptinlin/jsonschema"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapCache_HasProcessed(t *testing.T) {
+	// Arrange
+	compiler := jsonschema.NewCompiler()
+	schema, err := compiler.Compile([]byte(`{
+		"$id": "file:///pet.json",
+		"title": "Pet",
+		"properties": {
+			"home": {"title": "Address", "type": "object", "properties": {"street": {"type": "string"}}},
+			"work": {"title": "Address", "type": "object", "properties": {"street": {"type": "string"}}},
+			"other": {"title": "Address", "type": "object", "properties": {"city": {"type": "string"}}}
+		}
+	}`))
+	require.NoError(t, err)
+	other, err := compiler.Compile([]byte(`{"$id": "file:///other.json", "title": "Pet"}`))
+	require.NoError(t, err)
+	properties := *schema.Properties
+
+	cache := &MapCache{}
+
+	// Act
+	cache.Process(schema)
+	cache.Process(properties["home"])
+
+	// Assert
+	assert.True(t, cache.HasProcessed(schema))
+	assert.True(t, cache.HasProcessed(properties["home"]))
+	assert.True(t, cache.HasProcessed(properties["work"]), "deep equal inline schema is processed")
+	assert.False(t, cache.HasProcessed(properties["other"]))
+	assert.False(t, cache.HasProcessed(other))
+	assert.Len(t, cache.Schemas(), 2)
+}
+
+// BenchmarkParser_Classes parses generated schema graphs with the MapCache and the deepEqualCache it replaced
+func BenchmarkParser_Classes(b *testing.B) {
+	logrus.SetLevel(logrus.WarnLevel)
+
+	caches := map[string]func() Cache{
+		"MapCache":       func() Cache { return &MapCache{} },
+		"DeepEqualCache": func() Cache { return &deepEqualCache{} },
+	}
+
+	for _, size := range []int{100, 500, 1000} {
+		dir := generateSchemas(b, size)
+		parser := NewParser(filepath.Join(dir, "*.json")).SetBaseURI("file://" + dir)
+		schemas, err := parser.Schemas()
+		require.NoError(b, err)
+
+		for _, name := range []string{"MapCache", "DeepEqualCache"} {
+			b.Run(fmt.Sprintf("%s/%d", name, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					// only measure the parsing of classes from the compiled schemas
+					parser.Cache = &compiledCache{Cache: caches[name](), schemas: schemas}
+					parser.classParser = nil
+
+					classes, classesErr := parser.Classes()
+					require.NoError(b, classesErr)
+					require.Len(b, classes, 2*size)
+				}
+			})
+		}
+	}
+}
+
+// generateSchemas writes size schemas to a temporary directory where every schema has an inline object and
+// references the next schema
+func generateSchemas(b *testing.B, size int) string {
+	dir := b.TempDir()
+	for i := 0; i < size; i++ {
+		schema := fmt.Sprintf(`{
+			"$id": "file:///schema%[1]d.json",
+			"title": "Schema%[1]d",
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "format": "uuid"},
+				"name": {"type": "string"},
+				"address": {
+					"title": "Address%[1]d",
+					"type": "object",
+					"properties": {"street": {"type": "string"}, "city": {"type": "string"}}
+				},
+				"next": {"$ref": "schema%[2]d.json"}
+			}
+		}`, i, (i+1)%size)
+
+		require.NoError(b, os.WriteFile(filepath.Join(dir, fmt.Sprintf("schema%d.json", i)), []byte(schema), 0o644))
+	}
+
+	return dir
+}
+
+// compiledCache returns the compiled schemas such that the Parser does not compile them again
+type compiledCache struct {
+	Cache
+	schemas []*jsonschema.Schema
+}
+
+func (c *compiledCache) Schemas() []*jsonschema.Schema {
+	return c.schemas
+}
+
+// deepEqualCache deep compares an inline schema with every processed schema
+type deepEqualCache struct {
+	processed map[*jsonschema.Schema]struct{}
+}
+
+func (c *deepEqualCache) Schemas() []*jsonschema.Schema {
+	return nil
+}
+
+func (c *deepEqualCache) Process(schema *jsonschema.Schema) {
+	if c.processed == nil {
+		c.processed = make(map[*jsonschema.Schema]struct{})
+	}
+
+	c.processed[schema] = struct{}{}
+}
+
+func (c *deepEqualCache) HasProcessed(schema *jsonschema.Schema) bool {
+	if _, ok := c.processed[schema]; ok {
+		return true
+	} else if schema.ID != "" {
+		return false
+	}
+
+	for s := range c.processed {
+		if reflect.DeepEqual(s, schema) {
+			return true
+		}
+	}
+
+	return false
+}
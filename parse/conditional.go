@@ -0,0 +1,210 @@
+package parse
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// variant of a base schema, i.e. a conditional subschema ('then', 'else', 'dependentSchemas' or 'dependentRequired')
+// that is parsed as a Class of its own and related to the Class of the base schema by its condition
+type variant struct {
+	// base schema containing the conditional subschema
+	base *jsonschema.Schema
+
+	// name of the Class of the variant if the subschema has no title
+	name string
+
+	// processed is true iff the Class of the variant is parsed, variants are tracked by identity instead of the Cache
+	// such that equal subschemas of different conditions each get a Class
+	processed bool
+}
+
+// nonWord matches the characters that are replaced to name a variant after its condition
+var nonWord = regexp.MustCompile(`[^\pL\pN_-]+`)
+
+// conditionals of the schema (and the inline subschemas of its 'allOf') are parsed as variants of the class:
+//
+//   - 'then' and 'else' related by the condition of 'if', e.g. 'kind == "card"' and 'kind != "card"'
+//   - every entry of 'dependentSchemas' related by 'has name'
+//   - every entry of 'dependentRequired' as a variant with the required properties related by 'has name', a required
+//     property that is not defined by the schema (or its 'allOf') is left out as its type is unknown
+func (p *ClassParser) conditionals(class *domain.Class, schema *jsonschema.Schema) error {
+	sources := []*jsonschema.Schema{schema}
+	for _, subschema := range schema.AllOf {
+		if subschema.Ref == "" && subschema.DynamicRef == "" {
+			sources = append(sources, subschema)
+		}
+	}
+
+	for _, source := range sources {
+		if source.If != nil {
+			thenLabel, elseLabel, thenSuffix, elseSuffix := "then", "else", "then", "else"
+			if cond := condition(source.If); cond != "" {
+				thenLabel, elseLabel = cond, negate(cond)
+				thenSuffix, elseSuffix = nonWord.ReplaceAllString(cond, " "), "not "+nonWord.ReplaceAllString(cond, " ")
+			}
+
+			if err := p.variant(class, schema, source.Then, thenLabel, thenSuffix); err != nil {
+				return err
+			}
+
+			if err := p.variant(class, schema, source.Else, elseLabel, elseSuffix); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(source.DependentSchemas)) {
+			if err := p.variant(class, schema, source.DependentSchemas[name], "has "+name, "has "+name); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(source.DependentRequired)) {
+			if err := p.variant(class, schema, dependentRequired(sources, source.DependentRequired[name]), "has "+name, "has "+name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// variant of the base schema for the subschema (if any) related to the Class of the base by label, the Class of the
+// variant is named after the class followed by the suffix unless the subschema has a title
+func (p *ClassParser) variant(class *domain.Class, base *jsonschema.Schema, subschema *jsonschema.Schema, label string, suffix string) error {
+	if subschema == nil || subschema.Boolean != nil {
+		return nil
+	}
+
+	reference := &Reference{Type: ReferenceType(label), FromParent: base, To: subschema, ToParent: subschema}
+	if subschema.Ref != "" || subschema.DynamicRef != "" {
+		resolvedRef := subschema.ResolvedRef
+		if resolvedRef == nil {
+			resolvedRef = subschema.ResolvedDynamicRef
+		}
+		if resolvedRef == nil {
+			return fmt.Errorf("$ref '%s' (or $dynamicRef '%s') of '%s' could not be resolved: %w", subschema.Ref, subschema.DynamicRef, label, ErrUnknownSchema)
+		}
+
+		// a resolved schema that is a Class of its own (e.g. '#/$defs/card') is related instead of its document
+		resolvedRefParent := resolvedRef
+		if !isClass(resolvedRef) {
+			var err error
+			if resolvedRefParent, err = p.Parser.Compiler.GetSchema(resolvedRef.GetSchemaURI()); err != nil {
+				return fmt.Errorf("parent of $ref '%s' failed to load: %w", resolvedRef.GetSchemaURI(), err)
+			}
+		}
+
+		if !p.Cache.HasProcessed(resolvedRefParent) {
+			p.queue = append(p.queue, resolvedRefParent)
+		}
+
+		reference.To, reference.ToParent = resolvedRef, resolvedRefParent
+		p.references = append(p.references, reference)
+
+		return nil
+	}
+
+	if p.variants == nil {
+		p.variants = map[*jsonschema.Schema]*variant{}
+	}
+
+	if _, ok := p.variants[subschema]; !ok {
+		prefix := strings.TrimSpace(class.Name + " " + strings.TrimSpace(suffix))
+		name := prefix
+		for i := 2; p.hasVariant(name); i++ {
+			name = fmt.Sprintf("%s %d", prefix, i)
+		}
+
+		p.variants[subschema] = &variant{base: base, name: name}
+		p.queue = append(p.queue, subschema)
+	}
+
+	p.references = append(p.references, reference)
+
+	return nil
+}
+
+// hasVariant returns true iff a variant is named name
+func (p *ClassParser) hasVariant(name string) bool {
+	for _, v := range p.variants {
+		if v.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// dependentRequired returns a schema with the required properties as defined by the first of the sources defining them,
+// properties that are not defined by any of the sources are left out
+func dependentRequired(sources []*jsonschema.Schema, required []string) *jsonschema.Schema {
+	properties := jsonschema.SchemaMap{}
+	for _, name := range required {
+		for _, source := range sources {
+			if source.Properties != nil && (*source.Properties)[name] != nil {
+				properties[name] = (*source.Properties)[name]
+				break
+			}
+		}
+	}
+
+	return &jsonschema.Schema{Properties: &properties, Required: required}
+}
+
+// condition of an 'if' schema as the 'const' and 'enum' of its properties and its 'required' properties, e.g.
+// 'kind == "card" && has number', or "" if the condition cannot be described
+func condition(schema *jsonschema.Schema) string {
+	var parts []string
+	described := map[string]bool{}
+	if schema.Properties != nil {
+		for _, name := range slices.Sorted(maps.Keys(*schema.Properties)) {
+			property := (*schema.Properties)[name]
+			if property.Const != nil && property.Const.IsSet {
+				parts = append(parts, fmt.Sprintf("%s == %s", name, literal(property.Const.Value)))
+				described[name] = true
+			} else if len(property.Enum) > 0 {
+				var values []string
+				for _, value := range property.Enum {
+					values = append(values, literal(value))
+				}
+				parts = append(parts, fmt.Sprintf("%s in [%s]", name, strings.Join(values, ", ")))
+				described[name] = true
+			}
+		}
+	}
+
+	for _, name := range slices.Sorted(slices.Values(schema.Required)) {
+		if !described[name] {
+			parts = append(parts, "has "+name)
+		}
+	}
+
+	return strings.Join(parts, " && ")
+}
+
+// negate the condition, e.g. 'kind != "card"' for 'kind == "card"' or '!(has number)'
+func negate(cond string) string {
+	if strings.Count(cond, " == ") == 1 && !strings.Contains(cond, " && ") {
+		return strings.Replace(cond, " == ", " != ", 1)
+	}
+
+	return "!(" + cond + ")"
+}
+
+// literal JSON value used in a condition
+func literal(value any) string {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(b)
+}
@@ -0,0 +1,78 @@
+package parse
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCondition(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		expected string
+		negated  string
+	}{
+		"const": {
+			schema:   `{"properties": {"kind": {"const": "card"}}}`,
+			expected: `kind == "card"`,
+			negated:  `kind != "card"`,
+		},
+		"enum and required": {
+			schema:   `{"properties": {"kind": {"enum": ["card", 1]}}, "required": ["kind", "number"]}`,
+			expected: `kind in ["card", 1] && has number`,
+			negated:  `!(kind in ["card", 1] && has number)`,
+		},
+		"undescribed": {
+			schema:   `{"minProperties": 2}`,
+			expected: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			compiler, err := NewCompiler("")
+			require.NoError(t, err)
+			schema, err := compiler.Compile([]byte(tt.schema))
+			require.NoError(t, err)
+
+			// Act
+			cond := condition(schema)
+
+			// Assert
+			assert.Equal(t, tt.expected, cond)
+			if tt.negated != "" {
+				assert.Equal(t, tt.negated, negate(cond))
+			}
+		})
+	}
+}
+
+func TestParser_DependentRequired(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"payment.json": {Data: []byte(`{
+			"$id": "file:///payment.json",
+			"title": "Payment",
+			"type": "object",
+			"properties": {"number": {"type": "string"}},
+			"allOf": [{"properties": {"expiry": {"type": "string"}}}],
+			"dependentRequired": {"number": ["expiry", "cvc"]}
+		}`)},
+	}
+	parser := NewParser("payment.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	// Act
+	classes, err := parser.Classes()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	assert.Equal(t, "Payment has number", classes[1].Name)
+	require.Len(t, classes[1].Properties, 1)
+	assert.Equal(t, "expiry", classes[1].Properties[0].Name)
+	assert.Equal(t, "string", classes[1].Properties[0].Type)
+}
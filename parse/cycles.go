@@ -0,0 +1,51 @@
+package parse
+
+import "github.com/Emptyless/jsonschema-transform/domain"
+
+// Cycle of domain.Class'es that (transitively) reference each other
+type Cycle struct {
+	// Classes in the Cycle
+	Classes []*domain.Class
+
+	// Relations between the Classes of the Cycle
+	Relations []*domain.Relation
+}
+
+// Cycles in the directed graph formed by the domain.Relation's, one per strongly connected component that forms a
+// cycle (see StronglyConnectedComponents and IsCycle)
+func Cycles(classes []*domain.Class, relations []*domain.Relation) []*Cycle {
+	var res []*Cycle
+	for _, component := range StronglyConnectedComponents(classes, relations) {
+		if !IsCycle(component, relations) {
+			continue
+		}
+
+		inComponent := map[*domain.Class]bool{}
+		for _, class := range component {
+			inComponent[class] = true
+		}
+
+		cycle := &Cycle{Classes: component}
+		for _, relation := range relations {
+			if inComponent[relation.From] && inComponent[relation.To] {
+				cycle.Relations = append(cycle.Relations, relation)
+			}
+		}
+
+		res = append(res, cycle)
+	}
+
+	return res
+}
+
+// CycleRelations returns the set of domain.Relation's that are part of a Cycle
+func CycleRelations(classes []*domain.Class, relations []*domain.Relation) map[*domain.Relation]bool {
+	res := map[*domain.Relation]bool{}
+	for _, cycle := range Cycles(classes, relations) {
+		for _, relation := range cycle.Relations {
+			res[relation] = true
+		}
+	}
+
+	return res
+}
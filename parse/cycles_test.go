@@ -0,0 +1,49 @@
+package parse
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCycles_Defs(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"order.json": {Data: []byte(`{
+			"$id": "file:///order.json",
+			"title": "Order",
+			"type": "object",
+			"properties": {
+				"id": {"$ref": "#/$defs/id"},
+				"billing": {"$ref": "#/$defs/address"},
+				"lines": {"type": "array", "items": {"$ref": "#/$defs/line"}}
+			},
+			"$defs": {
+				"id": {"type": "string", "format": "uuid"},
+				"address": {"title": "Address", "type": "object", "properties": {"city": {"type": "string"}}},
+				"line": {"title": "Line", "type": "object", "properties": {"order": {"$ref": "#/$defs/id"}}}
+			}
+		}`)},
+	}
+	parser := NewParser("order.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	classes, err := parser.Classes()
+	require.NoError(t, err)
+
+	relations, err := parser.Relations()
+	require.NoError(t, err)
+
+	// Act
+	cycles := Cycles(classes, relations)
+
+	// Assert
+	assert.Empty(t, cycles)
+	var edges []string
+	for _, relation := range relations {
+		edges = append(edges, relation.From.Name+"."+relation.FromProperty.Name+" -- "+relation.To.Name+": "+relation.Type)
+	}
+	assert.Equal(t, []string{"Order.billing -- Address: $ref", "Order.lines -- Line: $ref"}, edges)
+}
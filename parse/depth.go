@@ -5,9 +5,13 @@ import (
 	"github.com/kaptinlin/jsonschema"
 )
 
+// Unreachable is the depth in the DepthMap of a class that cannot be reached from any root
+const Unreachable = -1
+
 // DepthMap w.r.t. the provided jsonschema.Schema roots that are directly parsed from the glob patterns. Each directly
 // referenced file (e.g. some/file/schema.json) must have a depth of 0. For each not directly referenced file the distance
-// is the shortest distance from any root to that file using the classical Dijkstra's shortest-path algorithm.
+// is the shortest distance from any root to that file using the classical Dijkstra's shortest-path algorithm. A class that
+// cannot be reached from any root has the Unreachable depth.
 func DepthMap(roots []*jsonschema.Schema, classes []*domain.Class, relations []*domain.Relation) map[*domain.Class]int {
 	graph := nodes{}
 	for _, class := range classes {
@@ -46,6 +50,12 @@ func DepthMap(roots []*jsonschema.Schema, classes []*domain.Class, relations []*
 		graph.Reset()
 	}
 
+	for class, depth := range depthMap {
+		if depth == unvisited {
+			depthMap[class] = Unreachable
+		}
+	}
+
 	return depthMap
 }
 
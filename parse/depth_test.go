@@ -0,0 +1,23 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDepthMap(t *testing.T) {
+	// Arrange
+	root := &jsonschema.Schema{}
+	rootClass, nodeClass, orphanClass := &domain.Class{Schema: root, Name: "Root"}, &domain.Class{Name: "Node"}, &domain.Class{Name: "Orphan"}
+	classes := []*domain.Class{rootClass, nodeClass, orphanClass}
+	relations := []*domain.Relation{{From: rootClass, To: nodeClass}}
+
+	// Act
+	depthMap := DepthMap([]*jsonschema.Schema{root}, classes, relations)
+
+	// Assert
+	assert.Equal(t, map[*domain.Class]int{rootClass: 0, nodeClass: 1, orphanClass: Unreachable}, depthMap)
+}
@@ -0,0 +1,83 @@
+package parse
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// keyedKeywords contain an object where each value is a subschema (instead of being a subschema themselves)
+var keyedKeywords = map[string]bool{
+	"$defs":             true,
+	"definitions":       true,
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+	"dependencies":      true,
+}
+
+// dataKeywords contain instance data instead of subschemas and are never walked
+var dataKeywords = map[string]bool{
+	"const":    true,
+	"enum":     true,
+	"default":  true,
+	"examples": true,
+	"required": true,
+}
+
+// IsKeyedKeyword returns true iff the keyword contains an object where each value is a subschema, e.g. 'properties'
+func IsKeyedKeyword(keyword string) bool {
+	return keyedKeywords[keyword]
+}
+
+// IsDataKeyword returns true iff the keyword contains instance data instead of subschemas, e.g. 'examples'
+func IsDataKeyword(keyword string) bool {
+	return dataKeywords[keyword]
+}
+
+// WalkDocument walks a decoded (e.g. using json.Unmarshal) schema document depth first and calls fn for every schema
+// object with its base URI, which is resolved using the '$id' keywords encountered so far. Keys are visited in sorted
+// order and keywords containing instance data (e.g. 'examples') are not walked
+func WalkDocument(document any, base *url.URL, fn func(schema map[string]any, base *url.URL)) {
+	switch node := document.(type) {
+	case map[string]any:
+		if id, ok := node["$id"].(string); ok {
+			base = ResolveURI(base, id)
+		}
+
+		fn(node, base)
+
+		for _, key := range slices.Sorted(maps.Keys(node)) {
+			if dataKeywords[key] {
+				continue
+			}
+
+			if keyed, ok := node[key].(map[string]any); ok && keyedKeywords[key] {
+				for _, name := range slices.Sorted(maps.Keys(keyed)) {
+					WalkDocument(keyed[name], base, fn)
+				}
+				continue
+			}
+
+			WalkDocument(node[key], base, fn)
+		}
+	case []any:
+		for _, item := range node {
+			WalkDocument(item, base, fn)
+		}
+	}
+}
+
+// ResolveURI reference against the base, if the base is nil or either fails to parse the reference is parsed as is
+func ResolveURI(base *url.URL, reference string) *url.URL {
+	ref, err := url.Parse(reference)
+	if err != nil {
+		return &url.URL{Path: reference}
+	}
+
+	if base == nil {
+		return ref
+	}
+
+	return base.ResolveReference(ref)
+}
@@ -0,0 +1,479 @@
+package parse
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Draft of the JSON Schema specification a schema is written in
+type Draft string
+
+// Draft04 of the JSON Schema specification
+const Draft04 Draft = "draft-04"
+
+// Draft06 of the JSON Schema specification
+const Draft06 Draft = "draft-06"
+
+// Draft07 of the JSON Schema specification
+const Draft07 Draft = "draft-07"
+
+// Draft201909 of the JSON Schema specification
+const Draft201909 Draft = "2019-09"
+
+// Draft202012 of the JSON Schema specification, used by the compiler
+const Draft202012 Draft = "2020-12"
+
+// Draft202012Schema is the '$schema' of a normalized schema
+const Draft202012Schema = "https://json-schema.org/draft/2020-12/schema"
+
+// recursiveAnchor is the name of the '$dynamicAnchor' replacing '$recursiveAnchor'
+const recursiveAnchor = "recursive"
+
+// DetectDraft from the '$schema' keyword, or the fallback if it is absent or not recognized
+func DetectDraft(schema string, fallback Draft) Draft {
+	for _, draft := range []Draft{Draft04, Draft06, Draft07, Draft201909, Draft202012} {
+		if strings.Contains(schema, string(draft)) {
+			return draft
+		}
+	}
+
+	return fallback
+}
+
+// Legacy returns true iff the Draft precedes 2019-09 (i.e. uses 'definitions' and ignores the siblings of '$ref')
+func (d Draft) Legacy() bool {
+	return d == Draft04 || d == Draft06 || d == Draft07
+}
+
+// annotationKeywords do not affect validation and are therefore kept as siblings of a '$ref' of a legacy Draft
+var annotationKeywords = map[string]bool{
+	"title":       true,
+	"description": true,
+	"$comment":    true,
+	"default":     true,
+	"examples":    true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+// Change to a schema document made while upgrading it to draft 2020-12
+type Change struct {
+	// Pointer to the schema object that is changed (a JSON pointer fragment, e.g. '#/properties/name')
+	Pointer string
+
+	// Description of the Change, e.g. "renamed 'definitions' to '$defs'"
+	Description string
+}
+
+// String of the Change, e.g. "#/properties/name: renamed 'definitions' to '$defs'"
+func (c Change) String() string {
+	return c.Pointer + ": " + c.Description
+}
+
+// Normalize the schema document to draft 2020-12 such that the compiler (and hence the ClassParser) sees the same
+// keywords regardless of the draft it is written in (see Upgrade). If the contents are not valid JSON or are
+// already 2020-12 they are returned as is
+func Normalize(contents []byte) []byte {
+	if !mentionsLegacyDraft(contents) && !bytes.Contains(contents, []byte("definitions")) {
+		return contents // nothing to upgrade, skip decoding the document
+	}
+
+	document, err := DecodeOrdered(contents)
+	if err != nil {
+		return contents
+	}
+
+	if len(Upgrade(document)) == 0 {
+		return contents
+	}
+
+	normalized, err := EncodeOrdered(document)
+	if err != nil {
+		return contents
+	}
+
+	return normalized
+}
+
+// Upgrade the document (see DecodeOrdered) in place to draft 2020-12 and return the changes made. The draft is
+// detected from '$schema' for every resource, by default a document is considered to be 2020-12:
+//
+//   - 'id' becomes '$id' (draft-04) and a fragment '$id' (e.g. '#address') becomes an '$anchor' (draft-04, 06 and 07)
+//   - boolean 'exclusiveMinimum' and 'exclusiveMaximum' become numbers (draft-04)
+//   - 'definitions' becomes '$defs' and '$ref' pointers into 'definitions' are rewritten (draft-04, 06 and 07)
+//   - siblings of '$ref' are removed as they are ignored, except annotations, '$defs' and the '$id' and '$schema' of
+//     the document (draft-04, 06 and 07)
+//   - 'dependencies' becomes 'dependentRequired' or 'dependentSchemas' (draft-04, 06 and 07)
+//   - array-form 'items' becomes 'prefixItems' and 'additionalItems' becomes 'items' (draft-04 up to 2019-09)
+//   - '$recursiveRef' and '$recursiveAnchor' become '$dynamicRef' and '$dynamicAnchor' (2019-09)
+//   - a '$ref' to another document pointing into its 'definitions' is rewritten to '$defs' (2019-09 and 2020-12), as
+//     such a pointer can only be resolved if the other document is written in a draft preceding 2019-09 and hence is
+//     normalized as well
+func Upgrade(document any) []Change {
+	root, ok := document.(*Object)
+	if !ok {
+		return nil
+	}
+
+	u := &upgrader{recursive: hasRecursiveAnchor(root)}
+	u.object(root, Draft202012, "#", true)
+
+	return u.changes
+}
+
+// upgrader of a single document
+type upgrader struct {
+	// recursive is true iff the document contains a '$recursiveAnchor'
+	recursive bool
+
+	// changes made so far
+	changes []Change
+}
+
+// change made to the schema object at the pointer
+func (u *upgrader) change(pointer string, format string, args ...any) {
+	u.changes = append(u.changes, Change{Pointer: pointer, Description: fmt.Sprintf(format, args...)})
+}
+
+// object upgrades the schema object written in the draft and its subschemas
+func (u *upgrader) object(node *Object, draft Draft, pointer string, root bool) {
+	if schema, ok := getString(node, "$schema"); ok {
+		draft = DetectDraft(schema, draft)
+		if DetectDraft(schema, Draft202012) != Draft202012 {
+			node.Set("$schema", Draft202012Schema)
+			u.change(pointer, "replaced '$schema' %s with %s", schema, Draft202012Schema)
+		}
+	}
+
+	if draft == Draft04 {
+		if id, ok := getString(node, "id"); ok {
+			if _, exists := node.Get("$id"); !exists {
+				node.Rename("id", "$id")
+				u.change(pointer, "renamed 'id' to '$id'")
+			} else {
+				node.Delete("id")
+				u.change(pointer, "removed 'id' %s as '$id' is set", id)
+			}
+		}
+
+		u.exclusive(node, pointer, "exclusiveMinimum", "minimum")
+		u.exclusive(node, pointer, "exclusiveMaximum", "maximum")
+	}
+
+	if draft.Legacy() {
+		u.anchor(node, pointer)
+		u.rename(node, pointer, "definitions", "$defs")
+		u.dependencies(node, pointer)
+
+		if ref, ok := getString(node, "$ref"); ok {
+			if rewritten := rewriteDefinitions(ref); rewritten != ref {
+				node.Set("$ref", rewritten)
+				u.change(pointer, "rewrote '$ref' %s to %s", ref, rewritten)
+			}
+
+			for _, key := range slices.Clone(node.Keys()) {
+				if key != "$ref" && key != "$defs" && !annotationKeywords[key] && !(root && (key == "$id" || key == "$schema")) {
+					node.Delete(key)
+					u.change(pointer, "removed '%s' as siblings of '$ref' are ignored", key)
+				}
+			}
+		}
+	}
+
+	if ref, ok := getString(node, "$ref"); ok && !draft.Legacy() && !strings.HasPrefix(ref, "#") {
+		if rewritten := rewriteDefinitions(ref); rewritten != ref {
+			node.Set("$ref", rewritten)
+			u.change(pointer, "rewrote '$ref' %s to %s as the other document is normalized", ref, rewritten)
+		}
+	}
+
+	if draft != Draft202012 {
+		if items, ok := node.Get("items"); ok && isArray(items) {
+			node.Rename("items", "prefixItems")
+			u.change(pointer, "renamed array-form 'items' to 'prefixItems'")
+			if _, exists := node.Get("additionalItems"); exists {
+				node.Rename("additionalItems", "items")
+				u.change(pointer, "renamed 'additionalItems' to 'items'")
+			}
+		} else if _, exists := node.Get("additionalItems"); exists {
+			node.Delete("additionalItems")
+			u.change(pointer, "removed 'additionalItems' as 'items' is not an array")
+		}
+	}
+
+	if draft == Draft201909 {
+		if ref, ok := getString(node, "$recursiveRef"); ok && u.recursive {
+			node.Rename("$recursiveRef", "$dynamicRef")
+			node.Set("$dynamicRef", ref+recursiveAnchor)
+			u.change(pointer, "replaced '$recursiveRef' with '$dynamicRef' %s", ref+recursiveAnchor)
+		} else if ok {
+			node.Rename("$recursiveRef", "$ref")
+			u.change(pointer, "replaced '$recursiveRef' with '$ref' as there is no '$recursiveAnchor'")
+		}
+
+		if anchor, ok := node.Get("$recursiveAnchor"); ok && anchor == true {
+			node.Rename("$recursiveAnchor", "$dynamicAnchor")
+			node.Set("$dynamicAnchor", recursiveAnchor)
+			u.change(pointer, "replaced '$recursiveAnchor' with '$dynamicAnchor' %s", recursiveAnchor)
+		} else if ok {
+			node.Delete("$recursiveAnchor")
+			u.change(pointer, "removed '$recursiveAnchor' false")
+		}
+	}
+
+	for _, key := range node.Keys() {
+		if dataKeywords[key] {
+			continue
+		}
+
+		value, _ := node.Get(key)
+		if keyed, ok := value.(*Object); ok && keyedKeywords[key] {
+			for _, name := range keyed.Keys() {
+				subschema, _ := keyed.Get(name)
+				u.value(subschema, draft, pointer+"/"+escapePointer(key)+"/"+escapePointer(name))
+			}
+			continue
+		}
+
+		u.value(value, draft, pointer+"/"+escapePointer(key))
+	}
+}
+
+// value that is a schema object or an array of schema objects
+func (u *upgrader) value(value any, draft Draft, pointer string) {
+	switch value := value.(type) {
+	case *Object:
+		u.object(value, draft, pointer, false)
+	case []any:
+		for i, item := range value {
+			if subschema, ok := item.(*Object); ok {
+				u.object(subschema, draft, fmt.Sprintf("%s/%d", pointer, i), false)
+			}
+		}
+	}
+}
+
+// rename the keyword from to the keyword to, if both exist the entries of from are merged into to
+func (u *upgrader) rename(node *Object, pointer string, from string, to string) {
+	value, ok := node.Get(from)
+	if !ok {
+		return
+	}
+
+	existing, exists := node.Get(to)
+	if !exists {
+		node.Rename(from, to)
+		u.change(pointer, "renamed '%s' to '%s'", from, to)
+		return
+	}
+
+	node.Delete(from)
+	into, intoObject := existing.(*Object)
+	entries, entriesObject := value.(*Object)
+	if !intoObject || !entriesObject {
+		u.change(pointer, "removed '%s' as '%s' is set", from, to)
+		return
+	}
+
+	for _, key := range entries.Keys() {
+		if _, conflict := into.Get(key); !conflict {
+			entry, _ := entries.Get(key)
+			into.Set(key, entry)
+		}
+	}
+	u.change(pointer, "merged '%s' into '%s'", from, to)
+}
+
+// anchor replaces the fragment of an '$id' (e.g. '#address' or 'other.json#address') with an '$anchor' as a plain
+// name fragment is only allowed in '$anchor' since 2019-09
+func (u *upgrader) anchor(node *Object, pointer string) {
+	id, ok := getString(node, "$id")
+	base, fragment, found := strings.Cut(id, "#")
+	if !ok || !found || (base != "" && fragment == "") {
+		return
+	}
+
+	_, exists := node.Get("$anchor")
+	switch {
+	case base == "" && (fragment == "" || exists):
+		node.Delete("$id")
+		u.change(pointer, "removed '$id' %s", id)
+	case base == "":
+		node.Rename("$id", "$anchor")
+		node.Set("$anchor", fragment)
+		u.change(pointer, "replaced '$id' %s with '$anchor' %s", id, fragment)
+	case exists:
+		node.Set("$id", base)
+		u.change(pointer, "removed the fragment of '$id' %s as '$anchor' is set", id)
+	default:
+		node.Set("$id", base)
+		node.Set("$anchor", fragment)
+		u.change(pointer, "moved the fragment of '$id' %s to '$anchor' %s", id, fragment)
+	}
+}
+
+// dependencies are split into 'dependentRequired' (array values) and 'dependentSchemas' (schema values)
+func (u *upgrader) dependencies(node *Object, pointer string) {
+	value, ok := node.Get("dependencies")
+	deps, isObject := value.(*Object)
+	if !ok || !isObject {
+		return
+	}
+
+	required, schemas := &Object{}, &Object{}
+	for _, name := range deps.Keys() {
+		dependency, _ := deps.Get(name)
+		if isArray(dependency) {
+			required.Set(name, dependency)
+		} else {
+			schemas.Set(name, dependency)
+		}
+	}
+
+	// keep the position of 'dependencies' for the first of the replacing keywords
+	for _, replacement := range []struct {
+		keyword string
+		value   *Object
+	}{{"dependentRequired", required}, {"dependentSchemas", schemas}} {
+		if len(replacement.value.Keys()) == 0 {
+			continue
+		}
+
+		if _, exists := node.Get("dependencies"); exists {
+			node.Rename("dependencies", replacement.keyword)
+		}
+		node.Set(replacement.keyword, replacement.value)
+		u.change(pointer, "moved %s of 'dependencies' to '%s'", strings.Join(replacement.value.Keys(), ", "), replacement.keyword)
+	}
+	node.Delete("dependencies")
+}
+
+// exclusive converts a boolean exclusive keyword to the number of its limit keyword (e.g. 'maximum')
+func (u *upgrader) exclusive(node *Object, pointer string, keyword string, limit string) {
+	value, ok := node.Get(keyword)
+	exclusive, isBool := value.(bool)
+	if !ok || !isBool {
+		return
+	}
+
+	number, exists := node.Get(limit)
+	if exclusive && exists {
+		node.Set(keyword, number)
+		node.Delete(limit)
+		u.change(pointer, "replaced '%s' and boolean '%s' with numeric '%s'", limit, keyword, keyword)
+		return
+	}
+
+	node.Delete(keyword)
+	u.change(pointer, "removed boolean '%s' %t", keyword, exclusive)
+}
+
+// hasRecursiveAnchor returns true iff the document contains a '$recursiveAnchor' that is true
+func hasRecursiveAnchor(document *Object) bool {
+	found := false
+	walkObjects(document, func(object *Object) {
+		if anchor, ok := object.Get("$recursiveAnchor"); ok && anchor == true {
+			found = true
+		}
+	})
+
+	return found
+}
+
+// walkObjects calls fn for every object of the value that is not instance data (see dataKeywords)
+func walkObjects(value any, fn func(object *Object)) {
+	switch value := value.(type) {
+	case *Object:
+		fn(value)
+		for _, key := range value.Keys() {
+			if !dataKeywords[key] {
+				child, _ := value.Get(key)
+				walkObjects(child, fn)
+			}
+		}
+	case []any:
+		for _, item := range value {
+			walkObjects(item, fn)
+		}
+	}
+}
+
+// getString value of the key
+func getString(node *Object, key string) (string, bool) {
+	value, ok := node.Get(key)
+	s, isString := value.(string)
+
+	return s, ok && isString
+}
+
+// isArray returns true iff the value is a JSON array
+func isArray(value any) bool {
+	_, ok := value.([]any)
+
+	return ok
+}
+
+// escapePointer escapes a reference token of a JSON pointer
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// rewriteDefinitions of the JSON pointer fragment of the reference, every 'definitions' keyword becomes '$defs', e.g.
+// '#/definitions/outer/definitions/inner' becomes '#/$defs/outer/$defs/inner'. A subschema named 'definitions' (e.g.
+// '#/properties/definitions') is kept
+func rewriteDefinitions(ref string) string {
+	base, fragment, ok := strings.Cut(ref, "#")
+	if !ok || !strings.HasPrefix(fragment, "/") {
+		return ref
+	}
+
+	segments := strings.Split(fragment, "/")
+	keyword := true
+	for i, segment := range segments[1:] {
+		switch {
+		case !keyword:
+			keyword = true // the segment is the name of a subschema of a keyed keyword
+		case IsKeyedKeyword(segment):
+			keyword = false
+			if segment == "definitions" {
+				segments[i+1] = "$defs"
+			}
+		}
+	}
+
+	return base + "#" + strings.Join(segments, "/")
+}
+
+// mentionsLegacyDraft returns true iff the contents mention a Draft preceding 2020-12, i.e. a '$schema' of the
+// document (or one of its resources) may be detected as such by DetectDraft
+func mentionsLegacyDraft(contents []byte) bool {
+	for _, draft := range []Draft{Draft04, Draft06, Draft07, Draft201909} {
+		if bytes.Contains(contents, []byte(draft)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizing returns a Loader that normalizes the schemas read by the loader (see Normalize)
+func normalizing(loader Loader) Loader {
+	return func(url string) (io.ReadCloser, error) {
+		rc, err := loader(url)
+		if err != nil {
+			return rc, err
+		}
+		defer func() { _ = rc.Close() }()
+
+		contents, err := io.ReadAll(rc)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(bytes.NewReader(Normalize(contents))), nil
+	}
+}
@@ -0,0 +1,316 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectDraft(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, Draft04, DetectDraft("http://json-schema.org/draft-04/schema#", Draft202012))
+	assert.Equal(t, Draft07, DetectDraft("http://json-schema.org/draft-07/schema", Draft202012))
+	assert.Equal(t, Draft201909, DetectDraft("https://json-schema.org/draft/2019-09/schema", Draft202012))
+	assert.Equal(t, Draft06, DetectDraft("https://example.com/custom", Draft06))
+}
+
+func TestNormalize_Draft04(t *testing.T) {
+	// Arrange
+	schema := `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"id": "file:///order.json",
+		"properties": {
+			"id": {"type": "string"},
+			"quantity": {"type": "integer", "minimum": 0, "exclusiveMinimum": true, "maximum": 10, "exclusiveMaximum": false},
+			"line": {"$ref": "#/definitions/line", "description": "kept", "type": "object"}
+		},
+		"dependencies": {"coupon": ["quantity"], "gift": {"required": ["message"]}},
+		"definitions": {"line": {"type": "object"}}
+	}`
+
+	// Act
+	normalized := decode(t, Normalize([]byte(schema)))
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"$schema": Draft202012Schema,
+		"$id":     "file:///order.json",
+		"properties": map[string]any{
+			"id":       map[string]any{"type": "string"},
+			"quantity": map[string]any{"type": "integer", "exclusiveMinimum": json.Number("0"), "maximum": json.Number("10")},
+			"line":     map[string]any{"$ref": "#/$defs/line", "description": "kept"},
+		},
+		"dependentRequired": map[string]any{"coupon": []any{"quantity"}},
+		"dependentSchemas":  map[string]any{"gift": map[string]any{"required": []any{"message"}}},
+		"$defs":             map[string]any{"line": map[string]any{"type": "object"}},
+	}, normalized)
+}
+
+func TestRewriteDefinitions(t *testing.T) {
+	tests := map[string]struct {
+		ref      string
+		expected string
+	}{
+		"definitions": {
+			ref:      "#/definitions/line",
+			expected: "#/$defs/line",
+		},
+		"nested definitions": {
+			ref:      "#/definitions/outer/definitions/inner",
+			expected: "#/$defs/outer/$defs/inner",
+		},
+		"other document": {
+			ref:      "price.json#/definitions/price/properties/amount",
+			expected: "price.json#/$defs/price/properties/amount",
+		},
+		"subschema named definitions": {
+			ref:      "#/properties/definitions/definitions/definitions",
+			expected: "#/properties/definitions/$defs/definitions",
+		},
+		"anchor": {
+			ref:      "#definitions",
+			expected: "#definitions",
+		},
+		"no fragment": {
+			ref:      "definitions.json",
+			expected: "definitions.json",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			res := rewriteDefinitions(tc.ref)
+
+			// Assert
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestNormalize_Draft07Items(t *testing.T) {
+	// Arrange
+	schema := `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"properties": {
+			"pair": {"items": [{"type": "string"}, {"type": "number"}], "additionalItems": false},
+			"list": {"items": {"type": "string"}, "additionalItems": false}
+		}
+	}`
+
+	// Act
+	normalized := decode(t, Normalize([]byte(schema)))
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"pair": map[string]any{"prefixItems": []any{map[string]any{"type": "string"}, map[string]any{"type": "number"}}, "items": false},
+		"list": map[string]any{"items": map[string]any{"type": "string"}},
+	}, normalized["properties"])
+}
+
+func TestNormalize_Draft201909Recursive(t *testing.T) {
+	// Arrange
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2019-09/schema",
+		"$recursiveAnchor": true,
+		"properties": {"children": {"items": {"$recursiveRef": "#"}}}
+	}`
+
+	// Act
+	normalized := decode(t, Normalize([]byte(schema)))
+
+	// Assert
+	assert.Equal(t, "recursive", normalized["$dynamicAnchor"])
+	assert.NotContains(t, normalized, "$recursiveAnchor")
+	assert.Equal(t, map[string]any{"children": map[string]any{"items": map[string]any{"$dynamicRef": "#recursive"}}}, normalized["properties"])
+}
+
+func TestNormalize_KeepsPropertyNamesAndData(t *testing.T) {
+	// Arrange
+	schema := `{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"properties": {"id": {"type": "string"}, "definitions": {"type": "object"}},
+		"default": {"id": "abc", "definitions": {}}
+	}`
+
+	// Act
+	normalized := decode(t, Normalize([]byte(schema)))
+
+	// Assert
+	assert.Equal(t, map[string]any{"id": map[string]any{"type": "string"}, "definitions": map[string]any{"type": "object"}}, normalized["properties"])
+	assert.Equal(t, map[string]any{"id": "abc", "definitions": map[string]any{}}, normalized["default"])
+}
+
+func TestNormalize_Unchanged(t *testing.T) {
+	// Arrange
+	current := []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "definitions": {}}`)
+	invalid := []byte(`{"$schema": "http://json-schema.org/draft-04/schema#"`)
+
+	// Act & Assert
+	assert.Equal(t, current, Normalize(current))
+	assert.Equal(t, invalid, Normalize(invalid))
+}
+
+func TestParser_Schemas_Draft07(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"product.json": {Data: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$id": "file:///product.json",
+			"title": "Product",
+			"type": "object",
+			"properties": {"price": {"$ref": "price.json#/definitions/price"}}
+		}`)},
+		"price.json": {Data: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$id": "file:///price.json",
+			"definitions": {"price": {"title": "Price", "type": "object"}}
+		}`)},
+	}
+	parser := NewParser("product.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	// Act
+	classes, err := parser.Classes()
+
+	// Assert
+	require.NoError(t, err)
+	var names []string
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	assert.Subset(t, names, []string{"Product", "Price"})
+}
+
+func TestNormalize_FragmentID(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		expected map[string]any
+	}{
+		"draft-07": {
+			schema: `{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {"address": {"$id": "#address"}}}`,
+			expected: map[string]any{
+				"$schema": Draft202012Schema,
+				"$defs":   map[string]any{"address": map[string]any{"$anchor": "address"}},
+			},
+		},
+		"draft-04": {
+			schema: `{"$schema": "http://json-schema.org/draft-04/schema#", "definitions": {"address": {"id": "#address"}}}`,
+			expected: map[string]any{
+				"$schema": Draft202012Schema,
+				"$defs":   map[string]any{"address": map[string]any{"$anchor": "address"}},
+			},
+		},
+		"base and fragment": {
+			schema: `{"$schema": "http://json-schema.org/draft-06/schema#", "$id": "file:///address.json#address"}`,
+			expected: map[string]any{
+				"$schema": Draft202012Schema,
+				"$id":     "file:///address.json",
+				"$anchor": "address",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			normalized := decode(t, Normalize([]byte(tt.schema)))
+
+			// Assert
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestNormalize_DefinitionsOfOtherDocument(t *testing.T) {
+	// Arrange
+	schema := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"properties": {
+			"price": {"$ref": "price.json#/definitions/price"},
+			"local": {"$ref": "#/definitions/local"}
+		}
+	}`
+
+	// Act
+	normalized := decode(t, Normalize([]byte(schema)))
+
+	// Assert
+	assert.Equal(t, map[string]any{
+		"price": map[string]any{"$ref": "price.json#/$defs/price"},
+		"local": map[string]any{"$ref": "#/definitions/local"},
+	}, normalized["properties"])
+}
+
+func TestParser_Schemas_Draft07FragmentID(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"person.json": {Data: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$id": "file:///person.json",
+			"title": "Person",
+			"type": "object",
+			"properties": {"home": {"$ref": "#address"}},
+			"definitions": {"address": {"$id": "#address", "title": "Address", "type": "object"}}
+		}`)},
+	}
+	parser := NewParser("person.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	// Act
+	classes, err := parser.Classes()
+
+	// Assert
+	require.NoError(t, err)
+	var names []string
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	assert.ElementsMatch(t, []string{"Person", "Address"}, names)
+}
+
+func TestParser_Schemas_DefinitionsOfDraft07Document(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"product.json": {Data: []byte(`{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "file:///product.json",
+			"title": "Product",
+			"type": "object",
+			"properties": {"price": {"$ref": "price.json#/definitions/price"}}
+		}`)},
+		"price.json": {Data: []byte(`{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$id": "file:///price.json",
+			"definitions": {"price": {"title": "Price", "type": "object"}}
+		}`)},
+	}
+	parser := NewParser("product.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	// Act
+	classes, err := parser.Classes()
+
+	// Assert
+	require.NoError(t, err)
+	var names []string
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	assert.Subset(t, names, []string{"Product", "Price"})
+}
+
+func decode(t *testing.T, contents []byte) map[string]any {
+	t.Helper()
+
+	var res map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&res))
+
+	return res
+}
@@ -0,0 +1,148 @@
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+)
+
+// ErrUnknownClass is returned when no domain.Class matches a query
+var ErrUnknownClass = errors.New("unknown class")
+
+// Dependent domain.Class that (transitively) references one of the targets of Dependents
+type Dependent struct {
+	// Class that depends on the target
+	Class *domain.Class
+
+	// Path of domain.Relation's from the Class to the target (the shortest one if multiple exist)
+	Path []*domain.Relation
+}
+
+// FindClasses matching the query by (in order of precedence) the $id of its jsonschema.Schema, the file path of its
+// domain.Source or its name (title)
+func FindClasses(classes []*domain.Class, query string) ([]*domain.Class, error) {
+	matchers := []func(class *domain.Class) bool{
+		func(class *domain.Class) bool {
+			return class.Schema != nil && (class.Schema.ID == query || class.Schema.GetSchemaURI() == query)
+		},
+		func(class *domain.Class) bool {
+			return class.Source != nil && samePath(class.Path(), query)
+		},
+		func(class *domain.Class) bool {
+			return class.Name == query
+		},
+	}
+
+	for _, matches := range matchers {
+		var res []*domain.Class
+		for _, class := range classes {
+			if matches(class) {
+				res = append(res, class)
+			}
+		}
+
+		if len(res) > 0 {
+			return res, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownClass, query)
+}
+
+// samePath returns true iff the source path (possibly a 'file://' URI) and the file path refer to the same file
+func samePath(source string, file string) bool {
+	if !strings.HasPrefix(source, "file://") {
+		return false
+	}
+
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return false
+	}
+
+	return filepath.Clean(strings.TrimPrefix(source, "file://")) == file
+}
+
+// Dependents of the targets: every domain.Class that (transitively) references one of the targets together with the
+// shortest path of domain.Relation's towards it. Dependents are ordered by the length of their Path and then in the
+// order of the classes slice
+func Dependents(classes []*domain.Class, relations []*domain.Relation, targets ...*domain.Class) []*Dependent {
+	incoming := map[*domain.Class][]*domain.Relation{}
+	for _, relation := range relations {
+		incoming[relation.To] = append(incoming[relation.To], relation)
+	}
+
+	// breadth first over the reversed relations, such that the first path found is the shortest
+	paths := map[*domain.Class][]*domain.Relation{}
+	for _, target := range targets {
+		paths[target] = nil
+	}
+
+	queue := slices.Clone(targets)
+	var found []*domain.Class
+	for len(queue) > 0 {
+		class := queue[0]
+		queue = queue[1:]
+
+		for _, relation := range incoming[class] {
+			if _, ok := paths[relation.From]; ok {
+				continue
+			}
+
+			paths[relation.From] = append([]*domain.Relation{relation}, paths[class]...)
+			found = append(found, relation.From)
+			queue = append(queue, relation.From)
+		}
+	}
+
+	position := map[*domain.Class]int{}
+	for i, class := range classes {
+		position[class] = i
+	}
+
+	slices.SortStableFunc(found, func(a, b *domain.Class) int {
+		if len(paths[a]) != len(paths[b]) {
+			return len(paths[a]) - len(paths[b])
+		}
+
+		return position[a] - position[b]
+	})
+
+	res := make([]*Dependent, 0, len(found))
+	for _, class := range found {
+		res = append(res, &Dependent{Class: class, Path: paths[class]})
+	}
+
+	return res
+}
+
+// Subgraph of the targets and their dependents: the domain.Class'es and the domain.Relation's between them
+func Subgraph(classes []*domain.Class, relations []*domain.Relation, targets ...*domain.Class) ([]*domain.Class, []*domain.Relation) {
+	include := map[*domain.Class]bool{}
+	for _, target := range targets {
+		include[target] = true
+	}
+	for _, dependent := range Dependents(classes, relations, targets...) {
+		include[dependent.Class] = true
+	}
+
+	var subClasses []*domain.Class
+	for _, class := range classes {
+		if include[class] {
+			subClasses = append(subClasses, class)
+		}
+	}
+
+	var subRelations []*domain.Relation
+	for _, relation := range relations {
+		if include[relation.From] && include[relation.To] {
+			subRelations = append(subRelations, relation)
+		}
+	}
+
+	return subClasses, subRelations
+}
@@ -0,0 +1,56 @@
+package parse
+
+import "github.com/Emptyless/jsonschema-transform/domain"
+
+// Neighbourhood of the targets: the domain.Class'es reachable from a target within depth domain.Relation's in either
+// direction (or all reachable classes if depth is negative) and the domain.Relation's between them. Classes and
+// relations keep the order of the classes and relations slices
+func Neighbourhood(classes []*domain.Class, relations []*domain.Relation, depth int, targets ...*domain.Class) ([]*domain.Class, []*domain.Relation) {
+	adjacent := map[*domain.Class][]*domain.Class{}
+	for _, relation := range relations {
+		adjacent[relation.From] = append(adjacent[relation.From], relation.To)
+		adjacent[relation.To] = append(adjacent[relation.To], relation.From)
+	}
+
+	// breadth first such that each class is reached at its shortest distance
+	distance := map[*domain.Class]int{}
+	for _, target := range targets {
+		distance[target] = 0
+	}
+
+	queue := append([]*domain.Class{}, targets...)
+	for len(queue) > 0 {
+		class := queue[0]
+		queue = queue[1:]
+		if depth >= 0 && distance[class] >= depth {
+			continue
+		}
+
+		for _, next := range adjacent[class] {
+			if _, ok := distance[next]; ok {
+				continue
+			}
+
+			distance[next] = distance[class] + 1
+			queue = append(queue, next)
+		}
+	}
+
+	var subClasses []*domain.Class
+	for _, class := range classes {
+		if _, ok := distance[class]; ok {
+			subClasses = append(subClasses, class)
+		}
+	}
+
+	var subRelations []*domain.Relation
+	for _, relation := range relations {
+		_, from := distance[relation.From]
+		_, to := distance[relation.To]
+		if from && to {
+			subRelations = append(subRelations, relation)
+		}
+	}
+
+	return subClasses, subRelations
+}
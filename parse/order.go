@@ -0,0 +1,66 @@
+package parse
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+)
+
+// SortClasses in their canonical order by the path of their source and then by name. Classes that compare equal keep
+// their order
+func SortClasses(classes []*domain.Class) {
+	slices.SortStableFunc(classes, CompareClasses)
+}
+
+// SortRelations in their canonical order by the class and property they originate from, then by the class and
+// property they point to and finally by their type. Relations that compare equal keep their order
+func SortRelations(relations []*domain.Relation) {
+	slices.SortStableFunc(relations, CompareRelations)
+}
+
+// CompareClasses by the path of their source and then by name
+func CompareClasses(a *domain.Class, b *domain.Class) int {
+	return cmp.Or(
+		cmp.Compare(sourcePath(a), sourcePath(b)),
+		cmp.Compare(className(a), className(b)),
+	)
+}
+
+// CompareRelations by the class and property they originate from, the class and property they point to and their type
+func CompareRelations(a *domain.Relation, b *domain.Relation) int {
+	return cmp.Or(
+		CompareClasses(a.From, b.From),
+		cmp.Compare(propertyName(a.FromProperty), propertyName(b.FromProperty)),
+		CompareClasses(a.To, b.To),
+		cmp.Compare(propertyName(a.ToProperty), propertyName(b.ToProperty)),
+		cmp.Compare(a.Type, b.Type),
+	)
+}
+
+// sourcePath of the class or an empty string if it has no source
+func sourcePath(class *domain.Class) string {
+	if class == nil || class.Source == nil {
+		return ""
+	}
+
+	return class.Path()
+}
+
+// className of the class or an empty string if nil
+func className(class *domain.Class) string {
+	if class == nil {
+		return ""
+	}
+
+	return class.Name
+}
+
+// propertyName of the property or an empty string if nil
+func propertyName(property *domain.Property) string {
+	if property == nil {
+		return ""
+	}
+
+	return property.Name
+}
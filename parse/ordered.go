@@ -0,0 +1,165 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrDecodingDocument is returned when a document is not a single valid JSON value
+var ErrDecodingDocument = errors.New("could not decode document")
+
+// Object is a JSON object that preserves the order of its keys, e.g. to rewrite a schema without reordering it
+type Object struct {
+	keys   []string
+	values map[string]any
+}
+
+// Keys of the Object in order
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Get the value of the key
+func (o *Object) Get(key string) (any, bool) {
+	value, ok := o.values[key]
+
+	return value, ok
+}
+
+// Set the value of the key, a new key is appended to the Object
+func (o *Object) Set(key string, value any) {
+	if o.values == nil {
+		o.values = map[string]any{}
+	}
+
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete the key
+func (o *Object) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+
+	delete(o.values, key)
+	o.keys = slices.DeleteFunc(o.keys, func(k string) bool { return k == key })
+}
+
+// Rename the key from to the key to keeping its position, an existing key to is replaced
+func (o *Object) Rename(from string, to string) {
+	value, ok := o.values[from]
+	if !ok {
+		return
+	}
+
+	o.Delete(to)
+	delete(o.values, from)
+	o.keys[slices.Index(o.keys, from)] = to
+	o.values[to] = value
+}
+
+// MarshalJSON of the Object with its keys in order
+func (o *Object) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		encodedKey, err := EncodeOrdered(key)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedValue, err := EncodeOrdered(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(encodedKey)
+		buffer.WriteByte(':')
+		buffer.Write(encodedValue)
+	}
+	buffer.WriteByte('}')
+
+	return buffer.Bytes(), nil
+}
+
+// DecodeOrdered document where objects are decoded to an *Object, arrays to []any and numbers to json.Number such that
+// encoding it again (see EncodeOrdered) yields the same document
+func DecodeOrdered(contents []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, errors.Join(ErrDecodingDocument, err)
+	}
+
+	if _, err = decoder.Token(); err == nil {
+		return nil, fmt.Errorf("%w: unexpected data after the document", ErrDecodingDocument)
+	}
+
+	return value, nil
+}
+
+// EncodeOrdered value (compact) without escaping HTML characters
+func EncodeOrdered(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// decodeValue of the next token(s) of the decoder
+func decodeValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := &Object{values: map[string]any{}}
+		for decoder.More() {
+			key, keyErr := decoder.Token()
+			if keyErr != nil {
+				return nil, keyErr
+			}
+
+			value, valueErr := decodeValue(decoder)
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			object.Set(key.(string), value)
+		}
+
+		_, err = decoder.Token() // '}'
+		return object, err
+	case json.Delim('['):
+		array := []any{}
+		for decoder.More() {
+			value, valueErr := decodeValue(decoder)
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			array = append(array, value)
+		}
+
+		_, err = decoder.Token() // ']'
+		return array, err
+	default:
+		return token, nil
+	}
+}
@@ -12,6 +12,9 @@ import (
 	"github.com/kaptinlin/jsonschema"
 )
 
+// ErrParserFailure is returned when there is some failure by the parser
+var ErrParserFailure = errors.New("failed to parse classes or relations")
+
 // ErrUnknownSchema is returned when the schema is not resolved or found
 var ErrUnknownSchema = errors.New("unknown schema")
 
@@ -33,6 +36,9 @@ type ClassParser struct {
 
 	// amount of anonymous schemas
 	anonymous int
+
+	// variants of conditional subschemas by their schema (see conditionals)
+	variants map[*jsonschema.Schema]*variant
 }
 
 // Classes returns the parsed Class slice that can be used by transformations
@@ -63,7 +69,11 @@ func (p *ClassParser) Classes() ([]*domain.Class, error) {
 		p.queue = p.queue[1:]
 
 		// track processed
-		if p.Cache.HasProcessed(schema) {
+		if v, ok := p.variants[schema]; ok && v.processed {
+			continue
+		} else if ok {
+			v.processed = true
+		} else if p.Cache.HasProcessed(schema) {
 			continue
 		} else {
 			p.Cache.Process(schema)
@@ -74,8 +84,11 @@ func (p *ClassParser) Classes() ([]*domain.Class, error) {
 			return nil, classErr
 		}
 
-		// Add a source
+		// Add a source, a variant is sourced from the document of its base
 		source := schema.GetSchemaURI()
+		if v, ok := p.variants[schema]; ok {
+			source = v.base.GetSchemaURI()
+		}
 		if p.BaseURI != "" {
 			file := regexp.MustCompile("(file:/?/?)/")
 
@@ -99,9 +112,9 @@ func (p *ClassParser) Classes() ([]*domain.Class, error) {
 
 		depthMap := DepthMap(schemas, p.classes, relations)
 		classes := []*domain.Class{}
-		for k, v := range depthMap {
-			if v <= p.Parser.Depth {
-				classes = append(classes, k)
+		for _, class := range p.classes {
+			if depth, ok := depthMap[class]; ok && depth != Unreachable && depth <= p.Parser.Depth {
+				classes = append(classes, class)
 			}
 		}
 
@@ -109,6 +122,8 @@ func (p *ClassParser) Classes() ([]*domain.Class, error) {
 		p.relations = nil // reset relations to recalculate
 	}
 
+	SortClasses(p.classes)
+
 	return p.classes, nil
 }
 
@@ -144,12 +159,10 @@ func (p *ClassParser) Relations() ([]*domain.Relation, error) {
 		for _, class := range p.classes {
 			if reference.FromParent == class.Schema {
 				from = class
-				continue
 			}
 
 			if reference.ToParent == class.Schema {
 				to = class
-				continue
 			}
 
 			if from != nil && to != nil {
@@ -165,14 +178,24 @@ func (p *ClassParser) Relations() ([]*domain.Relation, error) {
 			return nil, fmt.Errorf("one end of the relation '%s'(%s) to '%s' is missing", reference.FromParent.ID, reference.From.Ref, reference.ToParent.ID)
 		}
 
-		p.relations = append(p.relations, &domain.Relation{
-			Type: string(reference.Type),
-			From: from,
-			To:   to,
-		})
+		relation := &domain.Relation{
+			Type:         string(reference.Type),
+			FromProperty: findProperty(from, func(property *domain.Property) bool { return property.Name == reference.Property }),
+			From:         from,
+			ToProperty:   findProperty(to, func(property *domain.Property) bool { return property.Schema == reference.To }),
+			To:           to,
+		}
 
+		// a property can reference the same schema more than once, e.g. a tuple with multiple items of the same type
+		if slices.ContainsFunc(p.relations, func(r *domain.Relation) bool { return *r == *relation }) {
+			continue
+		}
+
+		p.relations = append(p.relations, relation)
 	}
 
+	SortRelations(p.relations)
+
 	return p.relations, nil
 }
 
@@ -184,6 +207,8 @@ func (p *ClassParser) NewClass(schema *jsonschema.Schema) (*domain.Class, error)
 
 	if title := schema.Title; title != nil {
 		class.Name = *title
+	} else if v, ok := p.variants[schema]; ok {
+		class.Name = v.name
 	} else {
 		// track amount of anonymous classes
 		class.Name = strings.Repeat(" ", p.anonymous+1)
@@ -208,25 +233,64 @@ func (p *ClassParser) NewClass(schema *jsonschema.Schema) (*domain.Class, error)
 		}
 	}
 
+	// properties not named by 'properties' are added as '[pattern]' and '[string]'
+	keys, values := extraProperties(schema)
+	for i, key := range keys {
+		name := "[" + key + "]"
+		property, propertyErr := p.NewProperty(schema, name, values[i])
+		if propertyErr != nil {
+			return nil, fmt.Errorf("failed to parse property %s for class %s: %w", name, class.Name, propertyErr)
+		}
+
+		property.Parent = &class
+		class.Properties = append(class.Properties, property)
+	}
+
+	if additional := schema.AdditionalProperties; additional != nil && additional.Boolean != nil {
+		class.Closed = !*additional.Boolean
+	}
+
+	if err := p.conditionals(&class, schema); err != nil {
+		return nil, fmt.Errorf("failed to parse conditionals for class %s: %w", class.Name, err)
+	}
+
 	return &class, nil
 }
 
 // NewProperty for a Class based on its property jsonschema.Schema
 func (p *ClassParser) NewProperty(parent *jsonschema.Schema, name string, value *jsonschema.Schema) (*domain.Property, error) {
 	property := domain.Property{
-		Name: name,
+		Schema: value,
+		Name:   name,
+	}
+
+	// boolean schemas allow any value (true) or forbid the property (false)
+	if value.Boolean != nil {
+		property.Type = "never"
+		if *value.Boolean {
+			property.Type = "any"
+		}
+
+		return &property, nil
 	}
 
-	if value.Ref != "" || value.DynamicRef != "" {
-		resolvedRef, resolvedRefErr := p.PropertyRef(parent, value)
+	referenced := value.Ref != "" || value.DynamicRef != ""
+	if referenced {
+		resolvedRef, resolvedRefErr := p.PropertyRef(parent, name, value)
 		if resolvedRefErr != nil {
 			return nil, resolvedRefErr
 		}
 		value = resolvedRef
 	}
 
-	property.Type = first(value.Type)
-	if property.Type == "object" && value.ResolvedRef != nil {
+	property.Type = primaryType(value.Type)
+	if !referenced && isMap(value) {
+		mapType, err := p.mapType(parent, name, value)
+		if err != nil {
+			return nil, err
+		}
+		property.Type = mapType
+	} else if property.Type == "object" && value.ResolvedRef != nil {
 		if title := value.ResolvedRef.Title; title != nil {
 			property.Type = *title
 		}
@@ -240,30 +304,41 @@ func (p *ClassParser) NewProperty(parent *jsonschema.Schema, name string, value
 		if title := value.Title; title != nil {
 			property.Type = *title
 		}
-		// must be inline property at this point
-		if !p.Cache.HasProcessed(value) {
-			// add resolvedRefParent to queue for processing
-			p.queue = append(p.queue, value)
-		}
 
-		p.references = append(p.references, &Reference{
-			Type:       ReferenceType(name),
-			FromParent: parent,
-			ToParent:   value,
-		})
+		// must be inline property at this point, a resolved $ref is already related in PropertyRef
+		if !referenced {
+			if !p.Cache.HasProcessed(value) {
+				// add resolvedRefParent to queue for processing
+				p.queue = append(p.queue, value)
+			}
+
+			p.references = append(p.references, &Reference{
+				Type:       ReferenceType(name),
+				Property:   name,
+				FromParent: parent,
+				ToParent:   value,
+			})
+		}
 	}
 
 	// if Type is "array" (and thus has "items", use the type of "items")
-	if items := value.Items; items != nil {
+	if items := value.Items; items != nil && items.Boolean == nil && len(value.PrefixItems) == 0 && value.Contains == nil {
 		item, err := p.NewProperty(parent, name, items)
 		if err != nil {
 			return nil, err
 		}
 
-		item.Type = fmt.Sprintf("[]%s", item.Type)
+		item.Type = unionType(fmt.Sprintf("[]%s", item.Type), value.Type)
 		return item, nil
 	}
 
+	// tuples ('prefixItems') and arrays described by 'contains' or 'unevaluatedItems'
+	if arrayType, err := p.arrayType(parent, name, value); err != nil {
+		return nil, err
+	} else if arrayType != "" {
+		property.Type = arrayType
+	}
+
 	// if the value is OneOf; process the OneOf as a oneOf type
 	if oneOf := value.OneOf; len(oneOf) > 0 {
 		var items []*domain.Property
@@ -295,11 +370,180 @@ func (p *ClassParser) NewProperty(parent *jsonschema.Schema, name string, value
 		property.Docstring = *description
 	}
 
+	property.Type = unionType(property.Type, value.Type)
+
 	return &property, nil
 }
 
-// PropertyRef handles properties which are defined with a $ref (possibly with an anchor '#")
-func (p *ClassParser) PropertyRef(parent *jsonschema.Schema, value *jsonschema.Schema) (*jsonschema.Schema, error) {
+// arrayType of a tuple as '[string, integer, ...Item]' where the rest is described by 'items' or 'unevaluatedItems'
+// (or '...' if any item is allowed), or of an array described by 'unevaluatedItems' as '[]Item'. If the array has
+// 'contains' the type is followed by 'contains[Item]'. Returns "" for any other schema
+func (p *ClassParser) arrayType(parent *jsonschema.Schema, name string, value *jsonschema.Schema) (string, error) {
+	rest, closed := value.Items, false
+	if rest == nil {
+		rest = value.UnevaluatedItems
+	}
+	if rest != nil && rest.Boolean != nil {
+		rest, closed = nil, !*rest.Boolean
+	}
+
+	var res string
+	if len(value.PrefixItems) > 0 {
+		var types []string
+		for _, item := range value.PrefixItems {
+			itemType, err := p.elementType(parent, name, item)
+			if err != nil {
+				return "", err
+			}
+			types = append(types, itemType)
+		}
+
+		if rest != nil {
+			restType, err := p.elementType(parent, name, rest)
+			if err != nil {
+				return "", err
+			}
+			types = append(types, "..."+restType)
+		} else if !closed {
+			types = append(types, "...")
+		}
+
+		res = fmt.Sprintf("[%s]", strings.Join(types, ", "))
+	} else if rest != nil {
+		restType, err := p.elementType(parent, name, rest)
+		if err != nil {
+			return "", err
+		}
+		res = fmt.Sprintf("[]%s", restType)
+	}
+
+	if contains := value.Contains; contains != nil && contains.Boolean == nil {
+		containsType, err := p.elementType(parent, name, contains)
+		if err != nil {
+			return "", err
+		}
+
+		if res != "" {
+			res += ","
+		}
+		res += fmt.Sprintf("contains[%s]", containsType)
+	}
+
+	return res, nil
+}
+
+// elementType of an array element schema (see NewProperty), 'any' if the schema does not restrict the type
+func (p *ClassParser) elementType(parent *jsonschema.Schema, name string, schema *jsonschema.Schema) (string, error) {
+	if schema.Boolean != nil {
+		return "any", nil
+	}
+
+	element, err := p.NewProperty(parent, name, schema)
+	if err != nil {
+		return "", err
+	}
+
+	if element.Type == "" {
+		return "any", nil
+	}
+
+	return element.Type, nil
+}
+
+// primaryType of the 'type' keyword, i.e. the first type that is not 'null' (or 'null' if it is the only type)
+func primaryType(types jsonschema.SchemaType) string {
+	for _, t := range types {
+		if t != "null" {
+			return t
+		}
+	}
+
+	return first(types)
+}
+
+// unionType of the 'type' keyword where typ replaces the primaryType, e.g. 'string?' for ["string", "null"] and
+// 'string|integer' for ["string", "integer"]
+func unionType(typ string, types jsonschema.SchemaType) string {
+	var others []string
+	nullable := false
+	for _, t := range types {
+		if t == "null" {
+			nullable = true
+		} else if t != primaryType(types) {
+			others = append(others, t)
+		}
+	}
+
+	if len(others) == 0 && (!nullable || primaryType(types) == "null") {
+		return typ
+	}
+
+	parts := append([]string{typ}, others...)
+	if nullable && len(parts) == 1 {
+		return typ + "?"
+	} else if nullable {
+		parts = append(parts, "null")
+	}
+
+	return strings.Join(parts, "|")
+}
+
+// isMap returns true iff the schema is an object without 'properties' that describes its values by
+// 'additionalProperties' or 'patternProperties', e.g. {"type": "object", "additionalProperties": {"$ref": "item.json"}}
+func isMap(schema *jsonschema.Schema) bool {
+	if schema.Properties != nil && len(*schema.Properties) > 0 {
+		return false
+	}
+
+	keys, _ := extraProperties(schema)
+
+	return len(keys) > 0
+}
+
+// mapType of a map schema (see isMap) as 'map[pattern]T' for every entry of 'patternProperties' followed by
+// 'map[string]T' for 'additionalProperties', where the value schemas are referenced from the property name
+func (p *ClassParser) mapType(parent *jsonschema.Schema, name string, value *jsonschema.Schema) (string, error) {
+	keys, values := extraProperties(value)
+	var types []string
+	for i, key := range keys {
+		item, err := p.NewProperty(parent, name, values[i])
+		if err != nil {
+			return "", err
+		}
+
+		if item.Type == "" {
+			item.Type = "any"
+		}
+		types = append(types, fmt.Sprintf("map[%s]%s", key, item.Type))
+	}
+
+	return strings.Join(types, ","), nil
+}
+
+// extraProperties of the schema that are not named by 'properties': the sorted patterns of 'patternProperties'
+// followed by 'string' for a schema of 'additionalProperties', with the schemas of their values
+func extraProperties(schema *jsonschema.Schema) ([]string, []*jsonschema.Schema) {
+	var keys []string
+	var values []*jsonschema.Schema
+	if patternProperties := schema.PatternProperties; patternProperties != nil {
+		for _, pattern := range slices.Sorted(maps.Keys(*patternProperties)) {
+			keys = append(keys, pattern)
+			values = append(values, (*patternProperties)[pattern])
+		}
+	}
+
+	if additional := schema.AdditionalProperties; additional != nil && additional.Boolean == nil {
+		keys = append(keys, "string")
+		values = append(values, additional)
+	}
+
+	return keys, values
+}
+
+// PropertyRef handles properties which are defined with a $ref (possibly with an anchor '#"). Every relation of a $ref
+// has the Ref type: a resolved schema that is a Class of its own (see isClass) is related to that Class, any other
+// resolved schema to the Class of its document unless it is defined in the document of the parent, e.g. '#/$defs/id'
+func (p *ClassParser) PropertyRef(parent *jsonschema.Schema, name string, value *jsonschema.Schema) (*jsonschema.Schema, error) {
 	var resolvedRef *jsonschema.Schema
 	if v := value.ResolvedRef; v != nil {
 		resolvedRef = v
@@ -309,11 +553,32 @@ func (p *ClassParser) PropertyRef(parent *jsonschema.Schema, value *jsonschema.S
 		return nil, fmt.Errorf("$ref '%s' (or $dynamicRef '%s') could not be resolved: %w", value.Ref, value.DynamicRef, ErrUnknownSchema)
 	}
 
+	if isClass(resolvedRef) {
+		if !p.Cache.HasProcessed(resolvedRef) {
+			p.queue = append(p.queue, resolvedRef)
+		}
+
+		p.references = append(p.references, &Reference{
+			Type:       Ref,
+			Property:   name,
+			From:       value,
+			FromParent: parent,
+			To:         resolvedRef,
+			ToParent:   resolvedRef,
+		})
+
+		return resolvedRef, nil
+	}
+
 	resolvedRefParent, getSchemaErr := p.Parser.Compiler.GetSchema(resolvedRef.GetSchemaURI())
 	if getSchemaErr != nil {
 		return nil, fmt.Errorf("parent of $ref '%s' failed to load: %w", resolvedRef.GetSchemaURI(), getSchemaErr)
 	}
 
+	if resolvedRef != resolvedRefParent && resolvedRefParent.GetSchemaURI() == parent.GetSchemaURI() {
+		return resolvedRef, nil
+	}
+
 	// if not already processed, process the parent
 	if !p.Cache.HasProcessed(resolvedRefParent) {
 		// add resolvedRefParent to queue for processing
@@ -323,6 +588,7 @@ func (p *ClassParser) PropertyRef(parent *jsonschema.Schema, value *jsonschema.S
 	// add reference to references
 	p.references = append(p.references, &Reference{
 		Type:       Ref,
+		Property:   name,
 		From:       value,
 		FromParent: parent,
 		To:         resolvedRef,
@@ -332,6 +598,22 @@ func (p *ClassParser) PropertyRef(parent *jsonschema.Schema, value *jsonschema.S
 	return resolvedRef, nil
 }
 
+// isClass returns true iff a resolved $ref is parsed as a Class of its own, i.e. an object that is not a $ref itself
+func isClass(schema *jsonschema.Schema) bool {
+	return primaryType(schema.Type) == "object" && schema.Ref == "" && schema.DynamicRef == ""
+}
+
+// findProperty of a domain.Class matching fn or nil if not found
+func findProperty(class *domain.Class, fn func(property *domain.Property) bool) *domain.Property {
+	for _, property := range class.Properties {
+		if fn(property) {
+			return property
+		}
+	}
+
+	return nil
+}
+
 // first element of slice
 func first[T any](input []T) T {
 	if len(input) > 0 {
@@ -0,0 +1,41 @@
+// Package parsetest provides a Parser returning fixed schemas, classes and relations to test the packages that build
+// on a parser
+package parsetest
+
+import (
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Parser returns the SchemaData, ClassData and RelationsData or the corresponding error if set
+type Parser struct {
+	SchemaData    []*jsonschema.Schema
+	ClassData     []*domain.Class
+	RelationsData []*domain.Relation
+
+	ClassesError   error
+	RelationsError error
+}
+
+// Schemas returns the SchemaData
+func (p *Parser) Schemas() ([]*jsonschema.Schema, error) {
+	return p.SchemaData, nil
+}
+
+// Classes returns the ClassData or the ClassesError if set
+func (p *Parser) Classes() ([]*domain.Class, error) {
+	if err := p.ClassesError; err != nil {
+		return nil, err
+	}
+
+	return p.ClassData, nil
+}
+
+// Relations returns the RelationsData or the RelationsError if set
+func (p *Parser) Relations() ([]*domain.Relation, error) {
+	if err := p.RelationsError; err != nil {
+		return nil, err
+	}
+
+	return p.RelationsData, nil
+}
@@ -0,0 +1,98 @@
+package parse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// ErrInvalidJSON is returned when a file does not contain valid JSON
+var ErrInvalidJSON = errors.New(`invalid json`)
+
+// file read (and pre-parsed) by readFiles
+type file struct {
+	// Name of the file
+	Name string
+
+	// Contents of the file normalized to draft 2020-12 (see Normalize) if it could be read and contains valid JSON
+	Contents []byte
+
+	// URL the Contents are served under by the loaders (see Parser.preloading), empty if not served
+	URL string
+
+	// ReadErr if the file could not be read
+	ReadErr error
+
+	// ParseErr if the file does not contain valid JSON
+	ParseErr error
+}
+
+// readFiles concurrently using jobs workers (or the number of CPUs if jobs <= 0). The files are returned in the order
+// of names regardless of the order in which they were read
+func (p *Parser) readFiles(names []string, jobs int) []*file {
+	if jobs <= 0 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	jobs = min(jobs, len(names))
+
+	files := make([]*file, len(names))
+	indices := make(chan int)
+
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				files[i] = p.readFile(names[i])
+			}
+		}()
+	}
+
+	for i := range names {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return files
+}
+
+// readFile from disk (or from the FS if set), normalize it and check that it contains valid JSON such that invalid
+// files are reported before compiling any schema. As the Compiler is not safe for concurrent use, the work that does
+// not need it is done here
+func (p *Parser) readFile(name string) *file {
+	f := &file{Name: name}
+	if p.FS != nil {
+		f.Contents, f.ReadErr = fs.ReadFile(p.FS, name)
+	} else {
+		f.Contents, f.ReadErr = os.ReadFile(name)
+	}
+
+	if f.ReadErr != nil {
+		return f
+	}
+
+	f.Contents = Normalize(f.Contents)
+	if !json.Valid(f.Contents) {
+		f.ParseErr = ErrInvalidJSON
+	}
+
+	return f
+}
+
+// Err of the file (if any) wrapped with its name
+func (f *file) Err() error {
+	switch {
+	case f.ReadErr != nil:
+		return fmt.Errorf("%s: %w", f.Name, errors.Join(ErrReadFile, f.ReadErr))
+	case f.ParseErr != nil:
+		return fmt.Errorf("%s: %w", f.Name, errors.Join(ErrParsingSchema, f.ParseErr))
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,129 @@
+package parse
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParser_Schemas_Order(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	for i := range 50 {
+		schema := fmt.Sprintf(`{"$id": "file:///schema%02d.json", "title": "Schema%02d", "type": "object"}`, i, i)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("schema%02d.json", i)), []byte(schema), 0o644))
+	}
+	parser := NewParser(filepath.Join(dir, "*.json")).SetBaseURI("file://" + dir).SetJobs(8)
+
+	// Act
+	schemas, err := parser.Schemas()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, schemas, 50)
+	for i, schema := range schemas {
+		assert.Equal(t, fmt.Sprintf("Schema%02d", i), *schema.Title)
+	}
+}
+
+func TestParser_Schemas_AggregatesErrors(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"$id": "file:///a.json"`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"$id": "file:///b.json"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.json"), []byte(`[`), 0o644))
+	parser := NewParser(filepath.Join(dir, "*.json")).SetBaseURI("file://" + dir).SetJobs(2)
+	parser.StrictMode = true
+
+	// Act
+	_, err := parser.Schemas()
+
+	// Assert
+	require.ErrorIs(t, err, ErrParsingSchema)
+	require.ErrorIs(t, err, ErrInvalidJSON)
+	assert.Contains(t, err.Error(), "a.json")
+	assert.Contains(t, err.Error(), "c.json")
+	assert.NotContains(t, err.Error(), "b.json")
+}
+
+func TestParser_Schemas_ReadsOnce(t *testing.T) {
+	// Arrange
+	fsys := &openCounter{FS: fstest.MapFS{
+		"a.json": {Data: []byte(`{"$id": "file:///a.json", "title": "A", "type": "object", "properties": {"b": {"$ref": "b.json"}}}`)},
+		"b.json": {Data: []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "file:///b.json", "title": "B", "type": "object", "definitions": {"c": {"type": "string"}}, "properties": {"c": {"$ref": "#/definitions/c"}}}`)},
+	}, opened: map[string]int{}}
+	parser := NewParser("*.json").SetFS(fsys)
+	parser.StrictMode = true
+
+	// Act
+	schemas, err := parser.Schemas()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, schemas, 2)
+	assert.Equal(t, "B", *schemas[1].Title)
+	assert.Equal(t, map[string]int{"a.json": 1, "b.json": 1}, fsys.opened)
+}
+
+// openCounter counts the number of times each JSON file of the FS is opened
+type openCounter struct {
+	fs.FS
+	opened map[string]int
+}
+
+// Open the file and count it
+func (c *openCounter) Open(name string) (fs.File, error) {
+	if strings.HasSuffix(name, ".json") {
+		c.opened[name]++
+	}
+
+	return c.FS.Open(name)
+}
+
+// BenchmarkParser_Schemas reads and compiles generated schemas sequentially and with multiple workers
+func BenchmarkParser_Schemas(b *testing.B) {
+	logrus.SetLevel(logrus.WarnLevel)
+
+	for _, size := range []int{100, 500} {
+		dir := generateSchemas(b, size)
+
+		for _, jobs := range []int{1, 8} {
+			b.Run(fmt.Sprintf("jobs=%d/%d", jobs, size), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					parser := NewParser(filepath.Join(dir, "*.json")).SetBaseURI("file://" + dir).SetJobs(jobs)
+
+					schemas, err := parser.Schemas()
+					require.NoError(b, err)
+					require.Len(b, schemas, size)
+				}
+			})
+		}
+	}
+}
+
+func TestReadSchema(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	schema := `{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "file:///a.json", "properties": {"b": {"$ref": "#/definitions/b"}}, "definitions": {"b": {"title": "B"}}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(schema), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.json"), []byte(`{`), 0o644))
+	compiler, err := NewCompiler("file://" + dir)
+	require.NoError(t, err)
+
+	// Act
+	res, err := ReadSchema(compiler, filepath.Join(dir, "a.json"), true)
+	_, invalidErr := ReadSchema(compiler, filepath.Join(dir, "invalid.json"), true)
+
+	// Assert
+	require.NoError(t, err)
+	require.Contains(t, res.Defs, "b")
+	require.ErrorIs(t, invalidErr, ErrInvalidJSON)
+}
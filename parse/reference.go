@@ -7,6 +7,9 @@ type Reference struct {
 	// ReferenceType can be oneOf, $ref, allOf, etc
 	Type ReferenceType
 
+	// Property name from which the Reference originates (if any)
+	Property string
+
 	// From which jsonschema.Schema the relation started, i.e. with $ref
 	From       *jsonschema.Schema
 	FromParent *jsonschema.Schema
@@ -0,0 +1,107 @@
+package parse
+
+import (
+	"slices"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+)
+
+// StronglyConnectedComponents of the directed graph formed by the domain.Relation's between the domain.Class'es using
+// Tarjan's algorithm. Each domain.Class is part of exactly one component, hence a component with more than one
+// domain.Class (or a single domain.Class with a relation to itself) is a cycle. Components are returned in reverse
+// topological order and the domain.Class'es within a component in the order of the classes slice.
+func StronglyConnectedComponents(classes []*domain.Class, relations []*domain.Relation) [][]*domain.Class {
+	position := map[*domain.Class]int{}
+	for i, class := range classes {
+		position[class] = i
+	}
+
+	edges := map[*domain.Class][]*domain.Class{}
+	for _, relation := range relations {
+		edges[relation.From] = append(edges[relation.From], relation.To)
+	}
+
+	t := &tarjan{
+		edges:   edges,
+		index:   map[*domain.Class]int{},
+		lowLink: map[*domain.Class]int{},
+		onStack: map[*domain.Class]bool{},
+	}
+
+	for _, class := range classes {
+		if _, visited := t.index[class]; !visited {
+			t.connect(class)
+		}
+	}
+
+	// order classes within a component by their position in the classes slice
+	for _, component := range t.components {
+		slices.SortFunc(component, func(a, b *domain.Class) int {
+			return position[a] - position[b]
+		})
+	}
+
+	return t.components
+}
+
+// tarjan tracks the state of Tarjan's strongly connected components algorithm
+type tarjan struct {
+	edges      map[*domain.Class][]*domain.Class
+	index      map[*domain.Class]int
+	lowLink    map[*domain.Class]int
+	onStack    map[*domain.Class]bool
+	stack      []*domain.Class
+	counter    int
+	components [][]*domain.Class
+}
+
+// connect visits the class depth first and pops a component off the stack once its root is found
+func (t *tarjan) connect(class *domain.Class) {
+	t.index[class] = t.counter
+	t.lowLink[class] = t.counter
+	t.counter++
+	t.stack = append(t.stack, class)
+	t.onStack[class] = true
+
+	for _, to := range t.edges[class] {
+		if _, visited := t.index[to]; !visited {
+			t.connect(to)
+			t.lowLink[class] = min(t.lowLink[class], t.lowLink[to])
+		} else if t.onStack[to] {
+			t.lowLink[class] = min(t.lowLink[class], t.index[to])
+		}
+	}
+
+	if t.lowLink[class] != t.index[class] {
+		return
+	}
+
+	var component []*domain.Class
+	for {
+		top := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[top] = false
+		component = append(component, top)
+
+		if top == class {
+			break
+		}
+	}
+
+	t.components = append(t.components, component)
+}
+
+// IsCycle returns true iff the component contains more than one domain.Class or a domain.Class with a relation to itself
+func IsCycle(component []*domain.Class, relations []*domain.Relation) bool {
+	if len(component) > 1 {
+		return true
+	}
+
+	for _, relation := range relations {
+		if len(component) == 1 && relation.From == component[0] && relation.To == component[0] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,12 +1,18 @@
 package parse
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/sirupsen/logrus"
 
@@ -22,11 +28,17 @@ var ErrReadFile = errors.New(`cannot read file`)
 // ErrParsingSchema is returned when the jsonschema compiler could not parse the json file
 var ErrParsingSchema = errors.New(`could not parse schema`)
 
+// ErrCyclicLoad is returned by a Loader when the document is requested while it is still being compiled
+var ErrCyclicLoad = errors.New(`document is already being loaded`)
+
 // Parser for .json files to execute transforms
 type Parser struct {
 	// Globs to search for JSON files
 	Globs []string
 
+	// Exclude files matched by the Globs that match one of these patterns (see filepath.Match)
+	Exclude []string
+
 	// StrictMode will error if a JSON file contained in the Globs cannot be parsed to a jsonschema.Schema
 	// default false where its logged as a warning
 	StrictMode bool
@@ -44,8 +56,24 @@ type Parser struct {
 	// Compiler used to load the jsonschema.Schema's
 	Compiler *jsonschema.Compiler
 
+	// Jobs is the number of files read and normalized concurrently, if <= 0 the number of CPUs is used
+	Jobs int
+
+	// FS the Globs are matched against and the schemas are read from, if nil the files are read from disk
+	FS fs.FS
+
 	// classParser used for caching intermediate results
 	classParser *ClassParser
+
+	// files matched by the Globs or loaded from disk by the Compiler (see Files), guarded by filesMu
+	files   map[string]struct{}
+	filesMu sync.Mutex
+
+	// documents by the file they are read from (see Documents), guarded by filesMu
+	documents map[string]string
+
+	// preloaded files read by readFiles by their path (see Parser.preloading)
+	preloaded map[string]*file
 }
 
 // NewParser for glob patterns, e.g. "*", "**/*.json", ...
@@ -60,6 +88,27 @@ func (p *Parser) SetBaseURI(baseURI string) *Parser {
 	return p
 }
 
+// SetExclude patterns of files that are skipped even though they match the Globs
+func (p *Parser) SetExclude(patterns ...string) *Parser {
+	p.Exclude = patterns
+
+	return p
+}
+
+// SetFS from which the schemas (and the files they reference) are read instead of the disk
+func (p *Parser) SetFS(fsys fs.FS) *Parser {
+	p.FS = fsys
+
+	return p
+}
+
+// SetJobs to read the files matched by the Globs with jobs workers
+func (p *Parser) SetJobs(jobs int) *Parser {
+	p.Jobs = jobs
+
+	return p
+}
+
 // SetDepth to only follow $refs that are 'depth' deep
 func (p *Parser) SetDepth(depth int) *Parser {
 	p.Depth = depth
@@ -67,6 +116,100 @@ func (p *Parser) SetDepth(depth int) *Parser {
 	return p
 }
 
+// Reset the Parser such that the next call to Schemas reads the schemas from disk again, e.g. after they have changed.
+// The Cache, Compiler and intermediate results are discarded
+func (p *Parser) Reset() {
+	p.Cache = nil
+	p.Compiler = nil
+	p.classParser = nil
+	p.preloaded = nil
+
+	p.filesMu.Lock()
+	defer p.filesMu.Unlock()
+	p.files = nil
+	p.documents = nil
+}
+
+// Files matched by the Globs or loaded from disk while resolving $refs (see NewFileLoader) as absolute paths in
+// sorted order (or paths relative to the root of the FS if set). Files are only known after Schemas is called
+func (p *Parser) Files() []string {
+	p.filesMu.Lock()
+	defer p.filesMu.Unlock()
+
+	return slices.Sorted(maps.Keys(p.files))
+}
+
+// Track the files as read by the Parser, e.g. when its results are restored from a cache such that the files are
+// still reported by Files
+func (p *Parser) Track(files ...string) {
+	for _, file := range files {
+		p.track(file)
+	}
+}
+
+// track the file as read by the Parser
+func (p *Parser) track(file string) {
+	p.filesMu.Lock()
+	defer p.filesMu.Unlock()
+
+	if p.files == nil {
+		p.files = map[string]struct{}{}
+	}
+	p.files[p.name(file)] = struct{}{}
+}
+
+// read tracks the file as read by the Parser and records the URI of the schema document it contains
+func (p *Parser) read(file string, uri string) {
+	p.track(file)
+
+	p.filesMu.Lock()
+	defer p.filesMu.Unlock()
+
+	if p.documents == nil {
+		p.documents = map[string]string{}
+	}
+	p.documents[p.name(file)] = strings.SplitN(uri, "#", 2)[0]
+}
+
+// name of the file as reported by Files, i.e. its absolute path unless read from the FS
+func (p *Parser) name(file string) string {
+	if p.FS != nil {
+		return file
+	}
+
+	return absolute(file)
+}
+
+// Documents read by the Parser by the file they are read from (see Files), i.e. the schemas compiled from the files
+// matched by the Globs and the files loaded while resolving $refs. Documents are only known after Schemas is called
+func (p *Parser) Documents() map[string]*jsonschema.Schema {
+	p.filesMu.Lock()
+	uris := maps.Clone(p.documents)
+	p.filesMu.Unlock()
+
+	res := map[string]*jsonschema.Schema{}
+	for file, uri := range uris {
+		if schema, err := p.Compiler.GetSchema(uri); err == nil {
+			res[file] = schema
+		}
+	}
+
+	return res
+}
+
+// tracking returns a Loader that tracks the files read by the loader, where the location maps a URL to the path of
+// the file it refers to (regardless of the type of the body returned by the loader, e.g. a file of an FS)
+func (p *Parser) tracking(loader Loader, location func(url string) string) Loader {
+	return func(url string) (io.ReadCloser, error) {
+		rc, err := loader(url)
+		if err == nil {
+			p.read(location(url), url)
+		}
+
+		return rc, err
+	}
+}
+
 // Schemas read by the parser
 func (p *Parser) Schemas() ([]*jsonschema.Schema, error) {
 	if p == nil || len(p.Globs) == 0 {
@@ -86,13 +229,84 @@ func (p *Parser) Schemas() ([]*jsonschema.Schema, error) {
 		if newCompilerErr != nil {
 			return nil, newCompilerErr
 		}
+		location := func(url string) string { return fsPath(url) }
+		if p.FS != nil {
+			compiler.RegisterLoader("file", NewFSLoader(p.FS))
+			compiler.RegisterLoader("", NewFSLoader(p.FS))
+		} else {
+			workingDirectory, workingDirectoryErr := baseDirectory(p.BaseURI)
+			if workingDirectoryErr != nil {
+				return nil, workingDirectoryErr
+			}
+			location = func(url string) string { return absolute(filePath(workingDirectory, url)) }
+		}
+		loading := map[string]bool{}
+		for scheme, loader := range compiler.Loaders {
+			if scheme == "file" || scheme == "" {
+				loader = p.preloading(normalizing(p.tracking(loader, location)), location)
+			} else {
+				loader = normalizing(loader)
+			}
+			compiler.RegisterLoader(scheme, acyclic(loader, loading))
+		}
 		p.Compiler = compiler
 	}
 
+	matches, err := p.Matches()
+	if err != nil {
+		return nil, err
+	}
+
+	// files are read and normalized concurrently but compiled in order as the Compiler is not safe for concurrent use
+	files := p.readFiles(matches, p.Jobs)
+	p.preloaded = make(map[string]*file, len(files))
+	for _, f := range files {
+		if p.FS != nil {
+			p.preloaded[f.Name] = f
+		} else {
+			p.preloaded[absolute(f.Name)] = f
+		}
+	}
+
 	var res []*jsonschema.Schema
+	var errs []error
+	for _, f := range files {
+		logrus.Info("parsing file: ", f.Name)
+		schema, compileErr := p.compile(f)
+		if compileErr != nil {
+			errs = append(errs, compileErr)
+			continue
+		} else if schema == nil {
+			continue // not strict, the file is skipped
+		}
+
+		schema, getSchemaErr := p.Compiler.GetSchema(schema.GetSchemaURI())
+		if getSchemaErr != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.Name, getSchemaErr))
+			continue
+		}
+		p.read(f.Name, schema.GetSchemaURI())
+
+		res = append(res, schema)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// documents that (transitively) reference each other cannot always be resolved while compiling, hence resolve the
+	// remaining references now all documents are loaded
+	ResolveReferences(p.Compiler, res...)
+
+	return res, nil
+}
+
+// Matches of the Globs that are JSON files and not excluded, in the order of the Globs
+func (p *Parser) Matches() ([]string, error) {
+	var res []string
 	for _, glob := range p.Globs {
 		logrus.Info("parsing glob pattern: ", glob)
-		matches, err := filepath.Glob(glob)
+		matches, err := p.glob(glob)
 		if err != nil {
 			return nil, errors.Join(ErrInvalidGlob, err)
 		}
@@ -102,41 +316,121 @@ func (p *Parser) Schemas() ([]*jsonschema.Schema, error) {
 		}
 
 		for _, match := range matches {
-			logrus.Info("parsing file: ", match)
-			if !strings.HasSuffix(match, ".json") {
+			if !strings.HasSuffix(match, ".json") || p.excluded(match) {
 				continue
 			}
+			p.track(match)
 
-			schema, readSchemaErr := ReadSchema(p.Compiler, match, p.StrictMode)
-			if readSchemaErr != nil {
-				return nil, readSchemaErr
-			}
+			res = append(res, match)
+		}
+	}
 
-			schema, getSchemaErr := p.Compiler.GetSchema(schema.GetSchemaURI())
-			if getSchemaErr != nil {
-				return nil, getSchemaErr
-			}
+	return res, nil
+}
+
+// compile the file read by readFiles, if not StrictMode errors are logged as a warning and a nil schema is returned
+func (p *Parser) compile(f *file) (*jsonschema.Schema, error) {
+	if err := f.Err(); err != nil && p.StrictMode {
+		return nil, err
+	} else if err != nil {
+		logrus.Warnf("could not read or parse file %s", f.Name)
+		logrus.Debug(err.Error())
+		return nil, nil
+	}
 
-			res = append(res, schema)
+	// a file referenced by a file compiled before is already compiled by the Compiler
+	if f.URL != "" {
+		if schema, err := p.Compiler.GetSchema(f.URL); err == nil {
+			return schema, nil
 		}
 	}
 
-	return res, nil
+	schema, compileSchemaErr := p.Compiler.Compile(f.Contents)
+	if compileSchemaErr != nil && p.StrictMode {
+		return nil, fmt.Errorf("%s: %w", f.Name, errors.Join(ErrParsingSchema, compileSchemaErr))
+	} else if compileSchemaErr != nil {
+		logrus.Warnf("could not compile schema %s", f.Name)
+		logrus.Debug(compileSchemaErr.Error())
+		return nil, nil
+	}
+
+	return schema, nil
+}
+
+// glob returns the files matching the pattern on disk or in the FS if set
+func (p *Parser) glob(pattern string) ([]string, error) {
+	if p.FS != nil {
+		return fs.Glob(p.FS, pattern)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// excluded returns true iff the file (or its absolute path) matches one of the Exclude patterns
+func (p *Parser) excluded(file string) bool {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+
+	for _, pattern := range p.Exclude {
+		if ok, _ := filepath.Match(pattern, file); ok {
+			return true
+		}
+
+		if ok, _ := filepath.Match(pattern, abs); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// preloading returns a Loader that serves the files read by readFiles from memory, such that a file matched by the
+// Globs and referenced by another schema is read and normalized only once. The location maps a URL to the path of the
+// file it refers to
+func (p *Parser) preloading(loader Loader, location func(url string) string) Loader {
+	return func(url string) (io.ReadCloser, error) {
+		f, ok := p.preloaded[location(url)]
+		if !ok || f.Err() != nil {
+			return loader(url)
+		}
+		f.URL = strings.SplitN(url, "#", 2)[0]
+		p.read(f.Name, f.URL)
+
+		return io.NopCloser(bytes.NewReader(f.Contents)), nil
+	}
+}
+
+// absolute path of the file, or the file itself if it has none
+func absolute(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+
+	return file
+}
+
+// baseDirectory file:// $id's are resolved against for baseURI. If the baseURI is an empty string "" the current
+// working directory is used.
+func baseDirectory(baseURI string) (string, error) {
+	if baseURI != "" {
+		return baseURI, nil
+	}
+
+	return os.Getwd()
 }
 
 // NewCompiler for baseURI. If the baseURI is an empty string "" the current working directory is used.
 func NewCompiler(baseURI string) (*jsonschema.Compiler, error) {
 	compiler := jsonschema.NewCompiler()
-	var workingDirectory string
 	if baseURI != "" {
 		compiler = compiler.SetDefaultBaseURI(baseURI)
-		workingDirectory = baseURI
-	} else {
-		cwd, cwdErr := os.Getwd()
-		if cwdErr != nil {
-			return nil, cwdErr
-		}
-		workingDirectory = cwd
+	}
+
+	workingDirectory, workingDirectoryErr := baseDirectory(baseURI)
+	if workingDirectoryErr != nil {
+		return nil, workingDirectoryErr
 	}
 
 	// register both the file:// and the implicit scheme as a file loader
@@ -146,46 +440,79 @@ func NewCompiler(baseURI string) (*jsonschema.Compiler, error) {
 	return compiler, nil
 }
 
-// ReadSchema from file into a jsonschema.Schema
+// ReadSchema from file into a jsonschema.Schema, read and compiled the same as the schemas matched by the Globs of a
+// Parser
 func ReadSchema(compiler *jsonschema.Compiler, filepath string, strict bool) (*jsonschema.Schema, error) {
-	contents, readFileErr := os.ReadFile(filepath)
-	if readFileErr != nil && strict {
-		return nil, errors.Join(ErrReadFile, readFileErr)
-	} else if readFileErr != nil {
-		logrus.Warnf("could not read file %s", filepath)
-		logrus.Debug(readFileErr.Error())
-		return nil, nil
-	}
+	p := &Parser{Compiler: compiler, StrictMode: strict}
 
-	schema, compileSchemaErr := compiler.Compile(contents)
-	if compileSchemaErr != nil && strict {
-		return nil, errors.Join(ErrParsingSchema, compileSchemaErr)
-	} else if compileSchemaErr != nil {
-		logrus.Warnf("could not compile schema %s", filepath)
-		logrus.Debug(compileSchemaErr.Error())
-		return nil, nil
-	}
-
-	return schema, nil
+	return p.compile(p.readFile(filepath))
 }
 
 // Loader used by jsonschema.Compiler::Loaders
 type Loader func(url string) (io.ReadCloser, error)
 
+// acyclic returns a Loader that refuses to load a document again while it is being compiled, i.e. until the compiler
+// closes the body. The compiler only registers a document after resolving its references, hence documents referencing
+// each other would otherwise be loaded endlessly. The references left unresolved are resolved by ResolveReferences
+func acyclic(loader Loader, loading map[string]bool) Loader {
+	return func(url string) (io.ReadCloser, error) {
+		document := path.Clean("/" + strings.TrimPrefix(strings.SplitN(url, "#", 2)[0], "file://"))
+		if loading[document] {
+			return nil, fmt.Errorf("%w: %s", ErrCyclicLoad, url)
+		}
+
+		rc, err := loader(url)
+		if err != nil {
+			return rc, err
+		}
+		loading[document] = true
+
+		return &loadingCloser{ReadCloser: rc, done: func() { delete(loading, document) }}, nil
+	}
+}
+
+// loadingCloser calls done once the body of a loaded document is closed
+type loadingCloser struct {
+	io.ReadCloser
+	done func()
+}
+
+// Close the body and mark the document as loaded
+func (c *loadingCloser) Close() error {
+	c.done()
+
+	return c.ReadCloser.Close()
+}
+
 // NewFileLoader constructs a loader that can read from disk
 func NewFileLoader(workingDirectory string) Loader {
 	workingDirectory = strings.TrimPrefix(workingDirectory, "file://")
 
 	return func(url string) (io.ReadCloser, error) {
-		url = strings.TrimPrefix(url, "file://")
-		url = path.Join(workingDirectory, url)
-		url = path.Clean(url)
+		return os.Open(filePath(workingDirectory, url))
+	}
+}
 
-		parts := strings.SplitN(url, "#", 2)
-		if len(parts) == 2 {
-			url = parts[0]
-		}
+// filePath on disk of the (file://) URL relative to the workingDirectory
+func filePath(workingDirectory, url string) string {
+	url = strings.TrimPrefix(url, "file://")
+	url = path.Join(strings.TrimPrefix(workingDirectory, "file://"), url)
+	url = path.Clean(url)
+
+	return strings.SplitN(url, "#", 2)[0]
+}
 
-		return os.Open(url)
+// NewFSLoader constructs a loader that reads from fsys where the (file://) URL is taken relative to the root of fsys
+func NewFSLoader(fsys fs.FS) Loader {
+	return func(url string) (io.ReadCloser, error) {
+		return fsys.Open(fsPath(url))
 	}
 }
+
+// fsPath in an fs.FS of the (file://) URL taken relative to its root
+func fsPath(url string) string {
+	url = strings.TrimPrefix(url, "file://")
+	url = strings.SplitN(url, "#", 2)[0]
+
+	return strings.TrimPrefix(path.Clean("/"+url), "/")
+}
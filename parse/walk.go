@@ -0,0 +1,147 @@
+package parse
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Walk the jsonschema.Schema and its nested subschemas depth first in a deterministic order. Resolved $ref's are not
+// followed. If fn returns false the subschemas of that jsonschema.Schema are skipped
+func Walk(schema *jsonschema.Schema, fn func(schema *jsonschema.Schema) bool) {
+	if schema == nil || !fn(schema) {
+		return
+	}
+
+	for _, subschema := range Subschemas(schema) {
+		Walk(subschema, fn)
+	}
+}
+
+// WalkPointers walks the jsonschema.Schema like Walk and calls fn with the JSON pointer of every subschema relative to
+// the jsonschema.Schema, e.g. '/properties/name' ("" being the jsonschema.Schema itself)
+func WalkPointers(schema *jsonschema.Schema, fn func(schema *jsonschema.Schema, pointer string) bool) {
+	walkPointers(schema, "", fn)
+}
+
+// walkPointers of the schema at the pointer
+func walkPointers(schema *jsonschema.Schema, pointer string, fn func(schema *jsonschema.Schema, pointer string) bool) {
+	if schema == nil || !fn(schema, pointer) {
+		return
+	}
+
+	for _, sub := range subschemas(schema) {
+		walkPointers(sub.schema, pointer+sub.pointer, fn)
+	}
+}
+
+// Subschemas directly nested in a jsonschema.Schema in a deterministic order (keywords in order of the specification
+// and keyed subschemas sorted by key)
+func Subschemas(schema *jsonschema.Schema) []*jsonschema.Schema {
+	var res []*jsonschema.Schema
+	for _, sub := range subschemas(schema) {
+		res = append(res, sub.schema)
+	}
+
+	return res
+}
+
+// subschema directly nested in a jsonschema.Schema at the JSON pointer relative to it
+type subschema struct {
+	pointer string
+	schema  *jsonschema.Schema
+}
+
+// subschemas directly nested in a jsonschema.Schema in the order of Subschemas
+func subschemas(schema *jsonschema.Schema) []subschema {
+	if schema == nil {
+		return nil
+	}
+
+	var res []subschema
+	res = append(res, keyed("$defs", schema.Defs)...)
+	res = append(res, indexed("allOf", schema.AllOf)...)
+	res = append(res, indexed("anyOf", schema.AnyOf)...)
+	res = append(res, indexed("oneOf", schema.OneOf)...)
+	res = append(res, subschema{"/not", schema.Not}, subschema{"/if", schema.If}, subschema{"/then", schema.Then}, subschema{"/else", schema.Else})
+	res = append(res, keyed("dependentSchemas", schema.DependentSchemas)...)
+	res = append(res, indexed("prefixItems", schema.PrefixItems)...)
+	res = append(res, subschema{"/items", schema.Items}, subschema{"/contains", schema.Contains})
+	if schema.Properties != nil {
+		res = append(res, keyed("properties", *schema.Properties)...)
+	}
+	if schema.PatternProperties != nil {
+		res = append(res, keyed("patternProperties", *schema.PatternProperties)...)
+	}
+	res = append(res, subschema{"/additionalProperties", schema.AdditionalProperties}, subschema{"/propertyNames", schema.PropertyNames})
+	res = append(res, subschema{"/unevaluatedItems", schema.UnevaluatedItems}, subschema{"/unevaluatedProperties", schema.UnevaluatedProperties}, subschema{"/contentSchema", schema.ContentSchema})
+
+	return slices.DeleteFunc(res, func(s subschema) bool { return s.schema == nil })
+}
+
+// keyed subschemas of the keyword sorted by their key
+func keyed[M ~map[string]*jsonschema.Schema](keyword string, m M) []subschema {
+	var res []subschema
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		res = append(res, subschema{"/" + keyword + "/" + escapePointer(key), m[key]})
+	}
+
+	return res
+}
+
+// indexed subschemas of the keyword in order
+func indexed(keyword string, schemas []*jsonschema.Schema) []subschema {
+	var res []subschema
+	for i, schema := range schemas {
+		res = append(res, subschema{"/" + keyword + "/" + strconv.Itoa(i), schema})
+	}
+
+	return res
+}
+
+// ResolveReferences of the documents and every document they (transitively) reference to the jsonschema.Schema's
+// loaded in the jsonschema.Compiler. Documents that reference each other are compiled through intermediate copies,
+// which may leave references unresolved or resolved to a copy instead of the loaded jsonschema.Schema
+func ResolveReferences(compiler *jsonschema.Compiler, documents ...*jsonschema.Schema) {
+	seen := map[*jsonschema.Schema]bool{}
+	queue := slices.Clone(documents)
+	for len(queue) > 0 {
+		document := queue[0]
+		queue = queue[1:]
+		if document == nil || seen[document] {
+			continue
+		}
+
+		Walk(document, func(schema *jsonschema.Schema) bool {
+			seen[schema] = true
+			base, _ := url.Parse(schema.GetSchemaURI())
+
+			if schema.Ref != "" {
+				if resolved, err := compiler.GetSchema(ResolveURI(base, schema.Ref).String()); err == nil {
+					schema.ResolvedRef = resolved
+				}
+			}
+
+			if schema.DynamicRef != "" {
+				if resolved, err := compiler.GetSchema(ResolveURI(base, schema.DynamicRef).String()); err == nil {
+					schema.ResolvedDynamicRef = resolved
+				}
+			}
+
+			for _, resolved := range []*jsonschema.Schema{schema.ResolvedRef, schema.ResolvedDynamicRef} {
+				if resolved == nil {
+					continue
+				}
+
+				if parent, err := compiler.GetSchema(resolved.GetSchemaURI()); err == nil {
+					queue = append(queue, parent)
+				}
+			}
+
+			return true
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkPointers(t *testing.T) {
+	// Arrange
+	schema, err := jsonschema.NewCompiler().Compile([]byte(`{
+		"$defs": {"a/b": {"type": "string"}},
+		"allOf": [{"properties": {"name": {"type": "string"}}}],
+		"items": {"type": "integer"}
+	}`))
+	require.NoError(t, err)
+
+	// Act
+	var pointers []string
+	WalkPointers(schema, func(_ *jsonschema.Schema, pointer string) bool {
+		pointers = append(pointers, pointer)
+		return true
+	})
+
+	// Assert
+	assert.Equal(t, []string{"", "/$defs/a~1b", "/allOf/0", "/allOf/0/properties/name", "/items"}, pointers)
+}
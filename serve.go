@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/serve"
+	"github.com/Emptyless/jsonschema-transform/transform"
+	"github.com/Emptyless/jsonschema-transform/watch"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd registered to the rootCmd
+var serveCmd = &cobra.Command{
+	Use:          "serve",
+	Short:        "preview the d2 diagram in the browser and reload it when the json schemas change",
+	Long:         "host the d2 diagram of the json schemas on localhost, rebuild it when the schemas change and reload the browser. The page accepts the query parameters 'focus' ($id, file or title of a class), 'depth' (relations around the focus, -1 for all) and 'container' (true or false)",
+	Example:      fmt.Sprintf("%s serve --globs 'schemas/*.json' --base-uri schemas", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleServe,
+}
+
+// init the serveCmd command
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	globsFlag.Apply(serveCmd.Flags())
+	excludeFlag.Apply(serveCmd.Flags())
+	jobsFlag.Apply(serveCmd.Flags())
+	baseURIFlag.Apply(serveCmd.Flags())
+	containerBasePathFlag.Apply(serveCmd.Flags())
+	depthFlag.Apply(serveCmd.Flags())
+	titleFlag.Apply(serveCmd.Flags())
+	addrFlag.Apply(serveCmd.Flags())
+	cacheDirFlag.Apply(serveCmd.Flags())
+	cacheMaxAgeFlag.Apply(serveCmd.Flags())
+	for _, f := range d2ConfigFlags {
+		f.Apply(serveCmd.Flags())
+	}
+}
+
+// handleServe for the serveCmd command
+func handleServe(cmd *cobra.Command, _ []string) error {
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	diagram, err := newD2Config(cmd, "diagram.svg")
+	if err != nil {
+		return err
+	}
+
+	dir, err := openCache(cmd)
+	if err != nil {
+		return err
+	}
+	if dir != nil {
+		diagram.Cache = dir
+	}
+
+	// container mode is available from the query regardless of the flag, relative to the base-uri (or working directory)
+	containerBasePath := cmd.Flag(containerBasePathFlag.Name).Value.String()
+	if transform.HasHTTPPrefix(parser.BaseURI) && containerBasePath != "" {
+		return fmt.Errorf("cannot use --%s when --%s is not a file:// based URI", containerBasePathFlag.Name, baseURIFlag.Name)
+	}
+
+	root := strings.TrimPrefix(parser.BaseURI, "file://")
+	if root == "" || transform.HasHTTPPrefix(root) {
+		if root, err = os.Getwd(); err != nil {
+			return err
+		}
+	}
+
+	server := serve.New(&serve.Config{
+		Title:             cmd.Flag(titleFlag.Name).Value.String(),
+		Diagram:           diagram,
+		ContainerBasePath: path.Join(root, containerBasePath),
+		Container:         containerBasePath != "",
+	})
+
+	listener, err := net.Listen("tcp", cmd.Flag(addrFlag.Name).Value.String())
+	if err != nil {
+		return err
+	}
+
+	httpServer := &http.Server{Handler: server}
+	go func() {
+		if serveErr := httpServer.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			logrus.Error(serveErr)
+		}
+	}()
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "serving diagram on http://%s, press Ctrl+C to stop\n", listener.Addr())
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	watcher := &watch.Watcher{
+		Run: func() error {
+			parser.Reset()
+			return server.Update(cachedParser(parser, dir))
+		},
+		Files: parser.Files,
+		Globs: parser.Globs,
+	}
+
+	watchErr := watcher.Watch(ctx)
+
+	// the server-sent events only end when the client disconnects, hence close instead of waiting for them
+	if closeErr := httpServer.Close(); closeErr != nil {
+		return errors.Join(watchErr, closeErr)
+	}
+
+	return watchErr
+}
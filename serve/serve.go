@@ -0,0 +1,305 @@
+// Package serve hosts the D2 diagram of the schemas over HTTP and reloads the browser when the schemas change
+package serve
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Emptyless/jsonschema-transform/d2"
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed templates/index.html
+var templatesFS embed.FS
+
+// indexTemplate of the page showing the diagram
+var indexTemplate = template.Must(template.ParseFS(templatesFS, "templates/index.html"))
+
+// Config of the Server
+type Config struct {
+	// Title of the page
+	Title string
+
+	// Diagram options, the Format is always d2.SVG and the classes link to the diagram focussed on that class
+	Diagram *d2.Config
+
+	// ContainerBasePath used when the diagram is rendered in container mode (see Query.Container)
+	ContainerBasePath string
+
+	// Container mode used when the query does not set it
+	Container bool
+}
+
+// Query parameters of the page and the diagram
+type Query struct {
+	// Focus on the classes matching the $id, file or title (see parse.FindClasses), all classes if empty
+	Focus string
+
+	// Depth of relations around the Focus to include, -1 includes every (transitively) related class
+	Depth int
+
+	// Container renders the classes in containers by their directory (see d2.Config.ContainerBasePath)
+	Container bool
+}
+
+// Server of the diagram, call Update to (re)load the classes and reload the connected browsers
+type Server struct {
+	cfg *Config
+
+	// mu guards the fields below
+	mu        sync.RWMutex
+	classes   []*domain.Class
+	relations []*domain.Relation
+	err       error
+	version   int
+	clients   map[chan int]struct{}
+}
+
+// New Server for the Config
+func New(cfg *Config) *Server {
+	if cfg.Diagram == nil {
+		cfg.Diagram = &d2.Config{}
+	}
+
+	return &Server{cfg: cfg, clients: map[chan int]struct{}{}}
+}
+
+// Update the classes and relations from the parser and reload the connected browsers. The error of the parser is
+// returned and shown on the page until the next Update
+func (s *Server) Update(parser d2.Parser) error {
+	classes, err := parser.Classes()
+	var relations []*domain.Relation
+	if err == nil {
+		relations, err = parser.Relations()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil {
+		s.classes, s.relations = classes, relations
+	}
+	s.err = err
+	s.version++
+
+	for client := range s.clients {
+		select {
+		case client <- s.version:
+		default: // the client has a reload pending
+		}
+	}
+
+	return err
+}
+
+// ServeHTTP serves the page on '/', the diagram on '/diagram.svg' and the reload events on '/events'
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/":
+		s.serveIndex(w, r)
+	case "/diagram.svg":
+		s.serveDiagram(w, r)
+	case "/events":
+		s.serveEvents(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ParseQuery from the URL values, missing values use the defaults of the Config
+func (s *Server) ParseQuery(values url.Values) (*Query, error) {
+	query := &Query{Focus: values.Get("focus"), Depth: 1, Container: s.cfg.Container}
+	if depth := values.Get("depth"); depth != "" {
+		d, err := strconv.Atoi(depth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid depth %q: %w", depth, err)
+		}
+		query.Depth = d
+	}
+
+	if container := values.Get("container"); container != "" {
+		c, err := strconv.ParseBool(container)
+		if err != nil {
+			return nil, fmt.Errorf("invalid container %q: %w", container, err)
+		}
+		query.Container = c
+	}
+
+	return query, nil
+}
+
+// Values of the Query, such that focus links keep the depth and container mode
+func (q *Query) Values() url.Values {
+	values := url.Values{}
+	if q.Focus != "" {
+		values.Set("focus", q.Focus)
+	}
+	values.Set("depth", strconv.Itoa(q.Depth))
+	values.Set("container", strconv.FormatBool(q.Container))
+
+	return values
+}
+
+// Render the diagram as SVG for the Query
+func (s *Server) Render(query *Query) ([]byte, error) {
+	s.mu.RLock()
+	classes, relations, err := s.classes, s.relations, s.err
+	s.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Focus != "" {
+		targets, findErr := parse.FindClasses(classes, query.Focus)
+		if findErr != nil {
+			return nil, findErr
+		}
+		classes, relations = parse.Neighbourhood(classes, relations, query.Depth, targets...)
+	}
+
+	cfg := *s.cfg.Diagram
+	cfg.Format = d2.SVG
+	cfg.ContainerBasePath = ""
+	if query.Container {
+		cfg.ContainerBasePath = s.cfg.ContainerBasePath
+	}
+	cfg.Link = func(class *domain.Class) string {
+		focus := *query
+		focus.Focus = class.Name
+		return "./?" + focus.Values().Encode() // D2 interprets links without './' as a link to one of its boards
+	}
+
+	return d2.D2(&graph{classes: classes, relations: relations}, &cfg)
+}
+
+// page data of the indexTemplate
+type page struct {
+	Title   string
+	Query   *Query
+	Classes []string
+	Diagram template.HTML
+	Error   string
+	Version int
+}
+
+// serveIndex with the diagram inlined such that its links can be followed
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	data := &page{Title: s.cfg.Title, Version: s.version}
+	for _, class := range s.classes {
+		data.Classes = append(data.Classes, class.Name)
+	}
+	s.mu.RUnlock()
+
+	query, err := s.ParseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data.Query = query
+
+	if svg, renderErr := s.Render(query); renderErr != nil {
+		data.Error = renderErr.Error()
+	} else {
+		// strip the XML declaration such that the SVG can be inlined
+		diagram := string(svg)
+		if i := strings.Index(diagram, "<svg"); i > 0 {
+			diagram = diagram[i:]
+		}
+		data.Diagram = template.HTML(diagram)
+	}
+
+	var buffer bytes.Buffer
+	if err = indexTemplate.Execute(&buffer, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buffer.Bytes())
+}
+
+// serveDiagram as SVG for the query
+func (s *Server) serveDiagram(w http.ResponseWriter, r *http.Request) {
+	query, err := s.ParseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	svg, err := s.Render(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/svg+xml")
+	_, _ = w.Write(svg)
+}
+
+// serveEvents as server-sent events, a 'reload' event is sent on every Update until the client disconnects. If the
+// 'version' the client has shown is outdated, the reload is sent immediately
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	client := make(chan int, 1)
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	if version, err := strconv.Atoi(r.URL.Query().Get("version")); err == nil && version < s.version {
+		client <- s.version
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	_, _ = fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case version := <-client:
+			logrus.Debug("reloading browser to version ", version)
+			_, _ = fmt.Fprintf(w, "event: reload\ndata: %d\n\n", version)
+			flusher.Flush()
+		}
+	}
+}
+
+// graph of domain.Class'es and domain.Relation's that is already parsed
+type graph struct {
+	classes   []*domain.Class
+	relations []*domain.Relation
+}
+
+// Classes of the graph
+func (g *graph) Classes() ([]*domain.Class, error) {
+	return g.classes, nil
+}
+
+// Relations of the graph
+func (g *graph) Relations() ([]*domain.Relation, error) {
+	return g.relations, nil
+}
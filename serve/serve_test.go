@@ -0,0 +1,108 @@
+package serve
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse/parsetest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_ServesFocusedDiagram(t *testing.T) {
+	// Arrange
+	pet := &domain.Class{Name: "Pet", Source: domain.FileSource{FilePath: "file:///schemas/pet.json"}}
+	store := &domain.Class{Name: "Pet Store", Source: domain.FileSource{FilePath: "file:///schemas/store.json"}}
+	owner := &domain.Class{Name: "Owner", Source: domain.FileSource{FilePath: "file:///schemas/owner.json"}}
+	server := New(&Config{Title: "Pets"})
+	require.NoError(t, server.Update(&parsetest.Parser{
+		ClassData:     []*domain.Class{pet, store, owner},
+		RelationsData: []*domain.Relation{{Type: "store", From: pet, To: store}, {Type: "pet", From: owner, To: pet}},
+	}))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/?focus=Pet+Store&depth=1", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	body := recorder.Body.String()
+	assert.Contains(t, body, "<title>Pet Store - Pets</title>")
+	assert.Contains(t, body, "<svg")
+	assert.Contains(t, body, `href="./?container=false&amp;depth=1&amp;focus=Pet"`, "classes link to the diagram focussed on them")
+	assert.NotContains(t, body, `focus=Owner"`, "owner is two relations away from the focus")
+}
+
+func TestServer_ShowsErrors(t *testing.T) {
+	// Arrange
+	server := New(&Config{Title: "Pets"})
+	updateErr := server.Update(&parsetest.Parser{ClassesError: errors.New("invalid schema")})
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Assert
+	require.EqualError(t, updateErr, "invalid schema")
+	assert.Contains(t, recorder.Body.String(), `<pre class="error">invalid schema</pre>`)
+}
+
+func TestServer_SendsReloadOnUpdate(t *testing.T) {
+	// Arrange
+	parser := &parsetest.Parser{ClassData: []*domain.Class{{Name: "Pet", Source: domain.FileSource{FilePath: "file:///schemas/pet.json"}}}}
+	server := New(&Config{})
+	require.NoError(t, server.Update(parser))
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	response, err := http.Get(httpServer.URL + "/events?version=1")
+	require.NoError(t, err)
+	defer response.Body.Close()
+	reader := bufio.NewReader(response.Body)
+	connected := readEvent(t, reader)
+
+	// Act
+	require.NoError(t, server.Update(parser))
+
+	// Assert
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	assert.Equal(t, ": connected\n", connected)
+	assert.Equal(t, "event: reload\ndata: 2\n", readEvent(t, reader))
+}
+
+func TestServer_ParseQuery(t *testing.T) {
+	// Arrange
+	server := New(&Config{Container: true})
+
+	// Act
+	defaults, err := server.ParseQuery(url.Values{})
+	require.NoError(t, err)
+	query, err := server.ParseQuery(url.Values{"focus": {"Pet"}, "depth": {"-1"}, "container": {"false"}})
+	require.NoError(t, err)
+	_, invalidErr := server.ParseQuery(url.Values{"depth": {"deep"}})
+
+	// Assert
+	assert.Equal(t, &Query{Depth: 1, Container: true}, defaults)
+	assert.Equal(t, &Query{Focus: "Pet", Depth: -1, Container: false}, query)
+	assert.ErrorContains(t, invalidErr, `invalid depth "deep"`)
+}
+
+// readEvent from the server-sent events up to the empty line that ends it
+func readEvent(t *testing.T, reader *bufio.Reader) string {
+	var event strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF || line == "\n" {
+			return event.String()
+		}
+		require.NoError(t, err)
+		event.WriteString(line)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_RebuildsOnChange(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	schemaFile := filepath.Join(dir, "pet.json")
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"$id": "pet.json", "title": "Pet", "type": "object", "properties": {"name": {"type": "string"}}}`), 0o644))
+	outputReader, outputWriter := io.Pipe()
+	rootCmd.SetOut(outputWriter)
+	args := []string{serveCmd.Use, "--globs", filepath.Join(dir, "*.json"), "--base-uri", dir, "--addr", "127.0.0.1:0"}
+	rootCmd.SetArgs(args)
+	ctx, cancel := context.WithCancel(context.Background())
+	serveCmd.SetContext(ctx) // cobra only passes the context of the rootCmd to commands without one
+	t.Cleanup(func() {
+		serveCmd.SetContext(context.Background())
+		rootCmd.SetOut(nil)
+	})
+	done := make(chan error)
+	go func() { done <- rootCmd.Execute() }()
+
+	line, err := bufio.NewReader(outputReader).ReadString('\n')
+	require.NoError(t, err)
+	go func() { _, _ = io.Copy(io.Discard, outputReader) }()
+	url := strings.TrimSuffix(strings.Fields(strings.TrimPrefix(line, "serving diagram on "))[0], ",")
+	get := func() string {
+		response, getErr := http.Get(url + "/?focus=Pet")
+		if getErr != nil {
+			return ""
+		}
+		defer response.Body.Close()
+		b, _ := io.ReadAll(response.Body)
+		return string(b)
+	}
+	require.Eventually(t, func() bool { return strings.Contains(get(), "name") }, 5*time.Second, 20*time.Millisecond)
+
+	// Act
+	require.NoError(t, os.WriteFile(schemaFile, []byte(`{"$id": "pet.json", "title": "Pet", "type": "object", "properties": {"nickname": {"type": "string"}}}`), 0o644))
+
+	// Assert
+	require.Eventually(t, func() bool { return strings.Contains(get(), "nickname") }, 5*time.Second, 20*time.Millisecond)
+	assert.Contains(t, get(), "<svg")
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,38 @@
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var root = document.body.getAttribute("data-root") || "";
+  var index = window.searchIndex || [];
+
+  function matches(entry, query) {
+    if (entry.name.toLowerCase().indexOf(query) >= 0 || entry.description.toLowerCase().indexOf(query) >= 0) {
+      return true;
+    }
+
+    return entry.properties.some(function (property) {
+      return property.toLowerCase().indexOf(query) >= 0;
+    });
+  }
+
+  input.addEventListener("input", function () {
+    var query = input.value.trim().toLowerCase();
+    results.textContent = "";
+    if (!query) {
+      return;
+    }
+
+    index.filter(function (entry) {
+      return matches(entry, query);
+    }).slice(0, 20).forEach(function (entry) {
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = root + entry.url;
+      link.textContent = entry.name;
+      var description = document.createElement("small");
+      description.textContent = entry.description;
+      link.appendChild(description);
+      item.appendChild(link);
+      results.appendChild(item);
+    });
+  });
+})();
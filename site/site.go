@@ -0,0 +1,417 @@
+// Package site generates a static HTML documentation site from the view.Model
+package site
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/d2"
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
+	"github.com/Emptyless/jsonschema-transform/view"
+	"github.com/kaptinlin/jsonschema"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+//go:embed assets
+var assetsFS embed.FS
+
+// Config of the Site
+type Config struct {
+	// Title of the Site
+	Title string
+
+	// Diagram rendered on the index page, if nil no diagram is rendered. The Format is always d2.SVG and the classes
+	// link to their pages (see d2.Config.Link)
+	Diagram *d2.Config
+}
+
+// Site of static pages
+type Site struct {
+	// Title of the Site
+	Title string
+
+	// Model the Site documents
+	Model *view.Model
+
+	// Pages per view.Class
+	Pages []*Page
+
+	// Diagram of all classes as SVG (possibly empty)
+	Diagram template.HTML
+
+	// pages by view.Class
+	pages map[*view.Class]*Page
+}
+
+// Page of a single view.Class
+type Page struct {
+	*view.Class
+
+	// Slug unique for the Page, used as file name
+	Slug string
+
+	// URL of the Page relative to the root of the Site
+	URL string
+
+	// Properties of the view.Class
+	Properties []*Property
+
+	// Outgoing links to the pages of the referenced classes
+	Outgoing []*Link
+
+	// Incoming links from the pages of the classes referencing this class
+	Incoming []*Link
+
+	// Source of the schema of the view.Class as indented JSON
+	Source string
+}
+
+// Property of a Page
+type Property struct {
+	*domain.Property
+
+	// Required is true iff the property is required by its class
+	Required bool
+
+	// Constraints on the property (e.g. 'minLength: 1')
+	Constraints []string
+
+	// Examples of the property as JSON
+	Examples []string
+
+	// Links to the pages of the classes referenced by the property
+	Links []*Link
+}
+
+// Link to the Page of a view.Class along a view.Relation
+type Link struct {
+	// Page linked to
+	Page *Page
+
+	// Relation linked along
+	Relation *view.Relation
+}
+
+// SearchEntry in the search index
+type SearchEntry struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Properties  []string `json:"properties"`
+}
+
+// New Site for the view.Model. The Diagram is rendered using the Config.Diagram
+func New(model *view.Model, cfg *Config) (*Site, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	site := &Site{Title: cfg.Title, Model: model, pages: map[*view.Class]*Page{}}
+	if site.Title == "" {
+		site.Title = "Schemas"
+	}
+
+	byClass := map[*domain.Class]*Page{}
+	for _, class := range model.Classes {
+		page := &Page{Class: class, Slug: class.Slug, URL: "classes/" + class.Slug + ".html"}
+		site.Pages = append(site.Pages, page)
+		site.pages[class] = page
+		byClass[class.Class] = page
+	}
+
+	for _, page := range site.Pages {
+		if err := page.populate(site); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Diagram != nil {
+		diagram, err := site.diagram(cfg, byClass)
+		if err != nil {
+			return nil, err
+		}
+		site.Diagram = template.HTML(diagram)
+	}
+
+	return site, nil
+}
+
+// populate the Page from its view.Class
+func (p *Page) populate(site *Site) error {
+	var required []string
+	if schema := p.Schema; schema != nil {
+		required = schema.Required
+
+		source, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return err
+		}
+		p.Source = string(source)
+	}
+
+	for _, property := range p.Class.Properties {
+		row := &Property{Property: property, Constraints: Constraints(property.Schema)}
+		for _, name := range required {
+			row.Required = row.Required || name == property.Name
+		}
+
+		if property.Schema != nil {
+			for _, example := range property.Schema.Examples {
+				b, err := json.Marshal(example)
+				if err != nil {
+					return err
+				}
+				row.Examples = append(row.Examples, string(b))
+			}
+		}
+
+		for _, relation := range p.Class.Outgoing {
+			if relation.FromProperty == property && !containsLink(row.Links, relation.To) {
+				row.Links = append(row.Links, &Link{Page: site.pages[relation.To], Relation: relation})
+			}
+		}
+
+		p.Properties = append(p.Properties, row)
+	}
+
+	for _, relation := range p.Class.Outgoing {
+		if !containsLink(p.Outgoing, relation.To) {
+			p.Outgoing = append(p.Outgoing, &Link{Page: site.pages[relation.To], Relation: relation})
+		}
+	}
+
+	for _, relation := range p.Class.Incoming {
+		if !containsLink(p.Incoming, relation.From) {
+			p.Incoming = append(p.Incoming, &Link{Page: site.pages[relation.From], Relation: relation})
+		}
+	}
+
+	return nil
+}
+
+// containsLink returns true iff one of the links points to the view.Class
+func containsLink(links []*Link, class *view.Class) bool {
+	for _, link := range links {
+		if link.Page.Class == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// diagram of all classes as SVG where each class links to its Page
+func (s *Site) diagram(cfg *Config, pages map[*domain.Class]*Page) (string, error) {
+	diagramCfg := *cfg.Diagram
+	diagramCfg.Format = d2.SVG
+	diagramCfg.Link = func(class *domain.Class) string {
+		if page, ok := pages[class]; ok {
+			return "./" + page.URL // D2 interprets links without './' as a link to one of its boards
+		}
+
+		return ""
+	}
+
+	b, err := d2.D2(&graph{model: s.Model}, &diagramCfg)
+	if err != nil {
+		return "", err
+	}
+
+	// strip the XML declaration such that the SVG can be inlined in the index
+	svg := string(b)
+	if i := strings.Index(svg, "<svg"); i > 0 {
+		svg = svg[i:]
+	}
+
+	return svg, nil
+}
+
+// graph of the view.Model implementing d2.Parser
+type graph struct {
+	model *view.Model
+}
+
+// Classes of the view.Model
+func (g *graph) Classes() ([]*domain.Class, error) {
+	var res []*domain.Class
+	for _, class := range g.model.Classes {
+		res = append(res, class.Class)
+	}
+
+	return res, nil
+}
+
+// Relations of the view.Model
+func (g *graph) Relations() ([]*domain.Relation, error) {
+	var res []*domain.Relation
+	for _, relation := range g.model.Relations {
+		res = append(res, relation.Relation)
+	}
+
+	return res, nil
+}
+
+// SearchIndex of the Site
+func (s *Site) SearchIndex() []*SearchEntry {
+	res := make([]*SearchEntry, 0, len(s.Pages))
+	for _, page := range s.Pages {
+		entry := &SearchEntry{Name: page.Name, Description: page.Docstring, URL: page.URL, Properties: []string{}}
+		for _, property := range page.Class.Properties {
+			entry.Properties = append(entry.Properties, property.Name)
+		}
+		res = append(res, entry)
+	}
+
+	return res
+}
+
+// Files of the Site by their path relative to the root of the Site
+func (s *Site) Files() (map[string][]byte, error) {
+	templates, err := template.New("site").Funcs(tmpl.Funcs()).ParseFS(templatesFS, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	render := func(path string, name string, data any) error {
+		buffer := new(bytes.Buffer)
+		if err := templates.ExecuteTemplate(buffer, name, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", path, err)
+		}
+		files[path] = buffer.Bytes()
+
+		return nil
+	}
+
+	if err = render("index.html", "index.html", map[string]any{"Site": s, "Root": ""}); err != nil {
+		return nil, err
+	}
+
+	for _, page := range s.Pages {
+		if err = render(page.URL, "class.html", map[string]any{"Site": s, "Page": page, "Root": "../"}); err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := json.Marshal(s.SearchIndex())
+	if err != nil {
+		return nil, err
+	}
+	files["assets/search-index.js"] = []byte("window.searchIndex = " + string(index) + ";\n")
+
+	err = fs.WalkDir(assetsFS, "assets", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		b, readFileErr := assetsFS.ReadFile(path)
+		files[path] = b
+
+		return readFileErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// Write the Files of the Site to the directory
+func (s *Site) Write(dir string) error {
+	files, err := s.Files()
+	if err != nil {
+		return err
+	}
+
+	for path, b := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(path, b, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Constraints of a jsonschema.Schema in a human readable form (e.g. 'minLength: 1')
+func Constraints(schema *jsonschema.Schema) []string {
+	if schema == nil {
+		return nil
+	}
+
+	var res []string
+	add := func(keyword string, value any) {
+		b, err := json.Marshal(value)
+		if err != nil {
+			b = []byte(fmt.Sprint(value))
+		}
+		res = append(res, keyword+": "+string(b))
+	}
+
+	if len(schema.Enum) > 0 {
+		add("enum", schema.Enum)
+	}
+	if schema.Const != nil && schema.Const.IsSet {
+		add("const", schema.Const.Value)
+	}
+	for keyword, value := range map[string]*jsonschema.Rat{
+		"multipleOf":       schema.MultipleOf,
+		"minimum":          schema.Minimum,
+		"exclusiveMinimum": schema.ExclusiveMinimum,
+		"maximum":          schema.Maximum,
+		"exclusiveMaximum": schema.ExclusiveMaximum,
+	} {
+		if value != nil && value.Rat != nil {
+			res = append(res, keyword+": "+value.RatString())
+		}
+	}
+	for keyword, value := range map[string]*float64{
+		"minLength":     schema.MinLength,
+		"maxLength":     schema.MaxLength,
+		"minItems":      schema.MinItems,
+		"maxItems":      schema.MaxItems,
+		"minContains":   schema.MinContains,
+		"maxContains":   schema.MaxContains,
+		"minProperties": schema.MinProperties,
+		"maxProperties": schema.MaxProperties,
+	} {
+		if value != nil {
+			add(keyword, *value)
+		}
+	}
+	if schema.Pattern != nil {
+		add("pattern", *schema.Pattern)
+	}
+	for keyword, value := range map[string]*bool{
+		"uniqueItems": schema.UniqueItems,
+		"deprecated":  schema.Deprecated,
+		"readOnly":    schema.ReadOnly,
+		"writeOnly":   schema.WriteOnly,
+	} {
+		if value != nil && *value {
+			res = append(res, keyword)
+		}
+	}
+	if schema.Default != nil {
+		add("default", schema.Default)
+	}
+
+	slices.Sort(res)
+
+	return res
+}
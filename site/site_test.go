@@ -0,0 +1,69 @@
+package site
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse/parsetest"
+	"github.com/Emptyless/jsonschema-transform/view"
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSite_Files(t *testing.T) {
+	// Arrange
+	petSchema, storeSchema := &jsonschema.Schema{Required: []string{"store"}}, &jsonschema.Schema{}
+	storeProperty := &domain.Property{Name: "store", Type: "Store", Schema: &jsonschema.Schema{Examples: []any{"abc"}}}
+	pet := &domain.Class{Schema: petSchema, Name: "Pet", Docstring: "a friendly animal", Source: domain.FileSource{FilePath: "file:///schemas/pet.json"}, Properties: []*domain.Property{storeProperty}}
+	store := &domain.Class{Schema: storeSchema, Name: "Pet Store", Source: domain.FileSource{FilePath: "file:///schemas/store.json"}}
+	model, err := view.New(&parsetest.Parser{
+		SchemaData:    []*jsonschema.Schema{petSchema},
+		ClassData:     []*domain.Class{pet, store},
+		RelationsData: []*domain.Relation{{Type: "store", From: pet, FromProperty: storeProperty, To: store}},
+	})
+	require.NoError(t, err)
+
+	s, err := New(model, &Config{Title: "Pets"})
+	require.NoError(t, err)
+
+	// Act
+	files, err := s.Files()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, files, "assets/style.css")
+	assert.Contains(t, files, "assets/search.js")
+
+	index := string(files["index.html"])
+	assert.Contains(t, index, `<a href="classes/pet.html">Pet</a>`)
+	assert.Contains(t, index, `<a href="classes/pet-store.html">Pet Store</a>`)
+
+	page := string(files["classes/pet.html"])
+	assert.Contains(t, page, `<link rel="stylesheet" href="../assets/style.css">`)
+	assert.Contains(t, page, `<span class="badge">required</span>`)
+	assert.Contains(t, page, `<a href="../classes/pet-store.html">Pet Store</a>`)
+	assert.Contains(t, page, `<code>&#34;abc&#34;</code>`)
+	assert.Contains(t, string(files["classes/pet-store.html"]), "Referenced by")
+
+	searchIndex := strings.TrimSuffix(strings.TrimPrefix(string(files["assets/search-index.js"]), "window.searchIndex = "), ";\n")
+	var entries []*SearchEntry
+	require.NoError(t, json.Unmarshal([]byte(searchIndex), &entries))
+	assert.Equal(t, &SearchEntry{Name: "Pet", Description: "a friendly animal", URL: "classes/pet.html", Properties: []string{"store"}}, entries[0])
+}
+
+func TestConstraints(t *testing.T) {
+	// Arrange
+	minLength, pattern, unique := 1.0, "^[a-z]+$", true
+	var schema jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{"minimum": 0.5, "enum": ["a", "b"]}`), &schema))
+	schema.MinLength, schema.Pattern, schema.UniqueItems = &minLength, &pattern, &unique
+
+	// Act
+	constraints := Constraints(&schema)
+
+	// Assert
+	assert.Equal(t, []string{`enum: ["a","b"]`, "minLength: 1", "minimum: 1/2", `pattern: "^[a-z]+$"`, "uniqueItems"}, constraints)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Emptyless/jsonschema-transform/stats"
+	"github.com/spf13/cobra"
+)
+
+// statsCmd registered to the rootCmd
+var statsCmd = &cobra.Command{
+	Use:          "stats",
+	Short:        "report metrics over the json schemas",
+	Long:         "report per class and global metrics (property counts, fan-in/fan-out, depth, cycles, documentation coverage) over the json schemas",
+	Example:      fmt.Sprintf("%s stats --globs 'schemas/*.json' --format prometheus", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleStats,
+}
+
+// init the statsCmd command
+func init() {
+	rootCmd.AddCommand(statsCmd)
+	globsFlag.Apply(statsCmd.Flags())
+	excludeFlag.Apply(statsCmd.Flags())
+	jobsFlag.Apply(statsCmd.Flags())
+	baseURIFlag.Apply(statsCmd.Flags())
+	statsFormatFlag.Apply(statsCmd.Flags())
+	topFlag.Apply(statsCmd.Flags())
+}
+
+// handleStats for the statsCmd command
+func handleStats(cmd *cobra.Command, _ []string) error {
+	format, err := stats.ParseFormat(cmd.Flag(statsFormatFlag.Name).Value.String())
+	if err != nil {
+		return err
+	}
+
+	top, err := cmd.Flags().GetInt(topFlag.Name)
+	if err != nil {
+		return err
+	}
+
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	report, err := stats.Compute(parser, top)
+	if err != nil {
+		return err
+	}
+
+	return format.Write(cmd.OutOrStdout(), report)
+}
@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+)
+
+// ErrUnknownFormat is returned when the supplied format is not recognized
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Format of the Report output
+type Format string
+
+// Table format meant for humans
+const Table Format = "table"
+
+// JSON format meant for further processing
+const JSON Format = "json"
+
+// Prometheus text exposition format meant for dashboards
+const Prometheus Format = "prometheus"
+
+// ParseFormat from its name
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case Table, JSON, Prometheus:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+}
+
+// Write the Report in the Format to the io.Writer
+func (f Format) Write(w io.Writer, report *Report) error {
+	switch f {
+	case Table:
+		return writeTable(w, report)
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case Prometheus:
+		return writePrometheus(w, report)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// writeTable writes the global metrics followed by a table with a row per class
+func writeTable(w io.Writer, report *Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintf(tw, "classes:\t%d\n", len(report.Classes))
+	_, _ = fmt.Fprintf(tw, "relations:\t%d\n", report.Relations)
+	_, _ = fmt.Fprintf(tw, "properties:\t%d\n", report.Properties)
+	_, _ = fmt.Fprintf(tw, "documented:\t%.1f%%\n", report.DocumentedPercentage)
+	_, _ = fmt.Fprintf(tw, "max depth:\t%d\n", report.MaxDepth)
+	_, _ = fmt.Fprintf(tw, "components:\t%d (largest %d)\n", len(report.Components), largestComponent(report))
+	_, _ = fmt.Fprintf(tw, "cycles:\t%d\n", len(report.Cycles))
+	for _, cycle := range report.Cycles {
+		_, _ = fmt.Fprintf(tw, "\t%s\n", strings.Join(cycle, ", "))
+	}
+	_, _ = fmt.Fprintf(tw, "largest:\t%s\n", names(report.Largest))
+	_, _ = fmt.Fprintf(tw, "unreferenced:\t%s\n", names(report.Unreferenced))
+	_, _ = fmt.Fprintln(tw)
+
+	_, _ = fmt.Fprintln(tw, "CLASS\tPROPERTIES\tDOCUMENTED\tFAN-IN\tFAN-OUT\tDEPTH\tSOURCE")
+	for _, c := range report.Classes {
+		depth := fmt.Sprint(c.Depth)
+		if c.Depth == parse.Unreachable {
+			depth = "-"
+		}
+
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n", c.Name, c.Properties, c.DocumentedProperties, c.FanIn, c.FanOut, depth, c.Source)
+	}
+
+	return tw.Flush()
+}
+
+// largestComponent is the amount of classes in the largest of the Report.Components
+func largestComponent(report *Report) int {
+	if len(report.Components) == 0 {
+		return 0
+	}
+
+	return len(report.Components[0])
+}
+
+// names of the classes separated by a comma
+func names(classes []*ClassStats) string {
+	var res []string
+	for _, c := range classes {
+		res = append(res, c.Name)
+	}
+
+	return strings.Join(res, ", ")
+}
+
+// writePrometheus writes the Report as gauges in the Prometheus text exposition format
+func writePrometheus(w io.Writer, report *Report) error {
+	var builder strings.Builder
+	gauge := func(name, help string) {
+		builder.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s gauge\n", name, help, name))
+	}
+
+	gauge("jsonschema_classes", "Number of classes.")
+	builder.WriteString(fmt.Sprintf("jsonschema_classes %d\n", len(report.Classes)))
+	gauge("jsonschema_relations", "Number of relations between classes.")
+	builder.WriteString(fmt.Sprintf("jsonschema_relations %d\n", report.Relations))
+	gauge("jsonschema_properties", "Number of properties over all classes.")
+	builder.WriteString(fmt.Sprintf("jsonschema_properties %d\n", report.Properties))
+	gauge("jsonschema_documented_properties", "Number of properties with a description over all classes.")
+	builder.WriteString(fmt.Sprintf("jsonschema_documented_properties %d\n", report.DocumentedProperties))
+	gauge("jsonschema_documented_properties_ratio", "Ratio of properties with a description.")
+	builder.WriteString(fmt.Sprintf("jsonschema_documented_properties_ratio %g\n", report.DocumentedPercentage/100))
+	gauge("jsonschema_max_depth", "Maximum depth of a class from the roots.")
+	builder.WriteString(fmt.Sprintf("jsonschema_max_depth %d\n", report.MaxDepth))
+	gauge("jsonschema_components", "Number of strongly connected components.")
+	builder.WriteString(fmt.Sprintf("jsonschema_components %d\n", len(report.Components)))
+	gauge("jsonschema_largest_component_classes", "Number of classes in the largest strongly connected component.")
+	builder.WriteString(fmt.Sprintf("jsonschema_largest_component_classes %d\n", largestComponent(report)))
+	gauge("jsonschema_cycles", "Number of strongly connected components forming a cycle.")
+	builder.WriteString(fmt.Sprintf("jsonschema_cycles %d\n", len(report.Cycles)))
+	gauge("jsonschema_unreferenced_classes", "Number of classes without incoming relations.")
+	builder.WriteString(fmt.Sprintf("jsonschema_unreferenced_classes %d\n", len(report.Unreferenced)))
+
+	perClass := []struct {
+		name  string
+		help  string
+		value func(c *ClassStats) int
+	}{
+		{"jsonschema_class_properties", "Number of properties of a class.", func(c *ClassStats) int { return c.Properties }},
+		{"jsonschema_class_documented_properties", "Number of properties with a description of a class.", func(c *ClassStats) int { return c.DocumentedProperties }},
+		{"jsonschema_class_fan_in", "Number of relations pointing towards a class.", func(c *ClassStats) int { return c.FanIn }},
+		{"jsonschema_class_fan_out", "Number of relations originating from a class.", func(c *ClassStats) int { return c.FanOut }},
+		{"jsonschema_class_depth", "Depth of a class from the roots, -1 if unreachable.", func(c *ClassStats) int { return c.Depth }},
+	}
+
+	for _, metric := range perClass {
+		gauge(metric.name, metric.help)
+		for _, c := range report.Classes {
+			builder.WriteString(fmt.Sprintf("%s{class=\"%s\",source=\"%s\"} %d\n", metric.name, label(c.Name), label(c.Source), metric.value(c)))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// labelReplacer escapes label values as defined by the Prometheus text exposition format
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label value escaped for the Prometheus text exposition format
+func label(value string) string {
+	return labelReplacer.Replace(value)
+}
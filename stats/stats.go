@@ -0,0 +1,184 @@
+package stats
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Parser implementation that returns the domain.Class'es, domain.Relation's and the root jsonschema.Schema's
+type Parser interface {
+	Schemas() ([]*jsonschema.Schema, error)
+	Classes() ([]*domain.Class, error)
+	Relations() ([]*domain.Relation, error)
+}
+
+// ClassStats are the metrics of a single domain.Class
+type ClassStats struct {
+	// Class the metrics are computed for
+	Class *domain.Class `json:"-"`
+
+	// Name of the Class
+	Name string `json:"name"`
+
+	// Source of the Class
+	Source string `json:"source"`
+
+	// Properties of the Class
+	Properties int `json:"properties"`
+
+	// DocumentedProperties is the amount of Properties with a description
+	DocumentedProperties int `json:"documentedProperties"`
+
+	// FanIn is the amount of relations pointing towards the Class
+	FanIn int `json:"fanIn"`
+
+	// FanOut is the amount of relations originating from the Class
+	FanOut int `json:"fanOut"`
+
+	// Depth from the closest root (see parse.DepthMap) or parse.Unreachable
+	Depth int `json:"depth"`
+}
+
+// Report of the metrics over all domain.Class'es and domain.Relation's
+type Report struct {
+	// Classes with their metrics ordered by source and name
+	Classes []*ClassStats `json:"classes"`
+
+	// Relations is the amount of relations between classes
+	Relations int `json:"relations"`
+
+	// Properties is the amount of properties over all classes
+	Properties int `json:"properties"`
+
+	// DocumentedProperties is the amount of properties with a description over all classes
+	DocumentedProperties int `json:"documentedProperties"`
+
+	// DocumentedPercentage of the Properties that have a description
+	DocumentedPercentage float64 `json:"documentedPercentage"`
+
+	// MaxDepth of any reachable class
+	MaxDepth int `json:"maxDepth"`
+
+	// Components are the strongly connected components (by class name) ordered by their size, largest first
+	Components [][]string `json:"components"`
+
+	// Cycles are the strongly connected components (by class name) that form a cycle
+	Cycles [][]string `json:"cycles"`
+
+	// Largest classes by their amount of properties
+	Largest []*ClassStats `json:"largest"`
+
+	// Unreferenced classes which no relation points towards
+	Unreferenced []*ClassStats `json:"unreferenced"`
+}
+
+// Compute the Report where the top largest classes are included in Report.Largest
+func Compute(parser Parser, top int) (*Report, error) {
+	roots, err := parser.Schemas()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	classes, err := parser.Classes()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	relations, err := parser.Relations()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	depthMap := parse.DepthMap(roots, classes, relations)
+
+	report := &Report{Relations: len(relations), Components: [][]string{}, Cycles: [][]string{}, Largest: []*ClassStats{}, Unreferenced: []*ClassStats{}}
+	byClass := map[*domain.Class]*ClassStats{}
+	for _, class := range classes {
+		classStats := &ClassStats{
+			Class:      class,
+			Name:       class.Name,
+			Properties: len(class.Properties),
+			Depth:      parse.Unreachable,
+		}
+
+		if class.Source != nil {
+			classStats.Source = class.Source.Path()
+		}
+
+		for _, property := range class.Properties {
+			if property.Docstring != "" {
+				classStats.DocumentedProperties++
+			}
+		}
+
+		if depth, ok := depthMap[class]; ok {
+			classStats.Depth = depth
+			report.MaxDepth = max(report.MaxDepth, depth)
+		}
+
+		report.Properties += classStats.Properties
+		report.DocumentedProperties += classStats.DocumentedProperties
+		report.Classes = append(report.Classes, classStats)
+		byClass[class] = classStats
+	}
+
+	for _, relation := range relations {
+		if from, ok := byClass[relation.From]; ok {
+			from.FanOut++
+		}
+
+		if to, ok := byClass[relation.To]; ok && relation.From != relation.To {
+			to.FanIn++
+		}
+	}
+
+	if report.Properties > 0 {
+		report.DocumentedPercentage = 100 * float64(report.DocumentedProperties) / float64(report.Properties)
+	}
+
+	slices.SortStableFunc(report.Classes, func(a, b *ClassStats) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Name, b.Name))
+	})
+
+	for _, component := range parse.StronglyConnectedComponents(classes, relations) {
+		report.Components = append(report.Components, classNames(component))
+	}
+	slices.SortStableFunc(report.Components, func(a, b []string) int {
+		return cmp.Or(cmp.Compare(len(b), len(a)), slices.Compare(a, b))
+	})
+
+	for _, cycle := range parse.Cycles(classes, relations) {
+		report.Cycles = append(report.Cycles, classNames(cycle.Classes))
+	}
+	slices.SortFunc(report.Cycles, slices.Compare)
+
+	for _, classStats := range report.Classes {
+		if classStats.FanIn == 0 {
+			report.Unreferenced = append(report.Unreferenced, classStats)
+		}
+	}
+
+	report.Largest = slices.Clone(report.Classes)
+	slices.SortStableFunc(report.Largest, func(a, b *ClassStats) int {
+		return cmp.Compare(b.Properties, a.Properties)
+	})
+	report.Largest = report.Largest[:min(max(top, 0), len(report.Largest))]
+
+	return report, nil
+}
+
+// classNames of the domain.Class'es in sorted order
+func classNames(classes []*domain.Class) []string {
+	var res []string
+	for _, class := range classes {
+		res = append(res, class.Name)
+	}
+	slices.Sort(res)
+
+	return res
+}
@@ -0,0 +1,76 @@
+package stats
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/parse/parsetest"
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompute(t *testing.T) {
+	// Arrange
+	root, node, leaf, orphan := &jsonschema.Schema{}, &jsonschema.Schema{}, &jsonschema.Schema{}, &jsonschema.Schema{}
+	rootClass := &domain.Class{Schema: root, Name: "Root", Source: domain.FileSource{FilePath: "a.json"}, Properties: []*domain.Property{
+		{Name: "node", Type: "Node", Docstring: "the node"},
+	}}
+	nodeClass := &domain.Class{Schema: node, Name: "Node", Source: domain.FileSource{FilePath: "b.json"}, Properties: []*domain.Property{
+		{Name: "parent", Type: "Node"},
+		{Name: "leaf", Type: "Leaf", Docstring: "the leaf"},
+	}}
+	leafClass := &domain.Class{Schema: leaf, Name: "Leaf", Source: domain.FileSource{FilePath: "c.json"}, Properties: []*domain.Property{
+		{Name: "node", Type: "Node"},
+	}}
+	orphanClass := &domain.Class{Schema: orphan, Name: "Orphan", Source: domain.FileSource{FilePath: "d.json"}}
+
+	parser := &parsetest.Parser{
+		SchemaData: []*jsonschema.Schema{root},
+		ClassData:  []*domain.Class{rootClass, nodeClass, leafClass, orphanClass},
+		RelationsData: []*domain.Relation{
+			{From: rootClass, To: nodeClass},
+			{From: nodeClass, To: leafClass},
+			{From: leafClass, To: nodeClass},
+		},
+	}
+
+	// Act
+	report, err := Compute(parser, 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Properties)
+	assert.Equal(t, 2, report.DocumentedProperties)
+	assert.InDelta(t, 50.0, report.DocumentedPercentage, 0.001)
+	assert.Equal(t, 2, report.MaxDepth)
+	assert.Equal(t, [][]string{{"Leaf", "Node"}, {"Orphan"}, {"Root"}}, report.Components)
+	assert.Equal(t, [][]string{{"Leaf", "Node"}}, report.Cycles)
+	require.Len(t, report.Largest, 1)
+	assert.Equal(t, "Node", report.Largest[0].Name)
+	assert.Equal(t, []string{"Root", "Orphan"}, []string{report.Unreferenced[0].Name, report.Unreferenced[1].Name})
+
+	require.Len(t, report.Classes, 4)
+	assert.Equal(t, "Node", report.Classes[1].Name)
+	assert.Equal(t, 2, report.Classes[1].FanIn)
+	assert.Equal(t, 1, report.Classes[1].FanOut)
+	assert.Equal(t, parse.Unreachable, report.Classes[3].Depth)
+}
+
+func TestFormat_WritePrometheus(t *testing.T) {
+	// Arrange
+	report := &Report{Classes: []*ClassStats{{Name: `Pet "Store"`, Source: "store.json", Properties: 2}}, Components: [][]string{{`Pet "Store"`}}}
+	buffer := new(bytes.Buffer)
+
+	// Act
+	err := Prometheus.Write(buffer, report)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, buffer.String(), "# TYPE jsonschema_classes gauge\njsonschema_classes 1\n")
+	assert.Contains(t, buffer.String(), "\njsonschema_components 1\n")
+	assert.Contains(t, buffer.String(), "\njsonschema_largest_component_classes 1\n")
+	assert.Contains(t, buffer.String(), `jsonschema_class_properties{class="Pet \"Store\"",source="store.json"} 2`)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_WritesTable(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{statsCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--format", "table"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, outputBuffer.String(), "classes:       2")
+	assert.Contains(t, outputBuffer.String(), "documented:    100.0%")
+}
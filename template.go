@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
+	"github.com/Emptyless/jsonschema-transform/view"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// templateCmd registered to the rootCmd
+var templateCmd = &cobra.Command{
+	Use:          "template",
+	Short:        "render a Go template against the json schemas",
+	Long:         "render a Go text/template against the view model of the json schemas (classes, properties, relations, containers, roots and depth), once or once per class",
+	Example:      fmt.Sprintf("%s template --globs 'schemas/*.json' --template class.md.tmpl --per-class --filename '{{ .Class.Name | lower }}.md' -o docs", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleTemplate,
+}
+
+// init the templateCmd command
+func init() {
+	rootCmd.AddCommand(templateCmd)
+	globsFlag.Apply(templateCmd.Flags())
+	excludeFlag.Apply(templateCmd.Flags())
+	jobsFlag.Apply(templateCmd.Flags())
+	baseURIFlag.Apply(templateCmd.Flags())
+	templateFileFlag.Apply(templateCmd.Flags())
+	templateOutputFlag.Apply(templateCmd.Flags())
+	perClassFlag.Apply(templateCmd.Flags())
+	filenameFlag.Apply(templateCmd.Flags())
+	allowOverwriteFlag.Apply(templateCmd.Flags())
+	watchFlag.Apply(templateCmd.Flags())
+}
+
+// handleTemplate for the templateCmd command
+func handleTemplate(cmd *cobra.Command, _ []string) error {
+	templateFile := cmd.Flag(templateFileFlag.Name).Value.String()
+	if templateFile == "" {
+		return ErrNoTemplate
+	}
+
+	perClass, err := cmd.Flags().GetBool(perClassFlag.Name)
+	if err != nil {
+		return err
+	}
+
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	return runOrWatch(cmd, parser, func(overwrite bool) error {
+		return renderTemplate(cmd, parser, templateFile, perClass, overwrite)
+	}, templateFile)
+}
+
+// renderTemplate against the view.Model of the parser to the output, once or once per class. Existing files are only
+// replaced if overwrite is true
+func renderTemplate(cmd *cobra.Command, parser *parse.Parser, templateFile string, perClass bool, overwrite bool) error {
+	tpl, err := template.New(filepath.Base(templateFile)).Funcs(tmpl.Funcs()).ParseFiles(templateFile)
+	if err != nil {
+		return err
+	}
+
+	model, err := view.New(parser)
+	if err != nil {
+		return err
+	}
+
+	output := cmd.Flag(templateOutputFlag.Name).Value.String()
+	if !perClass {
+		return writeTemplate(cmd, tpl, model, output, overwrite)
+	}
+
+	filename, err := template.New(filenameFlag.Name).Funcs(tmpl.Funcs()).Parse(cmd.Flag(filenameFlag.Name).Value.String())
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		output = "."
+	}
+
+	// every file name is checked before writing such that an invalid name does not leave a partial output
+	names := make([]string, len(model.Classes))
+	classes := map[string]*view.Class{}
+	for i, class := range model.Classes {
+		var name strings.Builder
+		if err = filename.Execute(&name, model.ForClass(class)); err != nil {
+			return err
+		}
+
+		names[i] = filepath.Clean(name.String())
+		if !filepath.IsLocal(name.String()) || names[i] == "." {
+			return fmt.Errorf("%w: %q of class %q", ErrInvalidFilename, name.String(), class.Name)
+		} else if other, ok := classes[names[i]]; ok {
+			return fmt.Errorf("%w: %q of classes %q and %q", ErrDuplicateFilename, names[i], other.Name, class.Name)
+		}
+		classes[names[i]] = class
+	}
+
+	for i, class := range model.Classes {
+		if err = writeTemplate(cmd, tpl, model.ForClass(class), filepath.Join(output, names[i]), overwrite); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeTemplate executed with the view.Model to the output file (or stdout if '-')
+func writeTemplate(cmd *cobra.Command, tpl *template.Template, model *view.Model, output string, overwrite bool) error {
+	buffer := new(bytes.Buffer)
+	if err := tpl.Execute(buffer, model); err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err := cmd.OutOrStdout().Write(buffer.Bytes())
+		return err
+	}
+
+	if _, statErr := os.Stat(output); statErr == nil && !overwrite {
+		return ErrNoOverwrite
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(output, buffer.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	logrus.Info("template written to ", output)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_WritesStdout(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{templateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--template", "./testdata/templates/view/summary.tmpl"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "\nPet (depth 0, root)\n  -> Pet Store via store\nPet Store (depth 0, root)\n", outputBuffer.String())
+}
+
+func TestTemplate_PerClass(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	args := []string{templateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--template", "./testdata/templates/view/class.md.tmpl",
+		"--per-class", "--filename", `{{ .Class.Name | replace " " "-" | lower }}.md`, "--output", outputDir}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(outputDir, "pet-store.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "# Pet Store\n\na store to buy pets\n")
+	assert.FileExists(t, filepath.Join(outputDir, "pet.md"))
+}
+
+func TestTemplate_PerClassDefaultFilename(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	args := []string{templateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--template", "./testdata/templates/view/class.md.tmpl",
+		"--per-class", "--output", outputDir}
+	rootCmd.SetArgs(args)
+	resetFlags(templateCmd)
+	t.Cleanup(func() { resetFlags(templateCmd) })
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(outputDir, "pet-store.txt"))
+	assert.FileExists(t, filepath.Join(outputDir, "pet.txt"))
+}
+
+func TestTemplate_PerClassInvalidFilename(t *testing.T) {
+	tests := map[string]struct {
+		filename string
+		err      error
+	}{
+		"duplicate": {filename: "class.md", err: ErrDuplicateFilename},
+		"empty":     {filename: "", err: ErrInvalidFilename},
+		"parent":    {filename: "../{{ .Class.Slug }}.md", err: ErrInvalidFilename},
+		"absolute":  {filename: "/tmp/{{ .Class.Slug }}.md", err: ErrInvalidFilename},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			outputDir := filepath.Join(t.TempDir(), "output")
+			args := []string{templateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--template", "./testdata/templates/view/class.md.tmpl",
+				"--per-class", "--filename", test.filename, "--output", outputDir}
+			rootCmd.SetArgs(args)
+			t.Cleanup(func() { resetFlags(templateCmd) })
+
+			// Act
+			err := rootCmd.Execute()
+
+			// Assert
+			require.ErrorIs(t, err, test.err)
+			assert.NoDirExists(t, outputDir)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package tmpl contains the helper library available to user supplied text/template's
+package tmpl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidArguments is returned when a helper is called with invalid arguments
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+// Funcs returns the helper library. Helpers take the piped value as their last argument such that they can be used
+// in pipelines, e.g. '{{ .Name | replace " " "_" | lower }}'
+func Funcs() template.FuncMap {
+	return template.FuncMap{
+		// strings
+		"lower":      strings.ToLower,
+		"upper":      strings.ToUpper,
+		"title":      Title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"indent":     Indent,
+		"quote":      Quote,
+		"slug":       Slug,
+
+		// paths
+		"base": path.Base,
+		"dir":  path.Dir,
+		"ext":  path.Ext,
+		"stem": func(p string) string { return strings.TrimSuffix(path.Base(p), path.Ext(p)) },
+
+		// values
+		"default": Default,
+		"list":    func(values ...any) []any { return values },
+		"dict":    Dict,
+		"add":     func(a, b int) int { return a + b },
+		"sub":     func(a, b int) int { return a - b },
+		"json":    JSON,
+	}
+}
+
+// Title returns s with its first letter in upper case
+func Title(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+// Indent every line of input with amount spaces
+func Indent(amount int, input string) string {
+	lines := strings.Split(input, "\n")
+	var builder strings.Builder
+	for i, line := range lines {
+		builder.WriteString(strings.Repeat(" ", amount) + line)
+		if i != len(lines)-1 {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Quote s as a double-quoted string, escaping backslashes, double quotes and newlines
+func Quote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// Slug of s for use in file names and URLs: lower case letters and digits separated by dashes
+func Slug(s string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+
+	return builder.String()
+}
+
+// Default returns value unless it is the zero value or an empty slice or map, then def is returned
+func Default(def any, value any) any {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.IsZero() {
+		return def
+	}
+
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return def
+	}
+
+	return value
+}
+
+// Dict creates a map from key value pairs, e.g. '{{ template "row" dict "Class" $class "Depth" 1 }}'
+func Dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("%w: dict expects key value pairs", ErrInvalidArguments)
+	}
+
+	res := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: dict key %v is not a string", ErrInvalidArguments, pairs[i])
+		}
+		res[key] = pairs[i+1]
+	}
+
+	return res, nil
+}
+
+// JSON representation of value
+func JSON(value any) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
@@ -0,0 +1,87 @@
+package tmpl
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuncs(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		data     any
+		expected string
+	}{
+		"pipeline": {
+			template: `{{ . | replace " " "_" | lower }}`,
+			data:     "Pet Store",
+			expected: "pet_store",
+		},
+		"title": {
+			template: `{{ title . }}`,
+			data:     "pet",
+			expected: "Pet",
+		},
+		"quote": {
+			template: `{{ quote . }}`,
+			data:     "a \"friendly\"\nanimal",
+			expected: `"a \"friendly\"\nanimal"`,
+		},
+		"default": {
+			template: `{{ . | default "none" }}`,
+			data:     "",
+			expected: "none",
+		},
+		"indent": {
+			template: `{{ indent 2 . }}`,
+			data:     "a\nb",
+			expected: "  a\n  b",
+		},
+		"stem": {
+			template: `{{ stem . }}`,
+			data:     "schemas/pet.json",
+			expected: "pet",
+		},
+		"slug": {
+			template: `{{ slug . }}`,
+			data:     " Pet Store (v2)",
+			expected: "pet-store-v2",
+		},
+		"dict": {
+			template: `{{ with dict "name" . }}{{ .name | upper }}{{ end }}`,
+			data:     "pet",
+			expected: "PET",
+		},
+		"json": {
+			template: `{{ json . }}`,
+			data:     []string{"a", "b"},
+			expected: `["a","b"]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			tpl := template.Must(template.New(name).Funcs(Funcs()).Parse(test.template))
+			var builder strings.Builder
+
+			// Act
+			err := tpl.Execute(&builder, test.data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, builder.String())
+		})
+	}
+}
+
+func TestDict_InvalidArguments(t *testing.T) {
+	// Act
+	_, err := Dict("key")
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidArguments)
+}
@@ -0,0 +1,59 @@
+package transform
+
+import (
+	"errors"
+	"path"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/d2"
+)
+
+// D2Name of the D2 Transformer
+const D2Name = "d2"
+
+// ErrContainerBaseURI is returned when a container base path is used without a file:// based base URI
+var ErrContainerBaseURI = errors.New("container base path requires a file:// based base URI")
+
+// D2Options used by the D2 Transformer
+type D2Options struct {
+	// Config used when rendering D2, if nil the D2 native format is rendered with the defaults (see d2.D2)
+	Config *d2.Config
+}
+
+// D2 Transformer rendering the diagram with d2.D2
+type D2 struct {
+	options D2Options
+}
+
+// NewD2 Transformer for the D2Options
+func NewD2(opts D2Options) *D2 {
+	return &D2{options: opts}
+}
+
+// Name of the D2 Transformer
+func (t *D2) Name() string {
+	return D2Name
+}
+
+// Transform the Parser into a D2 diagram in the format of the d2.Config
+func (t *D2) Transform(parser Parser) ([]byte, error) {
+	var cfg *d2.Config
+	if t.options.Config != nil {
+		copied := *t.options.Config
+		cfg = &copied // d2.D2 defaults the Config in place
+	}
+
+	return d2.D2(parser, cfg)
+}
+
+// ContainerBasePath resolves the containerBasePath relative to the file based baseURI (see ResolveBaseURI) such that
+// it can be used as d2.Config ContainerBasePath. Returns ErrContainerBaseURI if the baseURI is not file based
+func ContainerBasePath(baseURI string, containerBasePath string) (string, error) {
+	if containerBasePath == "" {
+		return "", nil
+	} else if baseURI == "" || HasHTTPPrefix(baseURI) {
+		return "", ErrContainerBaseURI
+	}
+
+	return path.Join(strings.TrimPrefix(baseURI, "file://"), containerBasePath), nil
+}
@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+)
+
+// ErrNoGlobs is returned when no globs are provided (which is a no-op)
+var ErrNoGlobs = errors.New("no globs provided")
+
+// Options used to construct a parse.Parser, see NewParser
+type Options struct {
+	// Globs to search for JSON files (e.g. '**/*.json', '*.json', 'file.json')
+	Globs []string
+
+	// Exclude files matched by the Globs that match one of these patterns (see filepath.Match)
+	Exclude []string
+
+	// BaseURI used to resolve the $id of schemas. If it does not start with 'http' or 'https' it's assumed to be a
+	// file path which is resolved to an absolute 'file://' URI
+	BaseURI string
+
+	// Depth of external $refs to follow, if nil all $refs are followed
+	Depth *int
+
+	// Jobs is the number of files read concurrently, if <= 0 the number of CPUs is used
+	Jobs int
+
+	// FS the Globs are matched against and the schemas are read from, if nil the files are read from disk. A file
+	// based BaseURI is taken relative to the root of the FS
+	FS fs.FS
+
+	// StrictMode will error if a JSON file contained in the Globs cannot be parsed to a schema instead of logging a
+	// warning
+	StrictMode bool
+}
+
+// NewParser for the Options
+func NewParser(opts Options) (*parse.Parser, error) {
+	if len(opts.Globs) == 0 {
+		return nil, ErrNoGlobs
+	}
+
+	parser := parse.NewParser(opts.Globs...).SetExclude(opts.Exclude...)
+	parser.StrictMode = opts.StrictMode
+	parser.SetJobs(opts.Jobs)
+	if opts.Depth != nil {
+		parser.SetDepth(*opts.Depth)
+	}
+
+	if opts.FS != nil {
+		parser.SetFS(opts.FS)
+	}
+
+	baseURI, err := ResolveBaseURI(opts.BaseURI, opts.FS)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.SetBaseURI(baseURI), nil
+}
+
+// ResolveBaseURI to an absolute 'file://' URI unless it is empty or has an HTTP prefix (see HasHTTPPrefix). If fsys
+// is set the file path is taken relative to its root instead of the working directory
+func ResolveBaseURI(baseURI string, fsys fs.FS) (string, error) {
+	switch {
+	case baseURI == "" || HasHTTPPrefix(baseURI):
+		return baseURI, nil
+	case fsys != nil:
+		return "file://" + path.Join("/", strings.TrimPrefix(baseURI, "file://")), nil
+	default:
+		baseURIAbs, err := filepath.Abs(strings.TrimPrefix(baseURI, "file://"))
+		if err != nil {
+			return "", err
+		}
+
+		return "file://" + baseURIAbs, nil
+	}
+}
+
+// HasHTTPPrefix checks if the baseURI starts with either http or https
+func HasHTTPPrefix(baseURI string) bool {
+	return baseURI != "" && (strings.HasPrefix(baseURI, "http") || strings.HasPrefix(baseURI, "https"))
+}
@@ -0,0 +1,86 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownTransformer is returned when no Transformer is registered by the name
+var ErrUnknownTransformer = errors.New("unknown transformer")
+
+// ErrDuplicateTransformer is returned when a Transformer is already registered by the name
+var ErrDuplicateTransformer = errors.New("transformer already registered")
+
+// Registry of Transformer's by their Name
+type Registry struct {
+	mu           sync.RWMutex
+	transformers map[string]Transformer
+}
+
+// NewRegistry containing the transformers
+func NewRegistry(transformers ...Transformer) (*Registry, error) {
+	registry := &Registry{}
+	for _, transformer := range transformers {
+		if err := registry.Register(transformer); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// Register the Transformer by its Name or return ErrDuplicateTransformer if the name is taken
+func (r *Registry) Register(transformer Transformer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.transformers[transformer.Name()]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTransformer, transformer.Name())
+	}
+
+	if r.transformers == nil {
+		r.transformers = map[string]Transformer{}
+	}
+	r.transformers[transformer.Name()] = transformer
+
+	return nil
+}
+
+// Lookup the Transformer registered by the name or return ErrUnknownTransformer
+func (r *Registry) Lookup(name string) (Transformer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	transformer, ok := r.transformers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTransformer, name)
+	}
+
+	return transformer, nil
+}
+
+// Names of the registered Transformer's in sorted order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(r.transformers))
+}
+
+// DefaultRegistry contains the built-in Transformer's rendering with their default options
+var DefaultRegistry = &Registry{transformers: map[string]Transformer{
+	D2Name: NewD2(D2Options{}),
+}}
+
+// Register the Transformer in the DefaultRegistry
+func Register(transformer Transformer) error {
+	return DefaultRegistry.Register(transformer)
+}
+
+// Lookup the Transformer in the DefaultRegistry
+func Lookup(name string) (Transformer, error) {
+	return DefaultRegistry.Lookup(name)
+}
@@ -0,0 +1,24 @@
+// Package transform exposes the transformation of JSON schemas into diagrams (and other formats) for use as a library.
+//
+// A parse.Parser is constructed from Options with NewParser after which a Transformer (e.g. D2) renders its classes
+// and relations. Additional formats can be made available by name with Register.
+package transform
+
+import (
+	"github.com/Emptyless/jsonschema-transform/domain"
+)
+
+// Parser implementation that returns the domain.Class and domain.Relation slices to transform, e.g. parse.Parser
+type Parser interface {
+	Classes() ([]*domain.Class, error)
+	Relations() ([]*domain.Relation, error)
+}
+
+// Transformer of the classes and relations of a Parser into an output format
+type Transformer interface {
+	// Name of the output format (e.g. 'd2') used to register the Transformer
+	Name() string
+
+	// Transform the Parser into the output
+	Transform(parser Parser) ([]byte, error)
+}
@@ -0,0 +1,142 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFS = fstest.MapFS{
+	"schemas/pet.json": {Data: []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "file:///schemas/pet.json",
+		"title": "Pet",
+		"type": "object",
+		"properties": {
+			"owner": {"$ref": "owner.json"}
+		}
+	}`)},
+	"schemas/owner.json": {Data: []byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "file:///schemas/owner.json",
+		"title": "Owner",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"}
+		}
+	}`)},
+}
+
+func TestNewParser_FS(t *testing.T) {
+	// Arrange
+	parser, err := NewParser(Options{Globs: []string{"schemas/pet.json"}, FS: testFS})
+	require.NoError(t, err)
+
+	// Act
+	classes, err := parser.Classes()
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+	assert.Equal(t, "Owner", classes[0].Name)
+	assert.Equal(t, "Pet", classes[1].Name)
+	assert.Equal(t, []string{"schemas/owner.json", "schemas/pet.json"}, parser.Files(), "referenced files are read from the FS as well")
+}
+
+func TestNewParser_NoGlobs(t *testing.T) {
+	// Act
+	_, err := NewParser(Options{})
+
+	// Assert
+	require.ErrorIs(t, err, ErrNoGlobs)
+}
+
+func TestResolveBaseURI(t *testing.T) {
+	tests := map[string]struct {
+		baseURI  string
+		fs       bool
+		expected string
+	}{
+		"empty":   {baseURI: "", expected: ""},
+		"http":    {baseURI: "https://acme.org/schemas", expected: "https://acme.org/schemas"},
+		"fs":      {baseURI: "schemas", fs: true, expected: "file:///schemas"},
+		"fs root": {baseURI: "./", fs: true, expected: "file:///"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			var fsys fstest.MapFS
+			if tt.fs {
+				fsys = testFS
+			}
+
+			// Act
+			baseURI, err := ResolveBaseURI(tt.baseURI, fsys)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, baseURI)
+		})
+	}
+}
+
+func TestContainerBasePath(t *testing.T) {
+	// Act
+	containerBasePath, err := ContainerBasePath("file:///schemas", "pets")
+	_, httpErr := ContainerBasePath("https://acme.org/schemas", "pets")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/schemas/pets", containerBasePath)
+	require.ErrorIs(t, httpErr, ErrContainerBaseURI)
+}
+
+func TestD2_Transform(t *testing.T) {
+	// Arrange
+	parser, err := NewParser(Options{Globs: []string{"schemas/*.json"}, FS: testFS})
+	require.NoError(t, err)
+	transformer, err := Lookup(D2Name)
+	require.NoError(t, err)
+
+	// Act
+	output, err := transformer.Transform(parser)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(output), "Pet")
+	assert.Contains(t, string(output), "Owner")
+}
+
+func TestRegistry(t *testing.T) {
+	// Arrange
+	registry, err := NewRegistry(NewD2(D2Options{}), &TestTransformer{name: "json"})
+	require.NoError(t, err)
+
+	// Act
+	duplicateErr := registry.Register(&TestTransformer{name: "json"})
+	transformer, lookupErr := registry.Lookup("json")
+	_, unknownErr := registry.Lookup("puml")
+
+	// Assert
+	require.ErrorIs(t, duplicateErr, ErrDuplicateTransformer)
+	require.NoError(t, lookupErr)
+	assert.Equal(t, "json", transformer.Name())
+	require.ErrorIs(t, unknownErr, ErrUnknownTransformer)
+	assert.Equal(t, []string{"d2", "json"}, registry.Names())
+}
+
+type TestTransformer struct {
+	name string
+}
+
+func (t *TestTransformer) Name() string {
+	return t.name
+}
+
+func (t *TestTransformer) Transform(_ Parser) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
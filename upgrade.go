@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/upgrade"
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// upgradeCmd registered to the rootCmd
+var upgradeCmd = &cobra.Command{
+	Use:          "upgrade",
+	Short:        "upgrade draft-04/06/07 and 2019-09 json schemas to 2020-12",
+	Long:         "upgrade the json schemas to 2020-12 in place (or to --output) keeping the order of their keys and their indentation, and report every change made. With --data the instances are validated against their schema (selected like the validate command does) before and after upgrading it and nothing is written if any outcome changed",
+	Example:      fmt.Sprintf("%s upgrade --globs 'schemas/*.json' --data 'samples/*.json' --output upgraded", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleUpgrade,
+}
+
+// init the upgradeCmd command
+func init() {
+	rootCmd.AddCommand(upgradeCmd)
+	globsFlag.Apply(upgradeCmd.Flags())
+	excludeFlag.Apply(upgradeCmd.Flags())
+	baseURIFlag.Apply(upgradeCmd.Flags())
+	dataFlag.Apply(upgradeCmd.Flags())
+	schemaIDFlag.Apply(upgradeCmd.Flags())
+	schemaMapFlag.Apply(upgradeCmd.Flags())
+	upgradeOutputFlag.Apply(upgradeCmd.Flags())
+	allowOverwriteFlag.Apply(upgradeCmd.Flags())
+}
+
+// handleUpgrade for the upgradeCmd command
+func handleUpgrade(cmd *cobra.Command, _ []string) error {
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	matches, err := parser.Matches()
+	if err != nil {
+		return err
+	}
+
+	var results []*upgrade.Result
+	for _, match := range matches {
+		contents, readFileErr := os.ReadFile(match)
+		if readFileErr != nil {
+			return readFileErr
+		}
+
+		result, upgradeErr := upgrade.Upgrade(match, contents)
+		if upgradeErr != nil {
+			return upgradeErr
+		}
+		results = append(results, result)
+	}
+
+	if err = writeUpgradeReport(cmd.OutOrStdout(), results); err != nil {
+		return err
+	}
+
+	if dataGlobs := cmd.Flag(dataFlag.Name).Value.(pflag.SliceValue).GetSlice(); len(dataGlobs) > 0 {
+		if err = verifyUpgrade(cmd, parser, results, dataGlobs); err != nil {
+			return err
+		}
+	}
+
+	return writeUpgrade(results, cmd.Flag(upgradeOutputFlag.Name).Value.String(), cmd.Flag(allowOverwriteFlag.Name).Value.String() == "true")
+}
+
+// writeUpgradeReport with the changes made to every schema
+func writeUpgradeReport(w io.Writer, results []*upgrade.Result) error {
+	for _, result := range results {
+		if len(result.Changes) == 0 {
+			if _, err := fmt.Fprintf(w, "%s: already 2020-12\n", result.Path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := fmt.Fprintf(w, "%s: %d change(s)\n", result.Path, len(result.Changes)); err != nil {
+			return err
+		}
+
+		for _, change := range result.Changes {
+			if _, err := fmt.Fprintf(w, "  %s\n", change); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// verifyUpgrade by validating the instances against their schema as written (see upgrade.Validator) and against the
+// upgraded schema compiled as 2020-12. The schema of an instance is selected as the validateCmd does
+func verifyUpgrade(cmd *cobra.Command, parser *parse.Parser, results []*upgrade.Result, dataGlobs []string) error {
+	mappings, err := schemaMappings(cmd)
+	if err != nil {
+		return err
+	}
+
+	instances, err := readInstances(dataGlobs)
+	if err != nil {
+		return err
+	}
+
+	compiler, err := parse.NewCompiler(parser.BaseURI)
+	if err != nil {
+		return err
+	}
+
+	before, err := upgrade.NewValidator(results, compiler.Loaders)
+	if err != nil {
+		return err
+	}
+
+	after, err := upgrade.Compile(compiler, results)
+	if err != nil {
+		return err
+	}
+
+	selector := &validate.Validator{
+		Compiler: compiler,
+		SchemaID: cmd.Flag(schemaIDFlag.Name).Value.String(),
+		Mappings: mappings,
+	}
+
+	mismatches, err := upgrade.Verify(before, after, results, instances, selector)
+	if err != nil {
+		return err
+	}
+
+	for _, mismatch := range mismatches {
+		if _, err = fmt.Fprintln(cmd.OutOrStdout(), mismatch); err != nil {
+			return err
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%w: %d mismatch(es)", ErrValidationChanged, len(mismatches))
+	}
+
+	logrus.Infof("%d instance(s) have the same validation outcome after upgrading", len(instances))
+
+	return nil
+}
+
+// writeUpgrade of the schemas in place, or to the output directory keeping their path relative to the working
+// directory. In place only the schemas that changed are written, in the output directory no file is written if any of
+// them exists unless overwrite is true
+func writeUpgrade(results []*upgrade.Result, output string, overwrite bool) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	paths := map[*upgrade.Result]string{}
+	for _, result := range results {
+		if output == "" {
+			if len(result.Changes) > 0 {
+				paths[result] = result.Path
+			}
+			continue
+		}
+
+		path := filepath.Join(output, relativePath(cwd, result.Path))
+		if _, statErr := os.Stat(path); statErr == nil && !overwrite {
+			return fmt.Errorf("%w: %s", ErrNoOverwrite, path)
+		}
+		paths[result] = path
+	}
+
+	for _, result := range results {
+		path, ok := paths[result]
+		if !ok {
+			continue
+		}
+
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(path, result.Contents, 0o644); err != nil {
+			return err
+		}
+
+		logrus.Info("upgraded schema written to ", path)
+	}
+
+	return nil
+}
+
+// relativePath of the file to the working directory, or its base name if the file is outside the working directory
+func relativePath(cwd string, file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.Base(file)
+	}
+
+	rel, err := filepath.Rel(cwd, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.Base(file)
+	}
+
+	return rel
+}
@@ -0,0 +1,174 @@
+package upgrade
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+)
+
+// source of a value in the original document, used to copy the values that are not changed by upgrading as written
+type source struct {
+	// raw bytes of the value in the original document
+	raw []byte
+
+	// compact raw bytes, computed on first use
+	compact []byte
+
+	// keys of the members of an object in order
+	keys []string
+
+	// members of an object by their key
+	members map[string]*source
+
+	// items of an array
+	items []*source
+}
+
+// decodeSource of the original document
+func decodeSource(contents []byte) (*source, error) {
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+
+	return decodeSourceValue(decoder, contents)
+}
+
+// decodeSourceValue of the next token(s) of the decoder
+func decodeSourceValue(decoder *json.Decoder, contents []byte) (*source, error) {
+	// the offset is at the end of the previous token, i.e. before the whitespace and the ':' or ',' of the value
+	start := int(decoder.InputOffset())
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	for start < len(contents) && bytes.IndexByte([]byte(" \t\r\n:,"), contents[start]) >= 0 {
+		start++
+	}
+
+	res := &source{}
+	switch token {
+	case json.Delim('{'):
+		res.members = map[string]*source{}
+		for decoder.More() {
+			key, keyErr := decoder.Token()
+			if keyErr != nil {
+				return nil, keyErr
+			}
+
+			member, memberErr := decodeSourceValue(decoder, contents)
+			if memberErr != nil {
+				return nil, memberErr
+			}
+
+			if _, exists := res.members[key.(string)]; !exists {
+				res.keys = append(res.keys, key.(string))
+			}
+			res.members[key.(string)] = member
+		}
+
+		if _, err = decoder.Token(); err != nil { // '}'
+			return nil, err
+		}
+	case json.Delim('['):
+		for decoder.More() {
+			item, itemErr := decodeSourceValue(decoder, contents)
+			if itemErr != nil {
+				return nil, itemErr
+			}
+			res.items = append(res.items, item)
+		}
+
+		if _, err = decoder.Token(); err != nil { // ']'
+			return nil, err
+		}
+	}
+
+	res.raw = contents[start:decoder.InputOffset()]
+
+	return res, nil
+}
+
+// equals returns true iff the value is unchanged, i.e. it encodes to the compact raw bytes
+func (s *source) equals(value any) bool {
+	if s.compact == nil {
+		var buffer bytes.Buffer
+		if err := json.Compact(&buffer, s.raw); err != nil {
+			return false
+		}
+		s.compact = buffer.Bytes()
+	}
+
+	encoded, err := parse.EncodeOrdered(value)
+
+	return err == nil && bytes.Equal(encoded, s.compact)
+}
+
+// multiline returns true iff the value spans more than one line in the original document
+func (s *source) multiline() bool {
+	return bytes.ContainsAny(s.raw, "\n")
+}
+
+// member of an object in the original document that the value of the key in the upgraded object originates from:
+//   - the member with the same key if its value has the same kind (object, array or scalar)
+//   - the member with the same value, e.g. 'items' renamed to 'prefixItems'
+//   - the first member with the same kind whose key is not in the upgraded object, e.g. 'definitions' renamed to
+//     '$defs' while its subschemas are upgraded
+//
+// or nil if there is none. Members in used are skipped and the member returned is added to used
+func (s *source) member(object *parse.Object, key string, value any, used map[*source]bool) *source {
+	if s == nil || s.members == nil {
+		return nil
+	}
+
+	var res *source
+	if member, ok := s.members[key]; ok && !used[member] && member.sameKind(value) {
+		res = member
+	}
+
+	for _, k := range s.keys {
+		if res != nil {
+			break
+		}
+
+		if member := s.members[k]; !used[member] && member.equals(value) {
+			res = member
+		}
+	}
+
+	for _, k := range s.keys {
+		if res != nil {
+			break
+		}
+
+		if _, exists := object.Get(k); !exists && !used[s.members[k]] && s.members[k].sameKind(value) {
+			res = s.members[k]
+		}
+	}
+
+	if res != nil {
+		used[res] = true
+	}
+
+	return res
+}
+
+// item of an array in the original document at the index if its value has the same kind, or nil if there is none
+func (s *source) item(i int, value any) *source {
+	if s == nil || i >= len(s.items) || !s.items[i].sameKind(value) {
+		return nil
+	}
+
+	return s.items[i]
+}
+
+// sameKind returns true iff the value and the original value are both objects, both arrays or both scalars
+func (s *source) sameKind(value any) bool {
+	switch value.(type) {
+	case *parse.Object:
+		return s.raw[0] == '{'
+	case []any:
+		return s.raw[0] == '['
+	default:
+		return s.raw[0] != '{' && s.raw[0] != '['
+	}
+}
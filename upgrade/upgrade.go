@@ -0,0 +1,233 @@
+// Package upgrade rewrites draft-04, draft-06, draft-07 and 2019-09 schema documents into 2020-12
+package upgrade
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// ErrUpgradingDocument is returned when a document cannot be upgraded, e.g. because it is not valid JSON
+var ErrUpgradingDocument = errors.New("could not upgrade document")
+
+// Result of upgrading a schema document
+type Result struct {
+	// Path of the schema document
+	Path string
+
+	// Original contents of the document
+	Original []byte
+
+	// Contents of the upgraded document, equal to the original contents if there are no Changes
+	Contents []byte
+
+	// Changes made to upgrade the document
+	Changes []parse.Change
+}
+
+// Upgrade the schema document at path to 2020-12 (see parse.Upgrade). The order of the keys and the indentation of
+// the document are kept, the values that are not changed are copied as written and objects and arrays written on a
+// single line stay on a single line. A document that is already 2020-12 is returned as is
+func Upgrade(path string, contents []byte) (*Result, error) {
+	document, err := parse.DecodeOrdered(contents)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrUpgradingDocument, path, err)
+	}
+
+	changes := parse.Upgrade(document)
+	if len(changes) == 0 {
+		return &Result{Path: path, Original: contents, Contents: contents}, nil
+	}
+
+	original, err := decodeSource(contents)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrUpgradingDocument, path, err)
+	}
+
+	var buffer bytes.Buffer
+	p := &printer{
+		buffer:       &buffer,
+		indent:       indentation(contents),
+		inlineArrays: inlineArrays.Match(contents),
+		spaced:       spaced.Match(contents),
+	}
+	if err = p.print(document, original, 0, false); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrUpgradingDocument, path, err)
+	}
+
+	if bytes.HasSuffix(contents, []byte("\n")) {
+		buffer.WriteByte('\n')
+	}
+
+	return &Result{Path: path, Original: contents, Contents: buffer.Bytes(), Changes: changes}, nil
+}
+
+// Compile the upgraded documents in order as 2020-12 schemas, i.e. without normalising them as the parse.Parser does
+func Compile(compiler *jsonschema.Compiler, results []*Result) ([]*jsonschema.Schema, error) {
+	var res []*jsonschema.Schema
+	for _, result := range results {
+		schema, err := compiler.Compile(result.Contents)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", result.Path, errors.Join(parse.ErrParsingSchema, err))
+		}
+
+		if schema, err = compiler.GetSchema(schema.GetSchemaURI()); err != nil {
+			return nil, fmt.Errorf("%s: %w", result.Path, err)
+		}
+
+		res = append(res, schema)
+	}
+
+	parse.ResolveReferences(compiler, res...)
+
+	return res, nil
+}
+
+// inlineArrays matches a document that contains an array (with at least one item) on a single line
+var inlineArrays = regexp.MustCompile(`\[[ \t]*[^\s\[\]{][^\n]*\]`)
+
+// spaced matches a document that writes a space after the colon of a key
+var spaced = regexp.MustCompile(`":[ \t]`)
+
+// indentation of the document, i.e. the leading whitespace of the first indented line or "" for a compact document
+func indentation(contents []byte) string {
+	for _, line := range strings.Split(string(contents), "\n")[1:] {
+		if trimmed := strings.TrimLeft(line, " \t"); trimmed != line && trimmed != "" {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+
+	return ""
+}
+
+// printer of a document decoded by parse.DecodeOrdered
+type printer struct {
+	buffer *bytes.Buffer
+
+	// indent per level, if empty the document is printed compact
+	indent string
+
+	// inlineArrays prints arrays of scalars on a single line
+	inlineArrays bool
+
+	// spaced prints a space after the colon of a key and after the comma of an inline member
+	spaced bool
+}
+
+// print the value at the level of indentation, copying the source in the original document if the value is unchanged.
+// The value is printed on a single line if its source is, or if it has no source and its parent is printed inline
+func (p *printer) print(value any, original *source, level int, inline bool) error {
+	if original != nil && original.equals(value) {
+		p.buffer.Write(original.raw)
+		return nil
+	}
+
+	if original != nil {
+		inline = !original.multiline()
+	}
+
+	switch value := value.(type) {
+	case *parse.Object:
+		if len(value.Keys()) == 0 {
+			p.buffer.WriteString("{}")
+			return nil
+		}
+
+		used := map[*source]bool{}
+		p.buffer.WriteByte('{')
+		for i, key := range value.Keys() {
+			p.separator(i, level+1, inline)
+
+			encoded, err := parse.EncodeOrdered(key)
+			if err != nil {
+				return err
+			}
+			p.buffer.Write(encoded)
+			p.buffer.WriteByte(':')
+			if p.spaced {
+				p.buffer.WriteByte(' ')
+			}
+
+			child, _ := value.Get(key)
+			if err = p.print(child, original.member(value, key, child, used), level+1, inline); err != nil {
+				return err
+			}
+		}
+		p.close(level, inline)
+		p.buffer.WriteByte('}')
+	case []any:
+		if len(value) == 0 {
+			p.buffer.WriteString("[]")
+			return nil
+		}
+
+		if original == nil && p.inlineArrays && scalars(value) {
+			inline = true
+		}
+
+		p.buffer.WriteByte('[')
+		for i, item := range value {
+			p.separator(i, level+1, inline)
+			if err := p.print(item, original.item(i, item), level+1, inline); err != nil {
+				return err
+			}
+		}
+		p.close(level, inline)
+		p.buffer.WriteByte(']')
+	default:
+		encoded, err := parse.EncodeOrdered(value)
+		if err != nil {
+			return err
+		}
+		p.buffer.Write(encoded)
+	}
+
+	return nil
+}
+
+// separator before the i-th member of an object or array, inline a space follows the comma if the document is spaced
+func (p *printer) separator(i int, level int, inline bool) {
+	if i > 0 {
+		p.buffer.WriteByte(',')
+	}
+
+	if !inline {
+		p.newline(level)
+	} else if i > 0 && p.spaced {
+		p.buffer.WriteByte(' ')
+	}
+}
+
+// close an object or array, i.e. the newline before its closing bracket unless it is printed inline
+func (p *printer) close(level int, inline bool) {
+	if !inline {
+		p.newline(level)
+	}
+}
+
+// newline followed by the indentation of the level, or nothing if the document is compact
+func (p *printer) newline(level int) {
+	if p.indent == "" {
+		return
+	}
+
+	p.buffer.WriteByte('\n')
+	p.buffer.WriteString(strings.Repeat(p.indent, level))
+}
+
+// scalars returns true iff none of the values is an object or array
+func scalars(values []any) bool {
+	for _, value := range values {
+		switch value.(type) {
+		case *parse.Object, []any:
+			return false
+		}
+	}
+
+	return true
+}
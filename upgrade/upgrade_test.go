@@ -0,0 +1,235 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade_KeepsOrderAndIndentation(t *testing.T) {
+	// Arrange
+	contents := "{\n" +
+		"\t\"$schema\": \"http://json-schema.org/draft-07/schema#\",\n" +
+		"\t\"title\": \"Pet\",\n" +
+		"\t\"required\": [\"name\"],\n" +
+		"\t\"properties\": {\n" +
+		"\t\t\"name\": {\"type\": \"string\", \"pattern\": \"^<[a-z]+>$\"},\n" +
+		"\t\t\"owner\": {\"$ref\": \"#/definitions/owner\"}\n" +
+		"\t},\n" +
+		"\t\"definitions\": {\n" +
+		"\t\t\"owner\": {\"type\": \"object\"}\n" +
+		"\t}\n" +
+		"}\n"
+
+	// Act
+	result, err := Upgrade("pet.json", []byte(contents))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "{\n"+
+		"\t\"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n"+
+		"\t\"title\": \"Pet\",\n"+
+		"\t\"required\": [\"name\"],\n"+
+		"\t\"properties\": {\n"+
+		"\t\t\"name\": {\"type\": \"string\", \"pattern\": \"^<[a-z]+>$\"},\n"+
+		"\t\t\"owner\": {\"$ref\": \"#/$defs/owner\"}\n"+
+		"\t},\n"+
+		"\t\"$defs\": {\n"+
+		"\t\t\"owner\": {\"type\": \"object\"}\n"+
+		"\t}\n"+
+		"}\n", string(result.Contents))
+	assert.Equal(t, []parse.Change{
+		{Pointer: "#", Description: "replaced '$schema' http://json-schema.org/draft-07/schema# with https://json-schema.org/draft/2020-12/schema"},
+		{Pointer: "#", Description: "renamed 'definitions' to '$defs'"},
+		{Pointer: "#/properties/owner", Description: "rewrote '$ref' #/definitions/owner to #/$defs/owner"},
+	}, result.Changes)
+}
+
+func TestUpgrade_KeepsUnchangedValuesAsWritten(t *testing.T) {
+	tests := map[string]struct {
+		contents string
+		expected string
+	}{
+		"compact": {
+			contents: `{"$schema":"http://json-schema.org/draft-07/schema#","properties":{"a":{"$ref":"#/definitions/a"}},"definitions":{"a":{"enum":[1, 2]}}}`,
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","properties":{"a":{"$ref":"#/$defs/a"}},"$defs":{"a":{"enum":[1, 2]}}}`,
+		},
+		"spacing": {
+			contents: "{\n" +
+				"  \"$schema\": \"http://json-schema.org/draft-07/schema#\",\n" +
+				"  \"enum\":  [ \"a\",\"b\" ],\n" +
+				"  \"items\": [ {\"type\" : \"string\"} ],\n" +
+				"  \"additionalItems\": {\"$ref\": \"#/definitions/b\"},\n" +
+				"  \"definitions\": {\n" +
+				"    \"b\": {\n" +
+				"      \"type\":\"boolean\"\n" +
+				"    }\n" +
+				"  }\n" +
+				"}",
+			expected: "{\n" +
+				"  \"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n" +
+				"  \"enum\": [ \"a\",\"b\" ],\n" +
+				"  \"prefixItems\": [ {\"type\" : \"string\"} ],\n" +
+				"  \"items\": {\"$ref\": \"#/$defs/b\"},\n" +
+				"  \"$defs\": {\n" +
+				"    \"b\": {\n" +
+				"      \"type\":\"boolean\"\n" +
+				"    }\n" +
+				"  }\n" +
+				"}",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			result, err := Upgrade("schema.json", []byte(tt.contents))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result.Contents))
+		})
+	}
+}
+
+func TestUpgrade_AlreadyUpgraded(t *testing.T) {
+	// Arrange
+	contents := []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "type": "object"}`)
+
+	// Act
+	result, err := Upgrade("pet.json", contents)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, result.Changes)
+	assert.Equal(t, contents, result.Contents)
+}
+
+func TestUpgrade_InvalidDocument(t *testing.T) {
+	// Act
+	_, err := Upgrade("pet.json", []byte(`{"$schema": `))
+
+	// Assert
+	require.ErrorIs(t, err, ErrUpgradingDocument)
+}
+
+func TestVerify(t *testing.T) {
+	// Arrange
+	original := []byte(`{"$schema": "http://json-schema.org/draft-04/schema#", "id": "file:///count.json", "type": "integer", "minimum": 0, "exclusiveMinimum": true}`)
+	compiler, err := parse.NewCompiler("")
+	require.NoError(t, err)
+	results := []*Result{{
+		Path:     "count.json",
+		Original: original,
+		Contents: []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "file:///count.json", "type": "integer", "minimum": 0}`), // lost the exclusiveness
+	}}
+	before, err := NewValidator(results, compiler.Loaders)
+	require.NoError(t, err)
+	after, err := Compile(compiler, results)
+	require.NoError(t, err)
+	instances := []*validate.Instance{{Path: "zero.json", Data: 0}, {Path: "one.json", Data: 1}}
+	selector := &validate.Validator{Compiler: compiler, SchemaID: "file:///count.json"}
+
+	// Act
+	mismatches, err := Verify(before, after, results, instances, selector)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "zero.json against count.json: invalid before upgrading, valid after", mismatches[0].String())
+}
+
+func TestVerify_Upgraded(t *testing.T) {
+	// Arrange
+	original := []byte(`{
+		"$schema": "http://json-schema.org/draft-04/schema#",
+		"id": "file:///order.json",
+		"type": "object",
+		"properties": {
+			"total": {"type": "number", "maximum": 100, "exclusiveMaximum": true},
+			"lines": {"type": "array", "items": [{"type": "string"}], "additionalItems": {"$ref": "#/definitions/line"}}
+		},
+		"dependencies": {"total": ["lines"], "coupon": {"required": ["total"]}},
+		"definitions": {"line": {"type": "integer", "minimum": 1}}
+	}`)
+	result, err := Upgrade("order.json", original)
+	require.NoError(t, err)
+	compiler, err := parse.NewCompiler("")
+	require.NoError(t, err)
+	before, err := NewValidator([]*Result{result}, compiler.Loaders)
+	require.NoError(t, err)
+	after, err := Compile(compiler, []*Result{result})
+	require.NoError(t, err)
+	var instances []*validate.Instance
+	for i, data := range []string{
+		`{"total": 99.5, "lines": ["a", 1, 2]}`,
+		`{"total": 100, "lines": ["a"]}`,
+		`{"total": 1}`,
+		`{"lines": ["a", 0]}`,
+		`{"coupon": "x"}`,
+		`{"coupon": "x", "total": 1, "lines": []}`,
+	} {
+		instances = append(instances, &validate.Instance{Path: "order.json", Index: i + 1, Data: decode(t, data)})
+	}
+
+	selector := &validate.Validator{Compiler: compiler, SchemaID: "file:///order.json"}
+
+	// Act
+	mismatches, err := Verify(before, after, []*Result{result}, instances, selector)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, mismatches)
+}
+
+func TestVerify_SelectsSchema(t *testing.T) {
+	// Arrange
+	results := []*Result{
+		{
+			Path:     "count.json",
+			Original: []byte(`{"$schema": "http://json-schema.org/draft-04/schema#", "id": "file:///count.json", "minimum": 0, "exclusiveMinimum": true}`),
+			Contents: []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "file:///count.json", "minimum": 0}`), // lost the exclusiveness
+		},
+		{
+			Path:     "pet.json",
+			Original: []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "file:///pet.json", "type": "object"}`),
+			Contents: []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "file:///pet.json", "type": "object"}`),
+		},
+	}
+	compiler, err := parse.NewCompiler("")
+	require.NoError(t, err)
+	before, err := NewValidator(results, compiler.Loaders)
+	require.NoError(t, err)
+	after, err := Compile(compiler, results)
+	require.NoError(t, err)
+	instances := []*validate.Instance{
+		{Path: "pets/zero.json", Data: 0}, // not validated against count.json
+		{Path: "pets/pet.json", Data: map[string]any{"$schema": "file:///pet.json"}},
+		{Path: "counts/zero.json", Data: 0},
+	}
+	selector := &validate.Validator{Compiler: compiler, Mappings: []validate.Mapping{
+		{Pattern: "pets/*.json", SchemaID: "file:///pet.json"},
+		{Pattern: "counts/*.json", SchemaID: "file:///count.json"},
+	}}
+
+	// Act
+	mismatches, err := Verify(before, after, results, instances, selector)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Equal(t, "counts/zero.json against count.json: invalid before upgrading, valid after", mismatches[0].String())
+}
+
+func decode(t *testing.T, data string) any {
+	t.Helper()
+
+	var res any
+	require.NoError(t, json.Unmarshal([]byte(data), &res))
+
+	return res
+}
@@ -0,0 +1,1018 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+)
+
+// ErrUnresolvedReference is returned when a reference of a document cannot be resolved while validating an instance
+var ErrUnresolvedReference = errors.New("could not resolve reference")
+
+// ErrValidatingInstance is returned when an instance cannot be validated, e.g. because a 'pattern' is not a valid
+// regular expression or a '$ref' recurses without end
+var ErrValidatingInstance = errors.New("could not validate instance")
+
+// maxDepth of the schemas evaluated for an instance, exceeded by a '$ref' that recurses without end
+const maxDepth = 1000
+
+// Validator validates instances against the schema documents as written, i.e. every resource with the semantics of
+// the draft declared by its '$schema' (draft-04 up to 2020-12, by default 2020-12) instead of the upgraded document.
+// The outcome before upgrading therefore does not depend on parse.Upgrade. Like the compiler, formats are not asserted
+type Validator struct {
+	// loaders of referenced documents that are not upgraded by their URI scheme (see jsonschema.Compiler.Loaders)
+	loaders map[string]func(url string) (io.ReadCloser, error)
+
+	// documents of the Results by their Path
+	documents map[string]any
+
+	// resources by their absolute URI without fragment
+	resources map[string]any
+
+	// anchors by their absolute URI with fragment, i.e. '$anchor', '$dynamicAnchor' and the plain name '$id' (or
+	// 'id') of draft-04 up to draft-07
+	anchors map[string]*parse.Object
+
+	// dynamicAnchors by their absolute URI with fragment
+	dynamicAnchors map[string]*parse.Object
+
+	// nodes of the schema objects indexed so far
+	nodes map[*parse.Object]*node
+}
+
+// node of a schema object
+type node struct {
+	// draft the schema object is written in
+	draft parse.Draft
+
+	// base URI of the resource the schema object is in
+	base *url.URL
+
+	// resource the schema object is in, i.e. the object that declares the base URI or the root of the document
+	resource *parse.Object
+
+	// recursiveAnchor is true iff the schema object has '$recursiveAnchor' true (2019-09)
+	recursiveAnchor bool
+}
+
+// NewValidator for the original documents of the results, documents referenced by them are loaded as written using
+// the loaders (by URI scheme)
+func NewValidator(results []*Result, loaders map[string]func(url string) (io.ReadCloser, error)) (*Validator, error) {
+	v := &Validator{
+		loaders:        loaders,
+		documents:      map[string]any{},
+		resources:      map[string]any{},
+		anchors:        map[string]*parse.Object{},
+		dynamicAnchors: map[string]*parse.Object{},
+		nodes:          map[*parse.Object]*node{},
+	}
+
+	for _, result := range results {
+		document, err := parse.DecodeOrdered(result.Original)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrUpgradingDocument, result.Path, err)
+		}
+
+		v.documents[result.Path] = document
+		v.add(document, &url.URL{Scheme: "file", Path: "/" + strings.TrimPrefix(filepath.ToSlash(filepath.Clean(result.Path)), "/")})
+	}
+
+	return v, nil
+}
+
+// Validate the instance against the original document of the Result
+func (v *Validator) Validate(result *Result, instance any) (bool, error) {
+	document, ok := v.documents[result.Path]
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrUnresolvedReference, result.Path)
+	}
+
+	e := &evaluation{validator: v}
+	out, err := e.evaluate(document, instance, 0)
+	if err != nil {
+		return false, err
+	}
+
+	return out.valid, nil
+}
+
+// add the document retrieved from the URI
+func (v *Validator) add(document any, uri *url.URL) {
+	v.resources[uri.String()] = document
+	root, _ := document.(*parse.Object)
+	v.index(document, uri, parse.Draft202012, root)
+}
+
+// index the schema (and its subschemas) written in the draft in the resource with the base URI. Like parse.Upgrade every
+// keyword is walked except those containing instance data, such that a JSON pointer into an unknown keyword resolves
+func (v *Validator) index(schema any, base *url.URL, draft parse.Draft, resource *parse.Object) {
+	switch schema := schema.(type) {
+	case []any:
+		for _, item := range schema {
+			v.index(item, base, draft, resource)
+		}
+	case *parse.Object:
+		if _, ok := v.nodes[schema]; ok {
+			return
+		}
+
+		n := &node{draft: draft, base: base, resource: resource}
+		if s, ok := stringOf(schema, "$schema"); ok {
+			n.draft = parse.DetectDraft(s, draft)
+		}
+
+		idKeyword := "$id"
+		if n.draft == parse.Draft04 {
+			idKeyword = "id"
+		}
+
+		if id, ok := stringOf(schema, idKeyword); ok {
+			if ref, err := url.Parse(id); err == nil && strings.HasPrefix(id, "#") && ref.Fragment != "" {
+				v.anchors[withFragment(base, ref.Fragment).String()] = schema
+			} else if err == nil {
+				n.base, n.resource = withFragment(base.ResolveReference(ref), ""), schema
+				v.resources[n.base.String()] = schema
+			}
+		}
+
+		if anchor, ok := stringOf(schema, "$anchor"); ok {
+			v.anchors[withFragment(n.base, anchor).String()] = schema
+		}
+
+		if anchor, ok := stringOf(schema, "$dynamicAnchor"); ok {
+			v.anchors[withFragment(n.base, anchor).String()] = schema
+			v.dynamicAnchors[withFragment(n.base, anchor).String()] = schema
+		}
+
+		if anchor, ok := schema.Get("$recursiveAnchor"); ok && anchor == true {
+			n.recursiveAnchor = true
+		}
+
+		v.nodes[schema] = n
+		for _, key := range schema.Keys() {
+			if parse.IsDataKeyword(key) {
+				continue
+			}
+
+			value, _ := schema.Get(key)
+			if keyed, ok := value.(*parse.Object); ok && parse.IsKeyedKeyword(key) {
+				for _, name := range keyed.Keys() {
+					subschema, _ := keyed.Get(name)
+					v.index(subschema, n.base, n.draft, n.resource)
+				}
+				continue
+			}
+
+			v.index(value, n.base, n.draft, n.resource)
+		}
+	}
+}
+
+// node of the schema object, a schema object that is not indexed (e.g. referenced by a JSON pointer into an unknown
+// keyword) is indexed in the resource of the fallback (or as a 2020-12 resource of its own if nil)
+func (v *Validator) node(schema *parse.Object, fallback *node) *node {
+	if n, ok := v.nodes[schema]; ok {
+		return n
+	}
+
+	if fallback == nil {
+		fallback = &node{draft: parse.Draft202012, base: &url.URL{}, resource: schema}
+	}
+
+	v.index(schema, fallback.base, fallback.draft, fallback.resource)
+
+	return v.nodes[schema]
+}
+
+// resolve the reference relative to the base URI to the schema it points to
+func (v *Validator) resolve(base *url.URL, ref string) (any, error) {
+	r, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrUnresolvedReference, ref, err)
+	}
+
+	target := base.ResolveReference(r)
+	document := withFragment(target, "")
+	root, ok := v.resources[document.String()]
+	if !ok {
+		if root, err = v.load(document); err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrUnresolvedReference, target, err)
+		}
+	}
+
+	switch {
+	case target.Fragment == "":
+		return root, nil
+	case strings.HasPrefix(target.Fragment, "/"):
+		schema, found := pointer(root, target.Fragment)
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnresolvedReference, target)
+		}
+
+		return schema, nil
+	default:
+		schema, found := v.anchors[target.String()]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrUnresolvedReference, target)
+		}
+
+		return schema, nil
+	}
+}
+
+// load the document that is not one of the Results
+func (v *Validator) load(document *url.URL) (any, error) {
+	loader, ok := v.loaders[document.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("no loader for scheme '%s'", document.Scheme)
+	}
+
+	rc, err := loader(document.String())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	contents, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := parse.DecodeOrdered(contents)
+	if err != nil {
+		return nil, err
+	}
+	v.add(decoded, document)
+
+	return decoded, nil
+}
+
+// evaluation of an instance keeping the dynamic scope
+type evaluation struct {
+	validator *Validator
+
+	// scope of the resources entered, the outermost first
+	scope []*parse.Object
+}
+
+// verdict of evaluating a schema with the properties and items evaluated by it (for 'unevaluatedProperties' and
+// 'unevaluatedItems'), which are only kept if the schema is valid
+type verdict struct {
+	valid      bool
+	properties map[string]bool
+	items      map[int]bool
+}
+
+// merge the evaluated properties and items of the other verdict if it is valid
+func (o *verdict) merge(other *verdict) {
+	if !other.valid {
+		return
+	}
+
+	for name := range other.properties {
+		o.properties[name] = true
+	}
+
+	for i := range other.items {
+		o.items[i] = true
+	}
+}
+
+// evaluate the instance against the schema
+func (e *evaluation) evaluate(schema any, instance any, depth int) (*verdict, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: exceeded %d nested schemas", ErrValidatingInstance, maxDepth)
+	}
+
+	out := &verdict{valid: true, properties: map[string]bool{}, items: map[int]bool{}}
+	object, ok := schema.(*parse.Object)
+	if !ok {
+		out.valid = schema != false
+
+		return out, nil
+	}
+
+	n := e.validator.node(object, e.validator.nodes[e.current()])
+	if e.current() != n.resource {
+		e.scope = append(e.scope, n.resource)
+		defer func() { e.scope = e.scope[:len(e.scope)-1] }()
+	}
+
+	if err := e.references(object, n, instance, out, depth); err != nil {
+		return nil, err
+	}
+
+	// siblings of '$ref' are ignored up to draft-07
+	if _, ok = object.Get("$ref"); ok && n.draft.Legacy() {
+		return out, nil
+	}
+
+	if err := e.applicators(object, n, instance, out, depth); err != nil {
+		return nil, err
+	}
+
+	if err := e.assertions(object, n, instance, out); err != nil {
+		return nil, err
+	}
+
+	if err := e.unevaluated(object, n, instance, out, depth); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// current resource of the dynamic scope
+func (e *evaluation) current() *parse.Object {
+	if len(e.scope) == 0 {
+		return nil
+	}
+
+	return e.scope[len(e.scope)-1]
+}
+
+// sub evaluates the instance against the subschema and merges its verdict, the verdict is invalid if the subschema is
+func (e *evaluation) sub(subschema any, instance any, out *verdict, depth int) (*verdict, error) {
+	res, err := e.evaluate(subschema, instance, depth+1)
+	if err != nil {
+		return nil, err
+	}
+
+	out.valid = out.valid && res.valid
+	out.merge(res)
+
+	return res, nil
+}
+
+// references '$ref', '$recursiveRef' (2019-09) and '$dynamicRef' (2020-12)
+func (e *evaluation) references(schema *parse.Object, n *node, instance any, out *verdict, depth int) error {
+	if ref, ok := stringOf(schema, "$ref"); ok {
+		target, err := e.validator.resolve(n.base, ref)
+		if err != nil {
+			return err
+		}
+
+		if _, err = e.sub(target, instance, out, depth); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := stringOf(schema, "$recursiveRef"); ok && n.draft == parse.Draft201909 {
+		target, err := e.validator.resolve(n.base, ref)
+		if err != nil {
+			return err
+		}
+
+		if object, isObject := target.(*parse.Object); isObject && e.validator.node(object, n).recursiveAnchor {
+			for _, resource := range e.scope {
+				if e.validator.nodes[resource].recursiveAnchor {
+					target = resource
+					break
+				}
+			}
+		}
+
+		if _, err = e.sub(target, instance, out, depth); err != nil {
+			return err
+		}
+	}
+
+	if ref, ok := stringOf(schema, "$dynamicRef"); ok && n.draft == parse.Draft202012 {
+		target, err := e.validator.resolve(n.base, ref)
+		if err != nil {
+			return err
+		}
+
+		if r, _ := url.Parse(ref); r != nil && r.Fragment != "" && !strings.HasPrefix(r.Fragment, "/") {
+			if object, isObject := target.(*parse.Object); isObject && dynamicAnchor(object) == r.Fragment {
+				for _, resource := range e.scope {
+					if anchored, found := e.validator.dynamicAnchors[withFragment(e.validator.nodes[resource].base, r.Fragment).String()]; found {
+						target = anchored
+						break
+					}
+				}
+			}
+		}
+
+		if _, err = e.sub(target, instance, out, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applicators of the schema to the instance (or its properties or items) except 'unevaluatedProperties' and
+// 'unevaluatedItems'
+func (e *evaluation) applicators(schema *parse.Object, n *node, instance any, out *verdict, depth int) error {
+	if subschemas, ok := arrayOf(schema, "allOf"); ok {
+		for _, subschema := range subschemas {
+			if _, err := e.sub(subschema, instance, out, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if subschemas, ok := arrayOf(schema, "anyOf"); ok {
+		valid := false
+		for _, subschema := range subschemas {
+			res, err := e.evaluate(subschema, instance, depth+1)
+			if err != nil {
+				return err
+			}
+			valid = valid || res.valid
+			out.merge(res)
+		}
+		out.valid = out.valid && valid
+	}
+
+	if subschemas, ok := arrayOf(schema, "oneOf"); ok {
+		var matched []*verdict
+		for _, subschema := range subschemas {
+			res, err := e.evaluate(subschema, instance, depth+1)
+			if err != nil {
+				return err
+			}
+			if res.valid {
+				matched = append(matched, res)
+			}
+		}
+
+		out.valid = out.valid && len(matched) == 1
+		if len(matched) == 1 {
+			out.merge(matched[0])
+		}
+	}
+
+	if subschema, ok := schema.Get("not"); ok {
+		res, err := e.evaluate(subschema, instance, depth+1)
+		if err != nil {
+			return err
+		}
+		out.valid = out.valid && !res.valid
+	}
+
+	if condition, ok := schema.Get("if"); ok && n.draft != parse.Draft04 && n.draft != parse.Draft06 {
+		res, err := e.evaluate(condition, instance, depth+1)
+		if err != nil {
+			return err
+		}
+		out.merge(res)
+
+		branch := "else"
+		if res.valid {
+			branch = "then"
+		}
+
+		if subschema, exists := schema.Get(branch); exists {
+			if _, err = e.sub(subschema, instance, out, depth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if object, ok := instance.(map[string]any); ok {
+		return e.properties(schema, n, object, out, depth)
+	}
+
+	if array, ok := instance.([]any); ok {
+		return e.items(schema, n, array, out, depth)
+	}
+
+	return nil
+}
+
+// properties applies 'properties', 'patternProperties', 'additionalProperties', 'propertyNames' and the dependencies
+// to an object instance
+func (e *evaluation) properties(schema *parse.Object, n *node, instance map[string]any, out *verdict, depth int) error {
+	matched := map[string]bool{}
+	if properties, ok := objectOf(schema, "properties"); ok {
+		for _, name := range properties.Keys() {
+			if value, exists := instance[name]; exists {
+				subschema, _ := properties.Get(name)
+				if _, err := e.sub(subschema, value, out, depth); err != nil {
+					return err
+				}
+				matched[name], out.properties[name] = true, true
+			}
+		}
+	}
+
+	if patterns, ok := objectOf(schema, "patternProperties"); ok {
+		for _, pattern := range patterns.Keys() {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrValidatingInstance, err)
+			}
+
+			subschema, _ := patterns.Get(pattern)
+			for name, value := range instance {
+				if !re.MatchString(name) {
+					continue
+				}
+
+				if _, err = e.sub(subschema, value, out, depth); err != nil {
+					return err
+				}
+				matched[name], out.properties[name] = true, true
+			}
+		}
+	}
+
+	if subschema, ok := schema.Get("additionalProperties"); ok {
+		for name, value := range instance {
+			if matched[name] {
+				continue
+			}
+
+			if _, err := e.sub(subschema, value, out, depth); err != nil {
+				return err
+			}
+			out.properties[name] = true
+		}
+	}
+
+	if subschema, ok := schema.Get("propertyNames"); ok && n.draft != parse.Draft04 {
+		for name := range instance {
+			res, err := e.evaluate(subschema, name, depth+1)
+			if err != nil {
+				return err
+			}
+			out.valid = out.valid && res.valid
+		}
+	}
+
+	// 'dependencies' is split into 'dependentRequired' and 'dependentSchemas' as of 2019-09
+	keywords := []string{"dependentRequired", "dependentSchemas"}
+	if n.draft.Legacy() {
+		keywords = []string{"dependencies"}
+	}
+
+	for _, keyword := range keywords {
+		dependencies, ok := objectOf(schema, keyword)
+		if !ok {
+			continue
+		}
+
+		for _, name := range dependencies.Keys() {
+			if _, exists := instance[name]; !exists {
+				continue
+			}
+
+			dependency, _ := dependencies.Get(name)
+			if required, isArray := dependency.([]any); isArray {
+				out.valid = out.valid && hasAll(instance, required)
+			} else if keyword != "dependentRequired" {
+				if _, err := e.sub(dependency, instance, out, depth); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// items applies 'prefixItems', 'items', 'additionalItems' and 'contains' to an array instance
+func (e *evaluation) items(schema *parse.Object, n *node, instance []any, out *verdict, depth int) error {
+	// the leading items evaluated by a tuple and the schema applied to the remaining items (if any)
+	var prefix []any
+	var rest any
+	var hasRest bool
+	if n.draft == parse.Draft202012 {
+		prefix, _ = arrayOf(schema, "prefixItems")
+		rest, hasRest = schema.Get("items")
+	} else if items, ok := schema.Get("items"); ok {
+		if tuple, isArray := items.([]any); isArray {
+			prefix = tuple
+			rest, hasRest = schema.Get("additionalItems")
+		} else {
+			rest, hasRest = items, true
+		}
+	}
+
+	for i, item := range instance {
+		subschema := rest
+		if i < len(prefix) {
+			subschema = prefix[i]
+		} else if !hasRest {
+			break
+		}
+
+		if _, err := e.sub(subschema, item, out, depth); err != nil {
+			return err
+		}
+		out.items[i] = true
+	}
+
+	contains, ok := schema.Get("contains")
+	if !ok || n.draft == parse.Draft04 {
+		return nil
+	}
+
+	minContains, maxContains := 1.0, math.Inf(1)
+	if n.draft == parse.Draft201909 || n.draft == parse.Draft202012 {
+		if limit, exists := numberOf(schema, "minContains"); exists {
+			minContains, _ = limit.Float64()
+		}
+		if limit, exists := numberOf(schema, "maxContains"); exists {
+			maxContains, _ = limit.Float64()
+		}
+	}
+
+	count := 0
+	for i, item := range instance {
+		res, err := e.evaluate(contains, item, depth+1)
+		if err != nil {
+			return err
+		}
+
+		if res.valid {
+			count++
+			if n.draft == parse.Draft202012 {
+				out.items[i] = true
+			}
+		}
+	}
+	out.valid = out.valid && float64(count) >= minContains && float64(count) <= maxContains
+
+	return nil
+}
+
+// unevaluated applies 'unevaluatedProperties' and 'unevaluatedItems' (2019-09 and 2020-12) to the properties and
+// items that are not evaluated by the other keywords
+func (e *evaluation) unevaluated(schema *parse.Object, n *node, instance any, out *verdict, depth int) error {
+	if n.draft != parse.Draft201909 && n.draft != parse.Draft202012 {
+		return nil
+	}
+
+	if subschema, ok := schema.Get("unevaluatedProperties"); ok {
+		if object, isObject := instance.(map[string]any); isObject {
+			for name, value := range object {
+				if out.properties[name] {
+					continue
+				}
+
+				if _, err := e.sub(subschema, value, out, depth); err != nil {
+					return err
+				}
+				out.properties[name] = true
+			}
+		}
+	}
+
+	if subschema, ok := schema.Get("unevaluatedItems"); ok {
+		if array, isArray := instance.([]any); isArray {
+			for i, item := range array {
+				if out.items[i] {
+					continue
+				}
+
+				if _, err := e.sub(subschema, item, out, depth); err != nil {
+					return err
+				}
+				out.items[i] = true
+			}
+		}
+	}
+
+	return nil
+}
+
+// assertions of the schema on the instance, i.e. the keywords that do not apply a subschema
+func (e *evaluation) assertions(schema *parse.Object, n *node, instance any, out *verdict) error {
+	valid := func(ok bool) {
+		out.valid = out.valid && ok
+	}
+
+	if types, ok := schema.Get("type"); ok {
+		names, isArray := types.([]any)
+		if !isArray {
+			names = []any{types}
+		}
+
+		matches := false
+		for _, name := range names {
+			s, _ := name.(string)
+			matches = matches || isType(instance, s)
+		}
+		valid(matches)
+	}
+
+	if values, ok := arrayOf(schema, "enum"); ok {
+		found := false
+		for _, value := range values {
+			found = found || equal(value, instance)
+		}
+		valid(found)
+	}
+
+	if value, ok := schema.Get("const"); ok && n.draft != parse.Draft04 {
+		valid(equal(value, instance))
+	}
+
+	if number, ok := rational(instance); ok {
+		e.numeric(schema, n, number, valid)
+	}
+
+	if s, ok := instance.(string); ok {
+		length := int64(utf8.RuneCountInString(s))
+		if limit, exists := integerOf(schema, "maxLength"); exists {
+			valid(length <= limit)
+		}
+		if limit, exists := integerOf(schema, "minLength"); exists {
+			valid(length >= limit)
+		}
+		if pattern, exists := stringOf(schema, "pattern"); exists {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrValidatingInstance, err)
+			}
+			valid(re.MatchString(s))
+		}
+	}
+
+	if array, ok := instance.([]any); ok {
+		if limit, exists := integerOf(schema, "maxItems"); exists {
+			valid(int64(len(array)) <= limit)
+		}
+		if limit, exists := integerOf(schema, "minItems"); exists {
+			valid(int64(len(array)) >= limit)
+		}
+		if unique, exists := schema.Get("uniqueItems"); exists && unique == true {
+			for i := range array {
+				for j := i + 1; j < len(array); j++ {
+					valid(!equal(array[i], array[j]))
+				}
+			}
+		}
+	}
+
+	if object, ok := instance.(map[string]any); ok {
+		if limit, exists := integerOf(schema, "maxProperties"); exists {
+			valid(int64(len(object)) <= limit)
+		}
+		if limit, exists := integerOf(schema, "minProperties"); exists {
+			valid(int64(len(object)) >= limit)
+		}
+		if required, exists := arrayOf(schema, "required"); exists {
+			valid(hasAll(object, required))
+		}
+	}
+
+	return nil
+}
+
+// numeric assertions on a number instance, draft-04 uses booleans for 'exclusiveMinimum' and 'exclusiveMaximum'
+func (e *evaluation) numeric(schema *parse.Object, n *node, number *big.Rat, valid func(bool)) {
+	if divisor, ok := numberOf(schema, "multipleOf"); ok && divisor.Sign() != 0 {
+		valid(new(big.Rat).Quo(number, divisor).IsInt())
+	}
+
+	exclusiveMaximum, _ := schema.Get("exclusiveMaximum")
+	exclusiveMinimum, _ := schema.Get("exclusiveMinimum")
+	if maximum, ok := numberOf(schema, "maximum"); ok {
+		if n.draft == parse.Draft04 && exclusiveMaximum == true {
+			valid(number.Cmp(maximum) < 0)
+		} else {
+			valid(number.Cmp(maximum) <= 0)
+		}
+	}
+	if minimum, ok := numberOf(schema, "minimum"); ok {
+		if n.draft == parse.Draft04 && exclusiveMinimum == true {
+			valid(number.Cmp(minimum) > 0)
+		} else {
+			valid(number.Cmp(minimum) >= 0)
+		}
+	}
+
+	if n.draft == parse.Draft04 {
+		return
+	}
+
+	if maximum, ok := numberOf(schema, "exclusiveMaximum"); ok {
+		valid(number.Cmp(maximum) < 0)
+	}
+	if minimum, ok := numberOf(schema, "exclusiveMinimum"); ok {
+		valid(number.Cmp(minimum) > 0)
+	}
+}
+
+// isType returns true iff the instance is of the JSON type, a number without fraction is an integer
+func isType(instance any, name string) bool {
+	switch name {
+	case "null":
+		return instance == nil
+	case "boolean":
+		_, ok := instance.(bool)
+		return ok
+	case "string":
+		_, ok := instance.(string)
+		return ok
+	case "array":
+		_, ok := instance.([]any)
+		return ok
+	case "object":
+		_, ok := instance.(map[string]any)
+		return ok
+	case "number":
+		_, ok := rational(instance)
+		return ok
+	case "integer":
+		number, ok := rational(instance)
+		return ok && number.IsInt()
+	default:
+		return false
+	}
+}
+
+// equal returns true iff the schema value (see parse.DecodeOrdered) and the instance are the same JSON value
+func equal(value any, instance any) bool {
+	if a, ok := rational(value); ok {
+		b, isNumber := rational(instance)
+		return isNumber && a.Cmp(b) == 0
+	}
+
+	switch value := value.(type) {
+	case *parse.Object:
+		object, ok := instance.(map[string]any)
+		if !ok || len(object) != len(value.Keys()) {
+			return false
+		}
+
+		for _, key := range value.Keys() {
+			v, _ := value.Get(key)
+			if other, exists := object[key]; !exists || !equal(v, other) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]any:
+		object, ok := instance.(map[string]any)
+		if !ok || len(object) != len(value) {
+			return false
+		}
+
+		for key, v := range value {
+			if other, exists := object[key]; !exists || !equal(v, other) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		array, ok := instance.([]any)
+		if !ok || len(array) != len(value) {
+			return false
+		}
+
+		for i := range value {
+			if !equal(value[i], array[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return value == instance
+	}
+}
+
+// rational number of a JSON number (decoded as json.Number or a Go number), false for any other value
+func rational(value any) (*big.Rat, bool) {
+	switch value := value.(type) {
+	case json.Number:
+		return new(big.Rat).SetString(value.String())
+	case float64:
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, false
+		}
+		// the shortest decimal representation, e.g. 0.3 instead of the nearest binary fraction
+		return new(big.Rat).SetString(strconv.FormatFloat(value, 'g', -1, 64))
+	case float32:
+		return rational(float64(value))
+	case int:
+		return new(big.Rat).SetInt64(int64(value)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(value)), true
+	case int64:
+		return new(big.Rat).SetInt64(value), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(value)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(value)), true
+	case uint64:
+		return new(big.Rat).SetUint64(value), true
+	default:
+		return nil, false
+	}
+}
+
+// hasAll returns true iff the object has every name
+func hasAll(object map[string]any, names []any) bool {
+	for _, name := range names {
+		s, _ := name.(string)
+		if _, ok := object[s]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// pointer resolves the JSON pointer (a URI fragment, e.g. '/definitions/pet') in the document
+func pointer(document any, fragment string) (any, bool) {
+	value := document
+	for _, token := range strings.Split(fragment, "/")[1:] {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch current := value.(type) {
+		case *parse.Object:
+			next, ok := current.Get(token)
+			if !ok {
+				return nil, false
+			}
+			value = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(current) {
+				return nil, false
+			}
+			value = current[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// dynamicAnchor of the schema object or "" if it has none
+func dynamicAnchor(schema *parse.Object) string {
+	anchor, _ := stringOf(schema, "$dynamicAnchor")
+
+	return anchor
+}
+
+// withFragment returns a copy of the URI with the fragment
+func withFragment(uri *url.URL, fragment string) *url.URL {
+	res := *uri
+	res.Fragment, res.RawFragment = fragment, ""
+
+	return &res
+}
+
+// stringOf the keyword of the schema object
+func stringOf(schema *parse.Object, keyword string) (string, bool) {
+	value, _ := schema.Get(keyword)
+	s, ok := value.(string)
+
+	return s, ok
+}
+
+// arrayOf the keyword of the schema object
+func arrayOf(schema *parse.Object, keyword string) ([]any, bool) {
+	value, _ := schema.Get(keyword)
+	array, ok := value.([]any)
+
+	return array, ok
+}
+
+// objectOf the keyword of the schema object
+func objectOf(schema *parse.Object, keyword string) (*parse.Object, bool) {
+	value, _ := schema.Get(keyword)
+	object, ok := value.(*parse.Object)
+
+	return object, ok
+}
+
+// numberOf the keyword of the schema object
+func numberOf(schema *parse.Object, keyword string) (*big.Rat, bool) {
+	value, ok := schema.Get(keyword)
+	if !ok {
+		return nil, false
+	}
+
+	return rational(value)
+}
+
+// integerOf the keyword of the schema object
+func integerOf(schema *parse.Object, keyword string) (int64, bool) {
+	number, ok := numberOf(schema, keyword)
+	if !ok || !number.IsInt() {
+		return 0, false
+	}
+
+	return number.Num().Int64(), true
+}
@@ -0,0 +1,271 @@
+package upgrade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Validate(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		instance string
+		valid    bool
+	}{
+		"draft-04 exclusiveMinimum": {
+			schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "minimum": 1, "exclusiveMinimum": true}`,
+			instance: `1`,
+			valid:    false,
+		},
+		"draft-06 exclusiveMinimum": {
+			schema:   `{"$schema": "http://json-schema.org/draft-06/schema#", "exclusiveMinimum": 1}`,
+			instance: `1.5`,
+			valid:    true,
+		},
+		"draft-07 ignores siblings of $ref": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "$ref": "#/definitions/id", "type": "integer", "definitions": {"id": {"type": "string"}}}`,
+			instance: `"abc"`,
+			valid:    true,
+		},
+		"2020-12 applies siblings of $ref": {
+			schema:   `{"$ref": "#/$defs/id", "type": "integer", "$defs": {"id": {"type": "string"}}}`,
+			instance: `"abc"`,
+			valid:    false,
+		},
+		"draft-07 nested definitions": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "$ref": "#/definitions/outer/definitions/inner", "definitions": {"outer": {"definitions": {"inner": {"type": "string"}}}}}`,
+			instance: `1`,
+			valid:    false,
+		},
+		"draft-07 plain name id": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "items": {"$ref": "#item"}, "definitions": {"item": {"$id": "#item", "type": "string"}}}`,
+			instance: `["a", 1]`,
+			valid:    false,
+		},
+		"draft-07 dependencies": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "dependencies": {"a": ["b"]}}`,
+			instance: `{"a": 1}`,
+			valid:    false,
+		},
+		"draft-07 array items": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "items": [{"type": "string"}], "additionalItems": false}`,
+			instance: `["a", "b"]`,
+			valid:    false,
+		},
+		"2019-09 recursiveRef": {
+			schema: `{
+				"$schema": "https://json-schema.org/draft/2019-09/schema",
+				"$id": "file:///tree.json",
+				"$recursiveAnchor": true,
+				"type": "object",
+				"properties": {"children": {"type": "array", "items": {"$recursiveRef": "#"}}}
+			}`,
+			instance: `{"children": [{"children": [1]}]}`,
+			valid:    false,
+		},
+		"2020-12 unevaluatedProperties": {
+			schema:   `{"allOf": [{"properties": {"a": true}}], "unevaluatedProperties": false}`,
+			instance: `{"a": 1, "b": 2}`,
+			valid:    false,
+		},
+		"draft-04 type integer": {
+			schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "type": "integer"}`,
+			instance: `1.5`,
+			valid:    false,
+		},
+		"minLength counts code points": {
+			schema:   `{"minLength": 2}`,
+			instance: `"é"`,
+			valid:    false,
+		},
+		"pattern": {
+			schema:   `{"pattern": "^a"}`,
+			instance: `"ba"`,
+			valid:    false,
+		},
+		"multipleOf decimal": {
+			schema:   `{"multipleOf": 0.1}`,
+			instance: `0.3`,
+			valid:    true,
+		},
+		"uniqueItems compares numbers by value": {
+			schema:   `{"uniqueItems": true}`,
+			instance: `[1, 1.0]`,
+			valid:    false,
+		},
+		"draft-04 ignores contains": {
+			schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "contains": {"type": "string"}}`,
+			instance: `[1]`,
+			valid:    true,
+		},
+		"draft-06 contains": {
+			schema:   `{"$schema": "http://json-schema.org/draft-06/schema#", "contains": {"type": "string"}}`,
+			instance: `[1]`,
+			valid:    false,
+		},
+		"draft-07 ignores minContains": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "contains": {"type": "string"}, "minContains": 2}`,
+			instance: `["a", 1]`,
+			valid:    true,
+		},
+		"2019-09 minContains": {
+			schema:   `{"$schema": "https://json-schema.org/draft/2019-09/schema", "contains": {"type": "string"}, "minContains": 2}`,
+			instance: `["a", 1]`,
+			valid:    false,
+		},
+		"draft-06 ignores if": {
+			schema:   `{"$schema": "http://json-schema.org/draft-06/schema#", "if": {"type": "integer"}, "then": {"minimum": 5}}`,
+			instance: `3`,
+			valid:    true,
+		},
+		"draft-07 if then": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "if": {"type": "integer"}, "then": {"minimum": 5}}`,
+			instance: `3`,
+			valid:    false,
+		},
+		"oneOf matching twice": {
+			schema:   `{"oneOf": [{"type": "integer"}, {"minimum": 0}]}`,
+			instance: `1`,
+			valid:    false,
+		},
+		"not": {
+			schema:   `{"not": {"type": "string"}}`,
+			instance: `"a"`,
+			valid:    false,
+		},
+		"draft-04 propertyNames is ignored": {
+			schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "propertyNames": {"maxLength": 1}}`,
+			instance: `{"ab": 1}`,
+			valid:    true,
+		},
+		"draft-06 propertyNames": {
+			schema:   `{"$schema": "http://json-schema.org/draft-06/schema#", "propertyNames": {"maxLength": 1}}`,
+			instance: `{"ab": 1}`,
+			valid:    false,
+		},
+		"draft-07 ignores dependentRequired": {
+			schema:   `{"$schema": "http://json-schema.org/draft-07/schema#", "dependentRequired": {"a": ["b"]}}`,
+			instance: `{"a": 1}`,
+			valid:    true,
+		},
+		"2019-09 dependentRequired": {
+			schema:   `{"$schema": "https://json-schema.org/draft/2019-09/schema", "dependentRequired": {"a": ["b"]}}`,
+			instance: `{"a": 1}`,
+			valid:    false,
+		},
+		"draft-04 plain name id": {
+			schema:   `{"$schema": "http://json-schema.org/draft-04/schema#", "items": {"$ref": "#item"}, "definitions": {"item": {"id": "#item", "type": "string"}}}`,
+			instance: `["a", 1]`,
+			valid:    false,
+		},
+		"$ref into an unknown keyword": {
+			schema:   `{"$ref": "#/x-shared/id", "x-shared": {"id": {"type": "string"}}}`,
+			instance: `1`,
+			valid:    false,
+		},
+		"format is not asserted": {
+			schema:   `{"format": "email"}`,
+			instance: `"pet"`,
+			valid:    true,
+		},
+		"enum of objects": {
+			schema:   `{"enum": [{"a": [1, 2.0]}]}`,
+			instance: `{"a": [1.0, 2]}`,
+			valid:    true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			result := &Result{Path: "schema.json", Original: []byte(tt.schema)}
+			validator, err := NewValidator([]*Result{result}, nil)
+			require.NoError(t, err)
+
+			// Act
+			valid, err := validator.Validate(result, decode(t, tt.instance))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.valid, valid)
+		})
+	}
+}
+
+func TestValidator_UnresolvedReference(t *testing.T) {
+	// Arrange
+	result := &Result{Path: "schema.json", Original: []byte(`{"$ref": "https://example.com/missing.json"}`)}
+	validator, err := NewValidator([]*Result{result}, nil)
+	require.NoError(t, err)
+
+	// Act
+	_, err = validator.Validate(result, "abc")
+
+	// Assert
+	require.ErrorIs(t, err, ErrUnresolvedReference)
+}
+
+func TestValidator_DynamicRef(t *testing.T) {
+	// Arrange
+	tree := &Result{Path: "tree.json", Original: []byte(`{
+		"$id": "file:///tree.json",
+		"$dynamicAnchor": "node",
+		"type": "object",
+		"properties": {"children": {"type": "array", "items": {"$dynamicRef": "#node"}}}
+	}`)}
+	strict := &Result{Path: "strict.json", Original: []byte(`{
+		"$id": "file:///strict.json",
+		"$dynamicAnchor": "node",
+		"$ref": "tree.json",
+		"unevaluatedProperties": false
+	}`)}
+	validator, err := NewValidator([]*Result{tree, strict}, nil)
+	require.NoError(t, err)
+	instance := decode(t, `{"children": [{"daat": 1}]}`)
+
+	// Act
+	treeValid, treeErr := validator.Validate(tree, instance)
+	strictValid, strictErr := validator.Validate(strict, instance)
+
+	// Assert
+	require.NoError(t, treeErr)
+	require.NoError(t, strictErr)
+	assert.True(t, treeValid)
+	assert.False(t, strictValid, "the children are evaluated against the strict tree")
+}
+
+func TestValidator_InvalidSchema(t *testing.T) {
+	tests := map[string]struct {
+		schema   string
+		expected error
+	}{
+		"recursion without end": {
+			schema:   `{"$ref": "#"}`,
+			expected: ErrValidatingInstance,
+		},
+		"invalid pattern": {
+			schema:   `{"pattern": "("}`,
+			expected: ErrValidatingInstance,
+		},
+		"unresolved anchor": {
+			schema:   `{"$ref": "#missing"}`,
+			expected: ErrUnresolvedReference,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			result := &Result{Path: "schema.json", Original: []byte(tt.schema)}
+			validator, err := NewValidator([]*Result{result}, nil)
+			require.NoError(t, err)
+
+			// Act
+			_, err = validator.Validate(result, "abc")
+
+			// Assert
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
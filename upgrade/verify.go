@@ -0,0 +1,71 @@
+package upgrade
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Mismatch of the validation outcome of an Instance against a schema before and after upgrading it
+type Mismatch struct {
+	// Instance validated
+	Instance string
+
+	// Schema the Instance is validated against
+	Schema string
+
+	// Before is true iff the Instance is valid against the original schema
+	Before bool
+
+	// After is true iff the Instance is valid against the upgraded schema
+	After bool
+}
+
+// String of the Mismatch, e.g. "data/pet.json against schemas/pet.json: valid before upgrading, invalid after"
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s against %s: %s before upgrading, %s after", m.Instance, m.Schema, outcome(m.Before), outcome(m.After))
+}
+
+// Verify that every instance has the same validation outcome against its schema before upgrading it (validated as
+// written, see Validator) and after upgrading it (compiled as 2020-12, see Compile), where after and results are in the
+// same order. The schema of an instance is selected from the upgraded schemas as the validate command does (see
+// validate.Validator.Schema), an instance whose schema is not one of the results is skipped
+func Verify(before *Validator, after []*jsonschema.Schema, results []*Result, instances []*validate.Instance, selector *validate.Validator) ([]Mismatch, error) {
+	var res []Mismatch
+	for _, instance := range instances {
+		schema, err := selector.Schema(instance)
+		if err != nil {
+			return nil, err
+		}
+
+		i := slices.IndexFunc(after, func(upgraded *jsonschema.Schema) bool {
+			return upgraded.GetSchemaURI() == schema.GetSchemaURI()
+		})
+		if i < 0 {
+			continue
+		}
+
+		valid, err := before.Validate(results[i], instance.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s against %s: %w", instance.Name(), results[i].Path, err)
+		}
+
+		upgraded := after[i].Validate(instance.Data).IsValid()
+		if valid != upgraded {
+			res = append(res, Mismatch{Instance: instance.Name(), Schema: results[i].Path, Before: valid, After: upgraded})
+		}
+	}
+
+	return res, nil
+}
+
+// outcome of a validation
+func outcome(valid bool) string {
+	if valid {
+		return "valid"
+	}
+
+	return "invalid"
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/upgrade"
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgrade_InPlace(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "pet.json")
+	require.NoError(t, os.WriteFile(schema, []byte(`{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "id": "file:///pet.json",
+  "type": "object",
+  "properties": {
+    "age": { "type": "integer", "minimum": 0, "exclusiveMinimum": true }
+  }
+}
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "young.json"), []byte(`{"age": 1}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unborn.yaml"), []byte("age: 0\n"), 0o644))
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	t.Cleanup(func() { resetFlags(upgradeCmd) })
+	rootCmd.SetArgs([]string{upgradeCmd.Use, "--globs", schema, "--data", filepath.Join(dir, "*.json"), "--data", filepath.Join(dir, "*.yaml"), "--schema-id", "file:///pet.json"})
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, outputBuffer.String(), schema+": 3 change(s)\n")
+	assert.Contains(t, outputBuffer.String(), "  #: renamed 'id' to '$id'\n")
+	assert.Contains(t, outputBuffer.String(), "  #/properties/age: replaced 'minimum' and boolean 'exclusiveMinimum' with numeric 'exclusiveMinimum'\n")
+	upgraded, err := os.ReadFile(schema)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "$id": "file:///pet.json",
+  "type": "object",
+  "properties": {
+    "age": {"type": "integer", "exclusiveMinimum": 0}
+  }
+}
+`, string(upgraded))
+}
+
+func TestUpgrade_OutputDirectory(t *testing.T) {
+	// Arrange
+	dir, output := t.TempDir(), t.TempDir()
+	contents := []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {}}`)
+	schema := filepath.Join(dir, "pet.json")
+	require.NoError(t, os.WriteFile(schema, contents, 0o644))
+	rootCmd.SetOut(new(bytes.Buffer))
+	t.Cleanup(func() { resetFlags(upgradeCmd) })
+	rootCmd.SetArgs([]string{upgradeCmd.Use, "--globs", schema, "--output", output})
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	original, _ := os.ReadFile(schema)
+	assert.Equal(t, contents, original)
+	upgraded, readErr := os.ReadFile(filepath.Join(output, "pet.json"))
+	require.NoError(t, readErr)
+	assert.Equal(t, `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$defs": {}}`, string(upgraded))
+}
+
+func TestUpgrade_OutputDirectoryNoOverwrite(t *testing.T) {
+	tests := map[string]struct {
+		overwrite bool
+		expected  string
+	}{
+		"without --overwrite": {
+			expected: `{}`,
+		},
+		"with --overwrite": {
+			overwrite: true,
+			expected:  `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$defs": {}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			dir, output := t.TempDir(), t.TempDir()
+			schema := filepath.Join(dir, "pet.json")
+			require.NoError(t, os.WriteFile(schema, []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "definitions": {}}`), 0o644))
+			require.NoError(t, os.WriteFile(filepath.Join(output, "pet.json"), []byte(`{}`), 0o644))
+			rootCmd.SetOut(new(bytes.Buffer))
+			resetFlags(upgradeCmd)
+			t.Cleanup(func() { resetFlags(upgradeCmd) })
+			args := []string{upgradeCmd.Use, "--globs", schema, "--output", output}
+			if tt.overwrite {
+				args = append(args, "--overwrite")
+			}
+			rootCmd.SetArgs(args)
+
+			// Act
+			err := rootCmd.Execute()
+
+			// Assert
+			if tt.overwrite {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrNoOverwrite)
+			}
+			written, readErr := os.ReadFile(filepath.Join(output, "pet.json"))
+			require.NoError(t, readErr)
+			assert.Equal(t, tt.expected, string(written))
+		})
+	}
+}
+
+func TestUpgrade_InstanceWithoutSchema(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	schema := filepath.Join(dir, "pet.json")
+	require.NoError(t, os.WriteFile(schema, []byte(`{"$schema": "http://json-schema.org/draft-07/schema#", "$id": "file:///pet.json"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.yaml"), []byte("age: 1\n"), 0o644))
+	rootCmd.SetOut(new(bytes.Buffer))
+	resetFlags(upgradeCmd)
+	t.Cleanup(func() { resetFlags(upgradeCmd) })
+	rootCmd.SetArgs([]string{upgradeCmd.Use, "--globs", schema, "--data", filepath.Join(dir, "*.yaml")})
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, validate.ErrNoSchema)
+	original, readErr := os.ReadFile(schema)
+	require.NoError(t, readErr)
+	assert.Contains(t, string(original), "draft-07", "nothing is written")
+}
+
+func TestVerifyUpgrade_ValidationChanged(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unborn.json"), []byte(`{"$schema": "file:///pet.json", "age": 0}`), 0o644))
+	results := []*upgrade.Result{{
+		Path:     filepath.Join(dir, "pet.json"),
+		Original: []byte(`{"$schema": "http://json-schema.org/draft-04/schema#", "id": "file:///pet.json", "properties": {"age": {"minimum": 0, "exclusiveMinimum": true}}}`),
+		Contents: []byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "$id": "file:///pet.json", "properties": {"age": {"minimum": 0}}}`), // wrong: no longer exclusive
+	}}
+	outputBuffer := new(bytes.Buffer)
+	upgradeCmd.SetOut(outputBuffer)
+	t.Cleanup(func() { upgradeCmd.SetOut(nil) })
+
+	// Act
+	err := verifyUpgrade(upgradeCmd, parse.NewParser().SetBaseURI(dir), results, []string{filepath.Join(dir, "*.json")})
+
+	// Assert
+	require.ErrorIs(t, err, ErrValidationChanged)
+	assert.Equal(t, filepath.Join(dir, "unborn.json")+" against "+results[0].Path+": invalid before upgrading, valid after\n", outputBuffer.String())
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/Emptyless/jsonschema-transform/validate"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// validateCmd registered to the rootCmd
+var validateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "validate instance documents against the json schemas",
+	Long:         "validate JSON, YAML and NDJSON instance documents against the json schemas. The schema for an instance is selected using --schema-id, the '$schema' property of the instance or --schema-map (in that order)",
+	Example:      fmt.Sprintf("%s validate --globs 'schemas/*.json' --data 'data/*.yaml' --schema-map 'pet*.yaml=file:///schemas/pet.json' --format junit", rootCmd.Use),
+	SilenceUsage: true,
+	RunE:         handleValidate,
+}
+
+// init the validateCmd command
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	globsFlag.Apply(validateCmd.Flags())
+	excludeFlag.Apply(validateCmd.Flags())
+	jobsFlag.Apply(validateCmd.Flags())
+	baseURIFlag.Apply(validateCmd.Flags())
+	dataFlag.Apply(validateCmd.Flags())
+	schemaIDFlag.Apply(validateCmd.Flags())
+	schemaMapFlag.Apply(validateCmd.Flags())
+	reportFormatFlag.Apply(validateCmd.Flags())
+}
+
+// handleValidate for the validateCmd command
+func handleValidate(cmd *cobra.Command, _ []string) error {
+	dataGlobs := cmd.Flag(dataFlag.Name).Value.(pflag.SliceValue).GetSlice()
+	if len(dataGlobs) == 0 {
+		return ErrNoData
+	}
+
+	format, err := validate.ParseFormat(cmd.Flag(reportFormatFlag.Name).Value.String())
+	if err != nil {
+		return err
+	}
+
+	mappings, err := schemaMappings(cmd)
+	if err != nil {
+		return err
+	}
+
+	parser, err := newParser(cmd)
+	if err != nil {
+		return err
+	}
+
+	// load all schemas into the compiler such that they can be selected by $id
+	if _, err = parser.Schemas(); err != nil {
+		return err
+	}
+
+	validator := &validate.Validator{
+		Compiler: parser.Compiler,
+		SchemaID: cmd.Flag(schemaIDFlag.Name).Value.String(),
+		Mappings: mappings,
+	}
+
+	instances, err := readInstances(dataGlobs)
+	if err != nil {
+		return err
+	}
+
+	var results []*validate.Result
+	for _, instance := range instances {
+		result, validateErr := validator.Validate(instance)
+		if validateErr != nil {
+			return validateErr
+		}
+		results = append(results, result)
+	}
+
+	if err = format.Write(cmd.OutOrStdout(), results); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !result.Valid {
+			return ErrInvalidInstances
+		}
+	}
+
+	return nil
+}
+
+// schemaMappings of the schemaMapFlag
+func schemaMappings(cmd *cobra.Command) ([]validate.Mapping, error) {
+	var res []validate.Mapping
+	for _, input := range cmd.Flag(schemaMapFlag.Name).Value.(pflag.SliceValue).GetSlice() {
+		mapping, err := validate.ParseMapping(input)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, mapping)
+	}
+
+	return res, nil
+}
+
+// readInstances from the files matched by the data globs, files of an unknown format are skipped
+func readInstances(dataGlobs []string) ([]*validate.Instance, error) {
+	var res []*validate.Instance
+	for _, glob := range dataGlobs {
+		matches, globErr := filepath.Glob(glob)
+		if globErr != nil {
+			return nil, globErr
+		}
+
+		if len(matches) == 0 {
+			logrus.Info("no matches for data glob pattern: ", glob)
+		}
+
+		for _, match := range matches {
+			logrus.Info("reading instances from file: ", match)
+			instances, readErr := validate.ReadInstances(match)
+			if errors.Is(readErr, validate.ErrUnknownInstanceFormat) {
+				logrus.Debug(readErr.Error())
+				continue
+			} else if readErr != nil {
+				return nil, readErr
+			}
+
+			res = append(res, instances...)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,157 @@
+package validate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// ErrUnknownInstanceFormat is returned when an instance file has an extension that cannot be decoded
+var ErrUnknownInstanceFormat = errors.New("unknown instance format")
+
+// ErrDecodingInstance is returned when an instance file could not be decoded
+var ErrDecodingInstance = errors.New("could not decode instance")
+
+// ErrTrailingData is returned when a JSON document is followed by more than whitespace
+var ErrTrailingData = errors.New("unexpected data after the JSON document")
+
+// Instance document that is validated against a jsonschema.Schema
+type Instance struct {
+	// Path of the file the Instance is read from
+	Path string
+
+	// Index of the Instance in a multi-document file (the line in a NDJSON file or the
+	// document in a YAML stream) starting at 1, or 0 for single document files
+	Index int
+
+	// Data of the Instance
+	Data any
+}
+
+// Name of the Instance used when reporting, e.g. 'data/pet.json' or 'data/pets.ndjson:3'
+func (i *Instance) Name() string {
+	if i.Index == 0 {
+		return i.Path
+	}
+
+	return fmt.Sprintf("%s:%d", i.Path, i.Index)
+}
+
+// ReadInstances from a file, the format is derived from the extension:
+//   - .json: a single JSON document
+//   - .yaml or .yml: one or more YAML documents separated by '---'
+//   - .ndjson or .jsonl: a JSON document per (non-empty) line
+func ReadInstances(path string) ([]*Instance, error) {
+	contents, readFileErr := os.ReadFile(path)
+	if readFileErr != nil {
+		return nil, readFileErr
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err := decodeJSON(contents)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrDecodingInstance, path, err)
+		}
+
+		return []*Instance{{Path: path, Data: data}}, nil
+	case ".yaml", ".yml":
+		return readYAML(path, contents)
+	case ".ndjson", ".jsonl":
+		return readNDJSON(path, contents)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownInstanceFormat, path)
+	}
+}
+
+// readYAML stream into an Instance per document. A stream with a single document is treated as a single document file
+func readYAML(path string, contents []byte) ([]*Instance, error) {
+	var res []*Instance
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	for {
+		var data any
+		if err := decoder.Decode(&data); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w %s: %w", ErrDecodingInstance, path, err)
+		}
+
+		res = append(res, &Instance{Path: path, Index: len(res) + 1, Data: data})
+	}
+
+	if len(res) == 1 {
+		res[0].Index = 0
+	}
+
+	return res, nil
+}
+
+// readNDJSON into an Instance per non-empty line
+func readNDJSON(path string, contents []byte) ([]*Instance, error) {
+	var res []*Instance
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(nil, len(contents)+1)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		data, err := decodeJSON(scanner.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("%w %s:%d: %w", ErrDecodingInstance, path, line, err)
+		}
+
+		res = append(res, &Instance{Path: path, Index: line, Data: data})
+	}
+
+	return res, scanner.Err()
+}
+
+// decodeJSON with numbers decoded as int64 if they are integers that fit (without loss of precision) and as float64
+// otherwise, as the numeric keywords of the compiler do not support json.Number. The contents must contain exactly one
+// JSON document
+func decodeJSON(contents []byte) (any, error) {
+	var data any
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w at offset %d", ErrTrailingData, decoder.InputOffset())
+	}
+
+	return numbers(data), nil
+}
+
+// numbers replaces the json.Number's of the decoded data
+func numbers(data any) any {
+	switch data := data.(type) {
+	case json.Number:
+		if i, err := data.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := data.Float64()
+		return f
+	case map[string]any:
+		for key, value := range data {
+			data[key] = numbers(value)
+		}
+	case []any:
+		for i, value := range data {
+			data[i] = numbers(value)
+		}
+	}
+
+	return data
+}
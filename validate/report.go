@@ -0,0 +1,194 @@
+package validate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrUnknownFormat is returned when the supplied report format is not recognized
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Format of the validation report
+type Format string
+
+// Text format meant for humans
+const Text Format = "text"
+
+// JSON format meant for further processing
+const JSON Format = "json"
+
+// JUnit XML format meant for CI systems
+const JUnit Format = "junit"
+
+// ParseFormat from its name
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case Text, JSON, JUnit:
+		return Format(name), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+}
+
+// Write the Result's in the Format to the io.Writer
+func (f Format) Write(w io.Writer, results []*Result) error {
+	switch f {
+	case Text:
+		return writeText(w, results)
+	case JSON:
+		return writeJSON(w, results)
+	case JUnit:
+		return writeJUnit(w, results)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// writeText writes a line per Result followed by an indented line per Error
+func writeText(w io.Writer, results []*Result) error {
+	var builder strings.Builder
+	failures := 0
+	for _, result := range results {
+		status := "PASS"
+		if !result.Valid {
+			status = "FAIL"
+			failures++
+		}
+
+		schemaID := result.SchemaID
+		if schemaID == "" {
+			schemaID = "no schema"
+		}
+
+		builder.WriteString(fmt.Sprintf("%s %s (%s)\n", status, result.Instance.Name(), schemaID))
+		for _, e := range result.Errors {
+			builder.WriteString(fmt.Sprintf("    #%s: %s (%s)\n", e.InstanceLocation, e.Message, e.KeywordLocation))
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("%d instances, %d failed\n", len(results), failures))
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// jsonResult is the JSON representation of a Result
+type jsonResult struct {
+	Instance string       `json:"instance"`
+	Path     string       `json:"path"`
+	Index    int          `json:"index,omitempty"`
+	Schema   string       `json:"schema"`
+	Valid    bool         `json:"valid"`
+	Errors   []*jsonError `json:"errors,omitempty"`
+}
+
+// jsonError is the JSON representation of an Error
+type jsonError struct {
+	InstanceLocation string `json:"instanceLocation"`
+	KeywordLocation  string `json:"keywordLocation"`
+	Keyword          string `json:"keyword"`
+	Message          string `json:"message"`
+}
+
+// writeJSON writes the Result's as an indented JSON array
+func writeJSON(w io.Writer, results []*Result) error {
+	res := []*jsonResult{}
+	for _, result := range results {
+		r := &jsonResult{
+			Instance: result.Instance.Name(),
+			Path:     result.Instance.Path,
+			Index:    result.Instance.Index,
+			Schema:   result.SchemaID,
+			Valid:    result.Valid,
+		}
+
+		for _, e := range result.Errors {
+			r.Errors = append(r.Errors, &jsonError{
+				InstanceLocation: e.InstanceLocation,
+				KeywordLocation:  e.KeywordLocation,
+				Keyword:          e.Keyword,
+				Message:          e.Message,
+			})
+		}
+
+		res = append(res, r)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(res)
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite groups the junitTestCase's
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase represents a single Result
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure lists the Error's of a Result
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes the Result's as a single JUnit test suite where each Result is a test case
+func writeJUnit(w io.Writer, results []*Result) error {
+	suite := junitTestSuite{Name: "jsonschema-transform validate", Tests: len(results)}
+	for _, result := range results {
+		testCase := junitTestCase{Name: result.Instance.Name(), ClassName: result.SchemaID}
+		if !result.Valid {
+			suite.Failures++
+
+			var builder strings.Builder
+			for _, e := range result.Errors {
+				builder.WriteString(fmt.Sprintf("#%s: %s (%s)\n", e.InstanceLocation, e.Message, e.KeywordLocation))
+			}
+
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d validation errors", len(result.Errors)),
+				Type:    "validation",
+				Text:    builder.String(),
+			}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	output, err := xml.MarshalIndent(junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(output, '\n'))
+	return err
+}
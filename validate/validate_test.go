@@ -0,0 +1,144 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `{
+  "$id": "https://example.com/pet.json",
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {"type": "string"},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+func TestValidator_Validate(t *testing.T) {
+	// Arrange
+	compiler := jsonschema.NewCompiler()
+	_, err := compiler.Compile([]byte(testSchema))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pets.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("{\"name\": \"Dog\"}\n\n{\"tags\": [\"good\", 1]}\n"), 0o644))
+
+	instances, err := ReadInstances(path)
+	require.NoError(t, err)
+
+	validator := &Validator{Compiler: compiler, Mappings: []Mapping{{Pattern: "pets.*", SchemaID: "https://example.com/pet.json"}}}
+
+	// Act
+	valid, validErr := validator.Validate(instances[0])
+	invalid, invalidErr := validator.Validate(instances[1])
+
+	// Assert
+	require.NoError(t, validErr)
+	require.NoError(t, invalidErr)
+	assert.True(t, valid.Valid)
+	assert.False(t, invalid.Valid)
+	assert.Equal(t, 3, invalid.Instance.Index)
+
+	var locations []string
+	for _, e := range invalid.Errors {
+		locations = append(locations, e.InstanceLocation+" "+e.Keyword)
+	}
+	assert.Contains(t, locations, " required")
+	assert.Contains(t, locations, "/tags items")
+}
+
+func TestValidator_ValidateNumbers(t *testing.T) {
+	// Arrange
+	compiler := jsonschema.NewCompiler()
+	_, err := compiler.Compile([]byte(`{"$id": "https://example.com/age.json", "type": "object", "properties": {"age": {"type": "integer", "minimum": 1}, "weight": {"type": "number", "maximum": 9.5}}}`))
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ages.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("{\"age\": 3, \"weight\": 9.25}\n{\"age\": 0}\n"), 0o644))
+
+	instances, err := ReadInstances(path)
+	require.NoError(t, err)
+
+	validator := &Validator{Compiler: compiler, Mappings: []Mapping{{Pattern: "ages.*", SchemaID: "https://example.com/age.json"}}}
+
+	// Act
+	valid, validErr := validator.Validate(instances[0])
+	invalid, invalidErr := validator.Validate(instances[1])
+
+	// Assert
+	require.NoError(t, validErr)
+	require.NoError(t, invalidErr)
+	assert.True(t, valid.Valid)
+	assert.False(t, invalid.Valid)
+}
+
+func TestValidator_NoSchema(t *testing.T) {
+	// Arrange
+	validator := &Validator{Compiler: jsonschema.NewCompiler()}
+
+	// Act
+	result, err := validator.Validate(&Instance{Path: "pet.json", Data: map[string]any{}})
+	_, schemaErr := validator.Schema(&Instance{Path: "pet.json", Data: map[string]any{}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, "$schema", result.Errors[0].Keyword)
+	assert.Contains(t, result.Errors[0].Message, "pet.json")
+	require.ErrorIs(t, schemaErr, ErrNoSchema)
+}
+
+func TestReadInstances_TrailingData(t *testing.T) {
+	tests := map[string]string{
+		"pet.json":    `{"name": "Dog"} {"name": "Cat"}`,
+		"pets.ndjson": "{\"name\": \"Dog\"}\n{\"name\": \"Cat\"} x\n",
+	}
+
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+
+			// Act
+			_, err := ReadInstances(path)
+
+			// Assert
+			require.ErrorIs(t, err, ErrDecodingInstance)
+			require.ErrorIs(t, err, ErrTrailingData)
+		})
+	}
+}
+
+func TestFormat_WriteJSON(t *testing.T) {
+	// Arrange
+	results := []*Result{
+		{
+			Instance: &Instance{Path: "pet.json"},
+			SchemaID: "https://example.com/pet.json",
+			Errors:   []*Error{{InstanceLocation: "/name", KeywordLocation: "/properties/name/type", Keyword: "type", Message: "wrong type"}},
+		},
+	}
+	buffer := new(bytes.Buffer)
+
+	// Act
+	err := JSON.Write(buffer, results)
+
+	// Assert
+	require.NoError(t, err)
+	var output []map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &output))
+	assert.Equal(t, "pet.json", output[0]["instance"])
+	assert.Equal(t, false, output[0]["valid"])
+}
@@ -0,0 +1,181 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kaptinlin/jsonschema"
+)
+
+// ErrNoSchema is returned by Validator.Schema when no schema could be selected for an Instance
+var ErrNoSchema = errors.New("no schema found for instance")
+
+// ErrInvalidMapping is returned when a Mapping cannot be parsed
+var ErrInvalidMapping = errors.New("invalid mapping, expected 'pattern=schema-id'")
+
+// Mapping of instance files matching Pattern to the schema with $id SchemaID
+type Mapping struct {
+	// Pattern matched against the path (or base name) of an Instance, see filepath.Match for the syntax
+	Pattern string
+
+	// SchemaID of the schema used to validate matching instances
+	SchemaID string
+}
+
+// ParseMapping in the form 'pattern=schema-id', e.g. 'pets/*.json=file:///testdata/pet.json'
+func ParseMapping(input string) (Mapping, error) {
+	pattern, schemaID, ok := strings.Cut(input, "=")
+	if !ok || pattern == "" || schemaID == "" {
+		return Mapping{}, fmt.Errorf("%w: %s", ErrInvalidMapping, input)
+	}
+
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return Mapping{}, fmt.Errorf("%w: %s: %w", ErrInvalidMapping, input, err)
+	}
+
+	return Mapping{Pattern: pattern, SchemaID: schemaID}, nil
+}
+
+// Matches returns true iff the path or the base name of the path matches the Pattern
+func (m Mapping) Matches(path string) bool {
+	if ok, _ := filepath.Match(m.Pattern, path); ok {
+		return true
+	}
+
+	ok, _ := filepath.Match(m.Pattern, filepath.Base(path))
+	return ok
+}
+
+// Validator validates Instance documents against schemas loaded in the Compiler
+type Validator struct {
+	// Compiler containing the loaded schemas
+	Compiler *jsonschema.Compiler
+
+	// SchemaID if set is used for every Instance
+	SchemaID string
+
+	// Mappings from instance paths to schemas, the first matching Mapping is used
+	Mappings []Mapping
+}
+
+// Schema selects the jsonschema.Schema for an Instance. In order of precedence:
+//   - the Validator.SchemaID
+//   - the '$schema' property of the Instance
+//   - the first of the Validator.Mappings that matches the Instance.Path
+func (v *Validator) Schema(instance *Instance) (*jsonschema.Schema, error) {
+	if v.SchemaID != "" {
+		return v.Compiler.GetSchema(v.SchemaID)
+	}
+
+	if object, ok := instance.Data.(map[string]any); ok {
+		if id, ok := object["$schema"].(string); ok && id != "" {
+			if schema, err := v.Compiler.GetSchema(id); err == nil && schema != nil {
+				return schema, nil
+			}
+		}
+	}
+
+	for _, mapping := range v.Mappings {
+		if mapping.Matches(instance.Path) {
+			return v.Compiler.GetSchema(mapping.SchemaID)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrNoSchema, instance.Name())
+}
+
+// Validate an Instance against its selected schema (see Validator.Schema). An Instance without a schema is an invalid
+// Result with a single '$schema' Error such that the other instances are still validated
+func (v *Validator) Validate(instance *Instance) (*Result, error) {
+	schema, err := v.Schema(instance)
+	if err == nil && schema == nil {
+		err = fmt.Errorf("%w: %s", ErrNoSchema, instance.Name())
+	}
+
+	if errors.Is(err, ErrNoSchema) {
+		return &Result{
+			Instance: instance,
+			Errors:   []*Error{{Keyword: "$schema", Message: err.Error()}},
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	evaluation := schema.Validate(instance.Data)
+	result := &Result{
+		Instance: instance,
+		SchemaID: schema.GetSchemaURI(),
+		Valid:    evaluation.IsValid(),
+	}
+	result.Errors = collectErrors(evaluation, "", "")
+	slices.SortStableFunc(result.Errors, func(a, b *Error) int {
+		if c := strings.Compare(a.InstanceLocation, b.InstanceLocation); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.KeywordLocation, b.KeywordLocation)
+	})
+
+	return result, nil
+}
+
+// Result of validating an Instance
+type Result struct {
+	// Instance that is validated
+	Instance *Instance
+
+	// SchemaID of the schema used
+	SchemaID string
+
+	// Valid is true iff the Instance is valid w.r.t. the schema
+	Valid bool
+
+	// Errors found, sorted by their InstanceLocation and KeywordLocation
+	Errors []*Error
+}
+
+// Error in an Instance
+type Error struct {
+	// InstanceLocation as a JSON pointer to the failing value in the Instance ("" being the document root)
+	InstanceLocation string
+
+	// KeywordLocation as a JSON pointer to the failing keyword relative to the root schema
+	KeywordLocation string
+
+	// Keyword that failed, e.g. 'required'
+	Keyword string
+
+	// Message describing the failure
+	Message string
+}
+
+// collectErrors from the (nested) jsonschema.EvaluationResult. Locations in nested results are relative to
+// their parent, hence the absolute locations are built while descending
+func collectErrors(result *jsonschema.EvaluationResult, instanceLocation string, keywordLocation string) []*Error {
+	instanceLocation += result.InstanceLocation
+	keywordLocation += result.EvaluationPath
+
+	var res []*Error
+	for _, keyword := range slices.Sorted(maps.Keys(result.Errors)) {
+		res = append(res, &Error{
+			InstanceLocation: instanceLocation,
+			KeywordLocation:  keywordLocation + "/" + keyword,
+			Keyword:          keyword,
+			Message:          result.Errors[keyword].Error(),
+		})
+	}
+
+	for _, detail := range result.Details {
+		if detail.IsValid() {
+			continue
+		}
+
+		res = append(res, collectErrors(detail, instanceLocation, keywordLocation)...)
+	}
+
+	return res
+}
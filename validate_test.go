@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate_ValidInstances(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{validateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--data", "./testdata/instances/*.yaml,./testdata/instances/*.ndjson", "--schema-map", "pet*=file:///testdata/pet.json,stores*=file:///testdata/store.json", "--format", "text"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, outputBuffer.String(), "PASS testdata/instances/pet.yaml (file:///testdata/pet.json)")
+	assert.Contains(t, outputBuffer.String(), "PASS testdata/instances/stores.ndjson:2 (file:///testdata/store.json)")
+	assert.Contains(t, outputBuffer.String(), "3 instances, 0 failed")
+}
+
+func TestValidate_InvalidInstanceJUnit(t *testing.T) {
+	// Arrange
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{validateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--data", "./testdata/instances/*.json", "--format", "junit"}
+	rootCmd.SetArgs(args)
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidInstances)
+	assert.Contains(t, outputBuffer.String(), `<testcase name="testdata/instances/pet-invalid.json" classname="file:///testdata/pet.json">`)
+	assert.Contains(t, outputBuffer.String(), "#/name")
+}
+
+func TestValidate_ReportsInstanceWithoutSchema(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pet.json"), []byte(`{"id": "3aedd626-63b2-4e06-9f5e-bd0685ff6265", "name": "Dog"}`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unknown.json"), []byte(`{}`), 0o644))
+	outputBuffer := new(bytes.Buffer)
+	rootCmd.SetOut(outputBuffer)
+	args := []string{validateCmd.Use, "--globs", "./testdata/*.json", "--base-uri", "./", "--data", filepath.Join(dir, "*.json"), "--schema-map", "pet.json=file:///testdata/pet.json", "--format", "text"}
+	rootCmd.SetArgs(args)
+	resetFlags(validateCmd)
+	t.Cleanup(func() { resetFlags(validateCmd) })
+
+	// Act
+	err := rootCmd.Execute()
+
+	// Assert
+	require.ErrorIs(t, err, ErrInvalidInstances)
+	assert.Contains(t, outputBuffer.String(), "PASS "+filepath.Join(dir, "pet.json")+" (file:///testdata/pet.json)")
+	assert.Contains(t, outputBuffer.String(), "FAIL "+filepath.Join(dir, "unknown.json")+" (no schema)")
+	assert.Contains(t, outputBuffer.String(), "2 instances, 1 failed")
+}
@@ -0,0 +1,243 @@
+// Package view contains the Model exposed to user supplied templates (see the template command)
+package view
+
+import (
+	"errors"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/tmpl"
+	"github.com/kaptinlin/jsonschema"
+)
+
+// Parser implementation of which the Model is built
+type Parser interface {
+	Schemas() ([]*jsonschema.Schema, error)
+	Classes() ([]*domain.Class, error)
+	Relations() ([]*domain.Relation, error)
+}
+
+// Model exposed to templates
+type Model struct {
+	// Classes parsed from the schemas
+	Classes []*Class
+
+	// Relations between the Classes
+	Relations []*Relation
+
+	// Roots are the Classes parsed from the schemas matched by the globs
+	Roots []*Class
+
+	// Container with the Classes grouped by the directories of their source (relative to the common directory)
+	Container *Container
+
+	// Depth of every Class from the closest root or parse.Unreachable (also available as Class.Depth)
+	Depth map[*Class]int
+
+	// Class currently rendered when rendering one output per Class, otherwise nil
+	Class *Class
+}
+
+// Class wraps a domain.Class (Name, Docstring, Properties, Source and Schema) with its position in the graph
+type Class struct {
+	*domain.Class
+
+	// ID of the schema of the Class (its $id or the URI it was loaded from)
+	ID string
+
+	// Path of the source of the Class without the 'file://' scheme
+	Path string
+
+	// Slug of the Name that is unique within the Model (e.g. 'pet-store' or 'pet-store-2'), 'class' if the Name has no
+	// letters or digits such as an anonymous Class. Used to name the files of a Class
+	Slug string
+
+	// Root is true iff the Class is parsed from a schema matched by the globs
+	Root bool
+
+	// Depth from the closest root or parse.Unreachable
+	Depth int
+
+	// Outgoing Relations from the Class
+	Outgoing []*Relation
+
+	// Incoming Relations towards the Class
+	Incoming []*Relation
+}
+
+// Relation wraps a domain.Relation (Type, FromProperty and ToProperty) between two Classes
+type Relation struct {
+	*domain.Relation
+
+	// From part of the Relation
+	From *Class
+
+	// To receiving end of the Relation
+	To *Class
+
+	// Cycle is true iff the Relation is part of a cycle (see parse.Cycles)
+	Cycle bool
+}
+
+// Container of Classes grouped by directory
+type Container struct {
+	// Name of the directory
+	Name string
+
+	// Path of the directory relative to the common directory of all Classes
+	Path string
+
+	// Classes in the directory
+	Classes []*Class
+
+	// Containers nested in the directory
+	Containers []*Container
+}
+
+// New Model from the Parser
+func New(parser Parser) (*Model, error) {
+	roots, err := parser.Schemas()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	classes, err := parser.Classes()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	relations, err := parser.Relations()
+	if err != nil {
+		return nil, errors.Join(parse.ErrParserFailure, err)
+	}
+
+	depthMap := parse.DepthMap(roots, classes, relations)
+	model := &Model{Depth: map[*Class]int{}}
+	byClass := map[*domain.Class]*Class{}
+	slugs := map[string]bool{}
+	for _, class := range classes {
+		c := &Class{Class: class, Depth: parse.Unreachable, Root: slices.Contains(roots, class.Schema), Slug: uniqueSlug(class.Name, slugs)}
+		if class.Schema != nil {
+			c.ID = class.Schema.GetSchemaURI()
+		}
+
+		if class.Source != nil {
+			c.Path = stripScheme(class.Path())
+		}
+
+		if depth, ok := depthMap[class]; ok {
+			c.Depth = depth
+		}
+
+		byClass[class] = c
+		model.Classes = append(model.Classes, c)
+		model.Depth[c] = c.Depth
+		if c.Root {
+			model.Roots = append(model.Roots, c)
+		}
+	}
+
+	cycles := parse.CycleRelations(classes, relations)
+	for _, relation := range relations {
+		r := &Relation{Relation: relation, From: byClass[relation.From], To: byClass[relation.To], Cycle: cycles[relation]}
+		r.From.Outgoing = append(r.From.Outgoing, r)
+		r.To.Incoming = append(r.To.Incoming, r)
+		model.Relations = append(model.Relations, r)
+	}
+
+	model.Container = containerize(model.Classes)
+
+	return model, nil
+}
+
+// uniqueSlug of the name (see tmpl.Slug) that is not in slugs, after which it is added to slugs
+func uniqueSlug(name string, slugs map[string]bool) string {
+	slug := tmpl.Slug(name)
+	if slug == "" {
+		slug = "class"
+	}
+
+	res := slug
+	for i := 2; slugs[res]; i++ {
+		res = slug + "-" + strconv.Itoa(i)
+	}
+	slugs[res] = true
+
+	return res
+}
+
+// ForClass returns a copy of the Model with the Class set
+func (m *Model) ForClass(class *Class) *Model {
+	res := *m
+	res.Class = class
+
+	return &res
+}
+
+// containerize the Classes by the directories of their Path relative to the common directory
+func containerize(classes []*Class) *Container {
+	var common []string
+	for i, class := range classes {
+		dir := splitDir(class.Path)
+		if i == 0 {
+			common = dir
+			continue
+		}
+
+		n := 0
+		for n < len(common) && n < len(dir) && common[n] == dir[n] {
+			n++
+		}
+		common = common[:n]
+	}
+
+	root := &Container{}
+	for _, class := range classes {
+		container := root
+		for _, name := range splitDir(class.Path)[len(common):] {
+			i := slices.IndexFunc(container.Containers, func(c *Container) bool { return c.Name == name })
+			if i < 0 {
+				container.Containers = append(container.Containers, &Container{Name: name, Path: path.Join(container.Path, name)})
+				i = len(container.Containers) - 1
+			}
+			container = container.Containers[i]
+		}
+
+		container.Classes = append(container.Classes, class)
+	}
+	root.sort()
+
+	return root
+}
+
+// sort the nested Containers (recursively) by name
+func (c *Container) sort() {
+	slices.SortStableFunc(c.Containers, func(a, b *Container) int { return strings.Compare(a.Name, b.Name) })
+	for _, container := range c.Containers {
+		container.sort()
+	}
+}
+
+// splitDir of a path into its directories
+func splitDir(p string) []string {
+	dir := strings.Trim(path.Dir(p), "/")
+	if dir == "" || dir == "." {
+		return nil
+	}
+
+	return strings.Split(dir, "/")
+}
+
+// stripScheme removes the 'file://' scheme of a source path
+func stripScheme(source string) string {
+	if match := regexp.MustCompile("^file:/?/?(/)").FindStringSubmatchIndex(source); match != nil {
+		return path.Clean(source[match[2]:])
+	}
+
+	return source
+}
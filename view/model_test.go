@@ -0,0 +1,78 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/Emptyless/jsonschema-transform/domain"
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/parse/parsetest"
+	"github.com/kaptinlin/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	// Arrange
+	root, node, orphan := &jsonschema.Schema{}, &jsonschema.Schema{}, &jsonschema.Schema{}
+	rootClass := &domain.Class{Schema: root, Name: "Root", Source: domain.FileSource{FilePath: "file:///schemas/root.json"}}
+	nodeClass := &domain.Class{Schema: node, Name: "Node", Source: domain.FileSource{FilePath: "file:///schemas/tree/node.json"}}
+	orphanClass := &domain.Class{Schema: orphan, Name: "Orphan", Source: domain.FileSource{FilePath: "file:///schemas/tree/orphan.json"}}
+	parser := &parsetest.Parser{
+		SchemaData: []*jsonschema.Schema{root},
+		ClassData:  []*domain.Class{rootClass, nodeClass, orphanClass},
+		RelationsData: []*domain.Relation{
+			{Type: "node", From: rootClass, To: nodeClass},
+			{Type: "parent", From: nodeClass, To: nodeClass},
+		},
+	}
+
+	// Act
+	model, err := New(parser)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, model.Classes, 3)
+	require.Len(t, model.Roots, 1)
+	assert.Equal(t, "Root", model.Roots[0].Name)
+
+	nodeView := model.Classes[1]
+	assert.Equal(t, "/schemas/tree/node.json", nodeView.Path)
+	assert.Equal(t, 1, nodeView.Depth)
+	assert.Equal(t, 1, model.Depth[nodeView])
+	assert.Equal(t, parse.Unreachable, model.Classes[2].Depth)
+	assert.Len(t, nodeView.Incoming, 2)
+	require.Len(t, nodeView.Outgoing, 1)
+	assert.True(t, nodeView.Outgoing[0].Cycle)
+	assert.False(t, model.Relations[0].Cycle)
+
+	require.Len(t, model.Container.Containers, 1)
+	assert.Equal(t, "tree", model.Container.Containers[0].Path)
+	assert.Equal(t, []*Class{model.Classes[0]}, model.Container.Classes)
+	assert.Equal(t, []*Class{nodeView, model.Classes[2]}, model.Container.Containers[0].Classes)
+
+	assert.Nil(t, model.Class)
+	assert.Equal(t, nodeView, model.ForClass(nodeView).Class)
+}
+
+func TestNew_Slug(t *testing.T) {
+	// Arrange
+	parser := &parsetest.Parser{
+		ClassData: []*domain.Class{
+			{Schema: &jsonschema.Schema{}, Name: "Pet Owner"},
+			{Schema: &jsonschema.Schema{}, Name: "pet-owner"},
+			{Schema: &jsonschema.Schema{}, Name: " "},
+			{Schema: &jsonschema.Schema{}, Name: ""},
+		},
+	}
+
+	// Act
+	model, err := New(parser)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, model.Classes, 4)
+	assert.Equal(t, "pet-owner", model.Classes[0].Slug)
+	assert.Equal(t, "pet-owner-2", model.Classes[1].Slug)
+	assert.Equal(t, "class", model.Classes[2].Slug)
+	assert.Equal(t, "class-2", model.Classes[3].Slug)
+}
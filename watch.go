@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/Emptyless/jsonschema-transform/parse"
+	"github.com/Emptyless/jsonschema-transform/watch"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// runOrWatch calls run once, or if --watch is set, again every time one of the files of the parse.Parser (or the
+// additional files) changes until interrupted. The first run may overwrite existing outputs iff --overwrite is set,
+// every later run while watching overwrites the outputs regardless as they are written by the run before it. While
+// watching, errors of run are printed instead of returned. The --overwrite flag itself is never changed
+func runOrWatch(cmd *cobra.Command, parser *parse.Parser, run func(overwrite bool) error, files ...string) error {
+	overwrite := false
+	if flag := cmd.Flags().Lookup(allowOverwriteFlag.Name); flag != nil {
+		overwrite = flag.Value.String() == "true"
+	}
+
+	if enabled, err := cmd.Flags().GetBool(watchFlag.Name); err != nil || !enabled {
+		return run(overwrite)
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+	defer stop()
+
+	watcher := &watch.Watcher{
+		Run: func() error {
+			parser.Reset()
+			if err := run(overwrite); err != nil {
+				return err
+			}
+
+			// outputs written by this run are regenerated by the next
+			overwrite = true
+
+			return nil
+		},
+		Files: func() []string { return append(parser.Files(), files...) },
+		Globs: parser.Globs,
+	}
+
+	logrus.Warn("watching for changes, press Ctrl+C to stop")
+
+	return watcher.Watch(ctx)
+}
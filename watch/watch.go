@@ -0,0 +1,173 @@
+package watch
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultDebounce is the quiet period after the last change before the Watcher runs again
+const DefaultDebounce = 200 * time.Millisecond
+
+// Watcher runs a function every time one of the watched files changes
+type Watcher struct {
+	// Run is called once when watching starts and again after every (debounced) change. Errors are reported with
+	// OnError and do not stop the Watcher
+	Run func() error
+
+	// Files to watch, called after every Run such that files added by the Run (e.g. a newly referenced schema) are
+	// watched as well
+	Files func() []string
+
+	// Globs matching files that are watched even though they did not exist yet, e.g. a new schema
+	Globs []string
+
+	// Debounce is the quiet period after the last change before Run is called, DefaultDebounce if zero
+	Debounce time.Duration
+
+	// OnError is called with the error of Run, if nil the error is logged
+	OnError func(err error)
+}
+
+// Watch the files until the context is done. The parent directories of the files are watched instead of the files
+// themselves such that files replaced by an editor (written to a temporary file and renamed) are still picked up
+func (w *Watcher) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	debounce := w.Debounce
+	if debounce == 0 {
+		debounce = DefaultDebounce
+	}
+
+	// directories of the globs are watched before the first Run such that no change is missed
+	w.add(watcher, w.dirs(nil))
+
+	files := w.run(watcher)
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+
+			if event.Op == fsnotify.Chmod || !w.matches(files, event.Name) {
+				continue
+			}
+
+			logrus.Debugf("%s changed (%s)", event.Name, event.Op)
+			timer.Reset(debounce)
+		case watchErr, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			w.report(watchErr)
+		case <-timer.C:
+			logrus.Info("regenerating")
+			files = w.run(watcher)
+		}
+	}
+}
+
+// run the Watcher and watch the directories of the resulting files, which are returned as set
+func (w *Watcher) run(watcher *fsnotify.Watcher) map[string]bool {
+	if err := w.Run(); err != nil {
+		w.report(err)
+	}
+
+	var files []string
+	if w.Files != nil {
+		files = w.Files()
+	}
+
+	for _, glob := range w.Globs {
+		matches, _ := filepath.Glob(glob)
+		files = append(files, matches...)
+	}
+
+	res := map[string]bool{}
+	for _, file := range files {
+		if abs, err := filepath.Abs(file); err == nil {
+			file = abs
+		}
+		res[file] = true
+	}
+	w.add(watcher, w.dirs(res))
+
+	return res
+}
+
+// dirs of the files and Globs, sorted and without duplicates
+func (w *Watcher) dirs(files map[string]bool) []string {
+	var res []string
+	for file := range files {
+		res = append(res, filepath.Dir(file))
+	}
+
+	for _, glob := range w.Globs {
+		// the directory of a glob can be a pattern itself (e.g. '**/*.json'), every directory it matches is watched
+		dirs, _ := filepath.Glob(filepath.Dir(glob))
+		for _, dir := range dirs {
+			if abs, err := filepath.Abs(dir); err == nil {
+				res = append(res, abs)
+			}
+		}
+	}
+
+	slices.Sort(res)
+	return slices.Compact(res)
+}
+
+// add the directories to the fsnotify.Watcher, directories that cannot be watched (e.g. do not exist) are skipped
+func (w *Watcher) add(watcher *fsnotify.Watcher, dirs []string) {
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			logrus.Debugf("cannot watch %s: %s", dir, err)
+		}
+	}
+}
+
+// matches returns true iff the file is watched or matches one of the Globs
+func (w *Watcher) matches(files map[string]bool, file string) bool {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+
+	if files[file] {
+		return true
+	}
+
+	for _, glob := range w.Globs {
+		if abs, err := filepath.Abs(glob); err == nil {
+			glob = abs
+		}
+
+		if ok, _ := filepath.Match(glob, file); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// report the error with OnError or log it
+func (w *Watcher) report(err error) {
+	if w.OnError != nil {
+		w.OnError(err)
+		return
+	}
+
+	logrus.Error(err)
+}
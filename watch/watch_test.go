@@ -0,0 +1,116 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_DebouncesChanges(t *testing.T) {
+	// Arrange
+	file := filepath.Join(t.TempDir(), "pet.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{}`), 0o644))
+	var runs atomic.Int32
+	watcher := &Watcher{
+		Run:      func() error { runs.Add(1); return nil },
+		Globs:    []string{file},
+		Debounce: 50 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Watch(ctx) }()
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	for range 3 {
+		require.NoError(t, os.WriteFile(file, []byte(`{"type": "object"}`), 0o644))
+	}
+
+	// Assert
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(2), runs.Load())
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatcher_DoubleStarGlob(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pets"), 0o755))
+	var runs atomic.Int32
+	watcher := &Watcher{
+		Run:      func() error { runs.Add(1); return nil },
+		Globs:    []string{filepath.Join(dir, "**", "*.json")},
+		Debounce: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Watch(ctx) }()
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pets", "pet.json"), []byte(`{}`), 0o644))
+
+	// Assert
+	require.Eventually(t, func() bool { return runs.Load() == 2 }, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatcher_ReportsErrorsAndContinues(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	var runs atomic.Int32
+	errs := make(chan error, 2)
+	watcher := &Watcher{
+		Run:      func() error { runs.Add(1); return errors.New("invalid schema") },
+		Globs:    []string{filepath.Join(dir, "*.json")},
+		Debounce: 10 * time.Millisecond,
+		OnError:  func(err error) { errs <- err },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Watch(ctx) }()
+	assert.EqualError(t, <-errs, "invalid schema")
+
+	// Act
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.json"), []byte(`{}`), 0o644))
+
+	// Assert
+	assert.EqualError(t, <-errs, "invalid schema")
+	assert.Equal(t, int32(2), runs.Load())
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatcher_IgnoresOtherFiles(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	var runs atomic.Int32
+	watcher := &Watcher{
+		Run:      func() error { runs.Add(1); return nil },
+		Globs:    []string{filepath.Join(dir, "*.json")},
+		Debounce: 10 * time.Millisecond,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- watcher.Watch(ctx) }()
+	require.Eventually(t, func() bool { return runs.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "diagram.d2"), []byte(`Pet`), 0o644))
+
+	// Assert
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+	cancel()
+	require.NoError(t, <-done)
+}